@@ -0,0 +1,45 @@
+// Command mockoidc menjalankan OpenID Connect provider palsu untuk development
+// dan pengujian end-to-end login sosial tanpa akun Google.
+//
+//	go run ./cmd/mockoidc -addr :9999 -email buyer@example.com
+//
+// lalu set di backend:
+//
+//	OAUTH_PROVIDERS=mock
+//	OAUTH_MOCK_ISSUER=http://localhost:9999
+//	OAUTH_MOCK_CLIENT_ID=mock-client
+//	OAUTH_MOCK_REDIRECT_URL=http://localhost:8080/auth/oauth/mock/callback
+//
+// Endpoint authorize langsung menyetujui login dan redirect kembali dengan code.
+package main
+
+import (
+	"ecommerce-backend/controllers/oauth/mockoidc"
+	"flag"
+	"log"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", ":9999", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9999", "issuer URL advertised in discovery and id tokens")
+	subject := flag.String("sub", "mock-user-1", "subject of the signed-in user")
+	email := flag.String("email", "buyer@example.com", "email of the signed-in user")
+	emailVerified := flag.Bool("email-verified", true, "whether the provider reports the email as verified")
+	name := flag.String("name", "Mock Buyer", "name of the signed-in user")
+	flag.Parse()
+
+	server, err := mockoidc.New(mockoidc.Config{
+		Issuer:        *issuer,
+		Subject:       *subject,
+		Email:         *email,
+		EmailVerified: *emailVerified,
+		Name:          *name,
+	})
+	if err != nil {
+		log.Fatalf("Failed to start mock provider: %v", err)
+	}
+
+	log.Printf("Mock OIDC provider running on %s (issuer %s)", *addr, *issuer)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
@@ -51,11 +51,87 @@ func InitDB() {
 	DB = db
 	fmt.Println("Database connected!")
 
+	// User yang sudah ada sebelum verifikasi email diperkenalkan dianggap terverifikasi
+	backfillEmailVerified := !db.Migrator().HasColumn(&models.User{}, "email_verified")
+	// Kolom statistik produk baru ditambahkan; isi dari data lama setelah migrasi
+	backfillProductStats := !db.Migrator().HasColumn(&models.Product{}, "review_count")
+	// Gambar tunggal produk lama dipindahkan ke galeri sebagai gambar primary
+	backfillProductImages := !db.Migrator().HasTable(&models.ProductImage{})
+	// Produk lama otomatis published (default kolom status); tanggal publish diisi dari created_at
+	backfillProductPublishedAt := !db.Migrator().HasColumn(&models.Product{}, "published_at")
+	// Order item lama belum punya snapshot produk; isi dari data produk yang masih ada
+	backfillOrderItemSnapshots := !db.Migrator().HasColumn(&models.OrderItem{}, "product_name")
+	// Kosakata search lama belum punya panjang kata untuk prefilter koreksi ejaan
+	backfillSearchWordLength := !db.Migrator().HasColumn(&models.SearchWord{}, "length")
+	// SKU varian dulu unik global; sekarang unik per seller sehingga butuh seller_id
+	backfillVariantSeller := !db.Migrator().HasColumn(&models.ProductVariant{}, "seller_id")
+
 	db.AutoMigrate(
-		&models.User{}, &models.Product{}, &models.Order{},
+		&models.User{}, &models.Product{}, &models.ProductOption{}, &models.ProductVariant{}, &models.ProductImage{}, &models.Order{},
 		&models.OrderItem{}, &models.Review{}, &models.CartItem{},
-		&models.Category{}, &models.Payment{},
+		&models.Category{}, &models.Payment{}, &models.RefreshToken{},
+		&models.EmailVerificationToken{}, &models.PasswordResetToken{},
+		&models.MFARecoveryCode{}, &models.RoleAuthPolicy{},
+		&models.UserIdentity{}, &models.OAuthState{}, &models.OAuthLoginCode{},
+		&models.LoginThrottle{}, &models.SecurityEvent{},
+		&models.Session{}, &models.APIKey{},
+		&models.SellerApplication{}, &models.SellerApplicationDocument{}, &models.ShopProfile{},
+		&models.ImpersonationSession{}, &models.ImpersonationAuditLog{},
+		&models.MagicLinkToken{},
+		&models.SearchTerm{}, &models.SearchDocument{}, &models.SearchWord{},
+		&models.SearchQueryStat{}, &models.SearchQuerySearcher{}, &models.ProductImportJob{},
+		&models.CategoryAttribute{}, &models.ProductAttributeValue{},
 	)
 
+	if backfillEmailVerified {
+		db.Exec(`UPDATE users SET email_verified = true, email_verified_at = created_at WHERE email_verified = false`)
+	}
+
+	if backfillProductStats {
+		db.Exec(`UPDATE products p SET
+			rating = COALESCE((SELECT AVG(r.rating) FROM reviews r WHERE r.product_id = p.id), 0),
+			review_count = (SELECT COUNT(*) FROM reviews r WHERE r.product_id = p.id),
+			sold_count = COALESCE((SELECT SUM(oi.quantity) FROM order_items oi
+				JOIN orders o ON o.id = oi.order_id
+				WHERE oi.product_id = p.id AND oi.status <> 'cancelled'
+				AND o.status IN ('paid', 'processing', 'shipped', 'completed')), 0)`)
+	}
+
+	if backfillProductImages {
+		db.Exec(`INSERT INTO product_images (id, product_id, url, alt_text, position, is_primary, created_at)
+			SELECT UUID(), id, image_url, name, 0, true, NOW() FROM products WHERE image_url <> ''`)
+	}
+
+	if backfillProductPublishedAt {
+		db.Exec(`UPDATE products SET published_at = created_at WHERE status = 'published' AND published_at IS NULL`)
+	}
+
+	if backfillOrderItemSnapshots {
+		db.Exec(`UPDATE order_items oi
+			JOIN products p ON p.id = oi.product_id
+			LEFT JOIN product_variants v ON v.id = oi.variant_id
+			LEFT JOIN users u ON u.id = p.seller_id
+			LEFT JOIN shop_profiles s ON s.user_id = p.seller_id
+			SET oi.product_name = p.name,
+				oi.sku = COALESCE(v.sku, p.sku, ''),
+				oi.variant_options = v.options,
+				oi.unit_price = CASE WHEN oi.quantity > 0 THEN oi.price / oi.quantity ELSE oi.price END,
+				oi.image_url = COALESCE(NULLIF(v.image_url, ''), p.image_url),
+				oi.seller_id = p.seller_id,
+				oi.seller_name = COALESCE(s.shop_name, u.name, '')
+			WHERE oi.seller_id IS NULL`)
+	}
+
+	if backfillSearchWordLength {
+		db.Exec(`UPDATE search_words SET length = CHAR_LENGTH(word), last_seen_at = NOW()`)
+	}
+
+	if backfillVariantSeller {
+		db.Exec(`UPDATE product_variants v JOIN products p ON p.id = v.product_id SET v.seller_id = p.seller_id`)
+	}
+	if db.Migrator().HasIndex(&models.ProductVariant{}, "idx_product_variants_sku") {
+		db.Migrator().DropIndex(&models.ProductVariant{}, "idx_product_variants_sku")
+	}
+
 	fmt.Println("Database migrated!")
 }
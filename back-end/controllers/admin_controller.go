@@ -1,8 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"ecommerce-backend/models"
 	"ecommerce-backend/services"
 
 	"github.com/gin-gonic/gin"
@@ -73,3 +77,140 @@ func ToggleUserActiveStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "User status updated successfully"})
 }
+
+type UpdateAuthPolicyRequest struct {
+	MFARequired      *bool `json:"mfa_required"`
+	MagicLinkEnabled *bool `json:"magic_link_enabled"`
+}
+
+func GetAuthPolicies(c *gin.Context) {
+	policies, err := services.GetRoleAuthPolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve auth policies"})
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+func UpdateAuthPolicy(c *gin.Context) {
+	role := c.Param("role")
+	var req UpdateAuthPolicyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil || (req.MFARequired == nil && req.MagicLinkEnabled == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var policy *models.RoleAuthPolicy
+	var err error
+	if req.MFARequired != nil {
+		if policy, err = services.SetRoleMFARequirement(role, *req.MFARequired); err != nil {
+			respondAuthPolicyError(c, err)
+			return
+		}
+	}
+	if req.MagicLinkEnabled != nil {
+		if policy, err = services.SetRoleMagicLinkEnabled(role, *req.MagicLinkEnabled); err != nil {
+			respondAuthPolicyError(c, err)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Auth policy updated successfully", "policy": policy})
+}
+
+func respondAuthPolicyError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrMFANotAllowedForRole) || errors.Is(err, services.ErrInvalidRole) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+func UnlockUserAccount(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := services.UnlockAccount(id, c.GetString("userID")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User account unlocked successfully"})
+}
+
+func GetSecurityEvents(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	events, err := services.GetSecurityEvents(services.SecurityEventFilter{
+		Type:   c.Query("type"),
+		UserID: c.Query("user_id"),
+		Limit:  limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve security events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func ImpersonateUser(c *gin.Context) {
+	var req ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required to impersonate a user"})
+		return
+	}
+
+	result, err := services.StartImpersonation(c.GetString("userID"), c.Param("id"), strings.TrimSpace(req.Reason), loginContext(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrImpersonationNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrImpersonationReasonEmpty):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	// Token tidak disimpan di cookie supaya sesi admin di browser tidak tertimpa
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Impersonation started",
+		"token":         result.Token,
+		"expires_in":    result.ExpiresIn,
+		"impersonation": result.Session,
+	})
+}
+
+func GetImpersonations(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	sessions, err := services.GetImpersonationSessions(c.Query("admin_id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve impersonation sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+func GetImpersonationAuditLog(c *gin.Context) {
+	entries, err := services.GetImpersonationAuditLog(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve impersonation audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+func EndImpersonation(c *gin.Context) {
+	if err := services.EndImpersonation(c.Param("id"), c.GetString("userID")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Impersonation ended"})
+}
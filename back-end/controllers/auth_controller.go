@@ -3,13 +3,18 @@ package controllers
 import (
 	"ecommerce-backend/config"
 	"ecommerce-backend/models"
+	"ecommerce-backend/policy"
+	"ecommerce-backend/services"
 	"ecommerce-backend/utils"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 func Register(c *gin.Context) {
@@ -34,18 +39,22 @@ func Register(c *gin.Context) {
 
 	// Set default values
 	user.ID = uuid.New().String()
-	if user.Role == "" {
-		user.Role = "buyer" // Default role
-	}
+	// Role tidak bisa dipilih sendiri; seller harus lewat pengajuan seller application
+	user.Role = "buyer"
 	user.IsActive = true // Set default active status
+	user.EmailVerified = false
+	user.EmailVerifiedAt = nil
+	user.MFAEnabled = false
+	user.MFASecret = ""
+	user.MFAPendingSecret = ""
 
 	// FIX: Hash password before saving
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := utils.HashPassword(user.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
 	}
-	user.Password = string(hashedPassword)
+	user.Password = hashedPassword
 
 	// Create user in database
 	if err := config.DB.Create(&user).Error; err != nil {
@@ -54,13 +63,59 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	if err := services.SendVerificationEmail(&user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
-		"message":  "User registered successfully!",
+		"message":  "User registered successfully! Please check your email to verify your account.",
 		"username": user.Name,
 		"email":    user.Email,
 	})
 }
 
+// VerifyEmail hanya menerima POST dari halaman /verify-email di front-end;
+// link di email membuka halaman itu, bukan endpoint ini, supaya prefetch link
+// oleh klien email tidak memakai token.
+func VerifyEmail(c *gin.Context) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	_ = c.ShouldBindJSON(&req)
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification token is required"})
+		return
+	}
+
+	if err := services.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+func ResendVerificationEmail(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Respons selalu sama untuk setiap email agar tidak bisa dipakai menebak akun terdaftar
+	if err := services.ResendVerificationEmail(req.Email); err != nil {
+		log.Printf("Failed to resend verification email to %s: %v", req.Email, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists and is not verified, a verification email has been sent"})
+}
+
 func Login(c *gin.Context) {
 	var req struct {
 		UsernameOrEmail string `json:"username_or_email" binding:"required"`
@@ -71,47 +126,281 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	lc := loginContext(c)
+	if err := services.CheckIPAllowed(lc.IPAddress); err != nil {
+		respondLoginLocked(c, err)
+		return
+	}
+
 	var user models.User
 	// FIX: Query user by username/email only, then compare password hash
 	if err := config.DB.Where("(email = ? OR name = ?) AND is_active = ?", req.UsernameOrEmail, req.UsernameOrEmail, true).First(&user).Error; err != nil || user.ID == "" {
+		services.RecordLoginFailure("", req.UsernameOrEmail, lc)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	if err := services.CheckAccountAllowed(user.ID); err != nil {
+		respondLoginLocked(c, err)
+		return
+	}
+	if !utils.CheckPassword(user.Password, req.Password) {
+		services.RecordLoginFailure(user.ID, req.UsernameOrEmail, lc)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	token, err := utils.GenerateToken(user.ID, user.Role)
+	completeLogin(c, &user)
+}
+
+func loginContext(c *gin.Context) services.LoginContext {
+	return services.LoginContext{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+func respondLoginLocked(c *gin.Context, err error) {
+	var lockedErr *services.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		c.Header("Retry-After", strconv.Itoa(int(lockedErr.RetryAfter.Seconds())+1))
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+}
+
+// completeLogin dipanggil setelah kredensial pertama valid. Jika akun
+// membutuhkan 2FA, yang diterbitkan hanya challenge token berumur pendek.
+func completeLogin(c *gin.Context, user *models.User) {
+	if services.RequiresMFAChallenge(user) {
+		challengeToken, err := utils.GenerateMFAChallengeToken(user.ID, user.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":                 "Two-factor authentication required",
+			"mfa_required":            true,
+			"mfa_enrollment_required": !user.MFAEnabled,
+			"mfa_challenge_token":     challengeToken,
+			"expires_in":              int(utils.MFAChallengeTTL().Seconds()),
+		})
+		return
+	}
+
+	startSession(c, user, "Login successful", nil)
+}
+
+// startSession dipanggil setelah semua faktor autentikasi lolos.
+func startSession(c *gin.Context, user *models.User, message string, extra gin.H) {
+	lc := loginContext(c)
+	tokens, err := services.CreateSession(user, lc)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
+	services.RecordLoginSuccess(user.ID, lc)
 
-	// Set cookie with proper domain configuration
-	// Use empty domain to work with any domain
-	c.SetCookie("token", token, 3600*24, "/", "", false, true)
+	csrfToken := setAuthCookies(c, tokens)
 
 	userResponse := gin.H{
-		"id":       user.ID,
-		"name":     user.Name,
-		"email":    user.Email,
-		"role":     user.Role,
-		"isActive": user.IsActive,
+		"id":            user.ID,
+		"name":          user.Name,
+		"email":         user.Email,
+		"role":          user.Role,
+		"isActive":      user.IsActive,
+		"emailVerified": user.EmailVerified,
+		"mfaEnabled":    user.MFAEnabled,
+	}
+
+	response := gin.H{
+		"message":       message,
+		"user":          userResponse,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    int(tokens.ExpiresIn.Seconds()),
+		"csrf_token":    csrfToken,
+	}
+	for key, value := range extra {
+		response[key] = value
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func RefreshToken(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	rawToken := req.RefreshToken
+	if rawToken == "" {
+		rawToken, _ = c.Cookie("refresh_token")
+	}
+	if rawToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token is required"})
+		return
+	}
+
+	tokens, err := services.RotateRefreshToken(rawToken)
+	if err != nil {
+		clearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
 	}
 
+	csrfToken := setAuthCookies(c, tokens)
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"user":    userResponse,
-		"token":   token,
+		"message":       "Token refreshed",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    int(tokens.ExpiresIn.Seconds()),
+		"csrf_token":    csrfToken,
 	})
 }
+
+func ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.RequestPasswordReset(req.Email); err != nil {
+		log.Printf("Failed to process password reset for %s: %v", req.Email, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a password reset link has been sent"})
+}
+
+func ResetPassword(c *gin.Context) {
+	var req struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required,min=8"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.ResetPassword(req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
+
 func GetAuthStatus(c *gin.Context) {
 	userID, _ := c.Get("userID")
-	c.JSON(http.StatusOK, gin.H{"authenticated": true, "userId": userID})
+	c.JSON(http.StatusOK, gin.H{
+		"authenticated": true,
+		"userId":        userID,
+		"permissions":   policy.Permissions(c.GetString("role")),
+		"impersonator":  c.GetString("impersonatorID"),
+	})
 }
 
 func Logout(c *gin.Context) {
-	c.SetCookie("token", "", -1, "/", "", false, true)
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	// Token impersonation hanya mengakhiri impersonation, sesi admin sendiri tidak disentuh
+	if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		claims, err := utils.ValidateToken(strings.TrimPrefix(authHeader, "Bearer "))
+		if err == nil && claims.ImpersonatorID != "" {
+			if err := services.EndImpersonation(claims.SessionID, claims.ImpersonatorID); err != nil {
+				log.Printf("Failed to end impersonation on logout: %v", err)
+			}
+			c.JSON(http.StatusOK, gin.H{"message": "Impersonation ended"})
+			return
+		}
+	}
+
+	rawToken := req.RefreshToken
+	if rawToken == "" {
+		rawToken, _ = c.Cookie("refresh_token")
+	}
+
+	// Cabut token family supaya access token yang masih hidup ikut ditolak
+	if rawToken != "" {
+		if err := services.RevokeRefreshToken(rawToken); err != nil {
+			log.Printf("Failed to revoke refresh token on logout: %v", err)
+		}
+	} else if accessToken := extractAccessToken(c); accessToken != "" {
+		if claims, err := utils.ValidateToken(accessToken); err == nil && claims.SessionID != "" {
+			if err := services.RevokeSession(claims.SessionID); err != nil {
+				log.Printf("Failed to revoke session on logout: %v", err)
+			}
+		}
+	}
+
+	clearAuthCookies(c)
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
+
+// setAuthCookies juga menerbitkan csrf_token baru. Cookie ini sengaja tidak httpOnly
+// supaya frontend bisa membacanya dan mengirimnya kembali di header X-CSRF-Token.
+func setAuthCookies(c *gin.Context, tokens *services.AuthTokens) string {
+	csrfToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Failed to generate CSRF token: %v", err)
+	}
+
+	refreshMaxAge := int(utils.RefreshTokenTTL().Seconds())
+	utils.SetCookie(c.Writer, "token", tokens.AccessToken, int(tokens.ExpiresIn.Seconds()), "/", true)
+	utils.SetCookie(c.Writer, "refresh_token", tokens.RefreshToken, refreshMaxAge, "/auth", true)
+	utils.SetCookie(c.Writer, utils.CSRFCookieName, csrfToken, refreshMaxAge, "/", false)
+	return csrfToken
+}
+
+func clearAuthCookies(c *gin.Context) {
+	utils.SetCookie(c.Writer, "token", "", -1, "/", true)
+	utils.SetCookie(c.Writer, "refresh_token", "", -1, "/auth", true)
+	utils.SetCookie(c.Writer, utils.CSRFCookieName, "", -1, "/", false)
+}
+
+// GetCSRFToken menerbitkan ulang csrf_token untuk sesi cookie yang sudah ada,
+// misalnya setelah frontend kehilangan cookie-nya.
+func GetCSRFToken(c *gin.Context) {
+	csrfToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate CSRF token"})
+		return
+	}
+	utils.SetCookie(c.Writer, utils.CSRFCookieName, csrfToken, int(utils.RefreshTokenTTL().Seconds()), "/", false)
+	c.JSON(http.StatusOK, gin.H{"csrf_token": csrfToken})
+}
+
+func extractAccessToken(c *gin.Context) string {
+	authHeader := c.GetHeader("Authorization")
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	if token, err := c.Cookie("token"); err == nil && token != "" {
+		return token
+	}
+	return ""
+}
+
+// GetJWKS mempublikasikan public key agar service lain bisa memverifikasi access token.
+func GetJWKS(c *gin.Context) {
+	keys, err := utils.JWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load signing keys"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
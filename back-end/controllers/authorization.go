@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"ecommerce-backend/policy"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func currentSubject(c *gin.Context) policy.Subject {
+	return policy.Subject{
+		UserID: c.GetString("userID"),
+		Role:   c.GetString("role"),
+	}
+}
+
+// authorize memeriksa permission terhadap pemilik resource dan langsung
+// mengirim 403 jika ditolak. Controller cukup return saat hasilnya false.
+func authorize(c *gin.Context, permission policy.Permission, ownerID string) bool {
+	if err := policy.Authorize(currentSubject(c), permission, ownerID); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
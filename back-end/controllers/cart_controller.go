@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"ecommerce-backend/models"
+	"ecommerce-backend/policy"
 	"ecommerce-backend/services"
 
 	"github.com/gin-gonic/gin"
@@ -16,7 +18,19 @@ func AddToCart(c *gin.Context) {
 		return
 	}
 
-	cartItem, err := services.AddToCart(input.UserID, input.ProductID, input.Quantity)
+	// Tanpa user_id, item masuk ke keranjang user yang sedang login
+	if input.UserID == "" {
+		input.UserID = c.GetString("userID")
+	}
+	if !authorize(c, policy.CartManage, input.UserID) {
+		return
+	}
+
+	cartItem, err := services.AddToCart(input.UserID, input.ProductID, input.VariantID, input.Quantity)
+	if errors.Is(err, services.ErrVariantRequired) || errors.Is(err, services.ErrVariantNotFound) || errors.Is(err, services.ErrProductUnavailable) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -27,6 +41,9 @@ func AddToCart(c *gin.Context) {
 
 func GetCartByUser(c *gin.Context) {
 	userID := c.Param("user_id")
+	if !authorize(c, policy.CartManage, userID) {
+		return
+	}
 
 	cartItems, err := services.GetCartByUser(userID)
 	if err != nil {
@@ -44,6 +61,15 @@ func UpdateCartItem(c *gin.Context) {
 		return
 	}
 
+	cartItem, err := services.GetCartItemByID(input.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !authorize(c, policy.CartManage, cartItem.UserID) {
+		return
+	}
+
 	updatedCartItem, err := services.UpdateCartItem(input.ID, input.Quantity)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -56,6 +82,9 @@ func UpdateCartItem(c *gin.Context) {
 func DeleteCartItem(c *gin.Context) {
 	cartItemID := c.Param("id")
 	userID := c.Param("user_id")
+	if !authorize(c, policy.CartManage, userID) {
+		return
+	}
 
 	if err := services.DeleteCartItem(userID, cartItemID); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
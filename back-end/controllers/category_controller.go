@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func CreateCategory(c *gin.Context) {
@@ -48,7 +49,10 @@ func GetCategoryByID(c *gin.Context) {
 	id := c.Param("id")
 
 	var category models.Category
-	err := config.DB.Preload("Products").First(&category, "id = ?", id).Error
+	err := config.DB.
+		Preload("Products", "status = ?", models.ProductStatusPublished).
+		Preload("Attributes", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&category, "id = ?", id).Error
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
 		return
@@ -56,3 +60,32 @@ func GetCategoryByID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, category)
 }
+
+func GetCategoryAttributes(c *gin.Context) {
+	attributes, err := services.GetCategoryAttributes(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get category attributes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, attributes)
+}
+
+// SetCategoryAttributes mengganti seluruh skema atribut kategori, body berupa
+// array [{key, name, type: text|number|enum, options, unit, required, filterable}]
+// dengan urutan array sebagai urutan tampil.
+func SetCategoryAttributes(c *gin.Context) {
+	var inputs []services.CategoryAttributeInput
+	if err := c.ShouldBindJSON(&inputs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attributes, err := services.SetCategoryAttributes(c.Param("id"), inputs)
+	if err != nil {
+		respondAttributeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attributes)
+}
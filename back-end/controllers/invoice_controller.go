@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"bytes"
+	"ecommerce-backend/policy"
+	"ecommerce-backend/services"
+	"errors"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"rupiah":  formatRupiah,
+	"options": formatVariantOptions,
+}).Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<title>{{.Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 40px; color: #222; }
+table { width: 100%; border-collapse: collapse; margin-top: 24px; }
+th, td { padding: 8px; border-bottom: 1px solid #ddd; text-align: left; }
+td.num, th.num { text-align: right; }
+.muted { color: #777; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>Order {{.OrderID}}<br>Tanggal {{.IssuedAt.Format "02 Jan 2006"}}<br>Status {{.OrderStatus}}</p>
+<p>Kepada: {{.BuyerName}}<br><span class="muted">{{.BuyerEmail}}</span></p>
+{{if .SellerName}}<p>Penjual: {{.SellerName}}</p>{{end}}
+<table>
+<thead><tr><th>Produk</th><th>Penjual</th><th class="num">Jumlah</th><th class="num">Harga</th><th class="num">Total</th></tr></thead>
+<tbody>
+{{range .Lines}}<tr>
+<td>{{.ProductName}}{{with options .VariantOptions}}<br><span class="muted">{{.}}</span>{{end}}{{if .SKU}}<br><span class="muted">SKU {{.SKU}}</span>{{end}}</td>
+<td>{{.SellerName}}</td>
+<td class="num">{{.Quantity}}</td>
+<td class="num">{{rupiah .UnitPrice}}</td>
+<td class="num">{{rupiah .Total}}</td>
+</tr>{{end}}
+</tbody>
+<tfoot><tr><th colspan="4" class="num">Total</th><th class="num">{{rupiah .Total}}</th></tr></tfoot>
+</table>
+</body>
+</html>
+`))
+
+const invoiceCSP = "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'"
+
+var rupiahPrinter = message.NewPrinter(language.Indonesian)
+
+func formatRupiah(amount float64) string {
+	return rupiahPrinter.Sprintf("Rp%.0f", amount)
+}
+
+func formatVariantOptions(options map[string]string) string {
+	parts := make([]string, 0, len(options))
+	for name, value := range options {
+		parts = append(parts, name+": "+value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// GetOrderInvoice mengembalikan invoice order. Default JSON; format=html
+// menghasilkan halaman yang siap dicetak.
+func GetOrderInvoice(c *gin.Context) {
+	invoice, order, err := services.GetOrderInvoice(c.Param("id"))
+	if err != nil {
+		respondInvoiceError(c, err)
+		return
+	}
+	if !authorize(c, policy.OrdersRead, order.UserID) {
+		return
+	}
+	respondInvoice(c, invoice)
+}
+
+// GetSellerInvoice mengembalikan invoice yang hanya memuat item seller yang login.
+func GetSellerInvoice(c *gin.Context) {
+	invoice, err := services.GetSellerInvoice(c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		respondInvoiceError(c, err)
+		return
+	}
+	respondInvoice(c, invoice)
+}
+
+func respondInvoice(c *gin.Context, invoice *services.Invoice) {
+	if c.Query("format") != "html" {
+		c.JSON(http.StatusOK, invoice)
+		return
+	}
+	var buf bytes.Buffer
+	if err := invoiceTemplate.Execute(&buf, invoice); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render invoice"})
+		return
+	}
+	// CSP global (default-src 'none') memblokir <style> di template; script
+	// tetap dilarang dan semua isi dinamis di-escape oleh html/template.
+	c.Header("Content-Security-Policy", invoiceCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func respondInvoiceError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvoiceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invoice"})
+}
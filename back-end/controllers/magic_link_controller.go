@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"ecommerce-backend/services"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RequestMagicLink(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lc := loginContext(c)
+	if err := services.CheckIPAllowed(lc.IPAddress); err != nil {
+		respondLoginLocked(c, err)
+		return
+	}
+
+	if err := services.RequestMagicLink(req.Email, lc); err != nil {
+		log.Printf("Failed to send magic link to %s: %v", req.Email, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a login link has been sent"})
+}
+
+// ConsumeMagicLink menukar token dari email dengan sesi login, sama seperti Login
+// (termasuk langkah 2FA jika akun membutuhkannya).
+func ConsumeMagicLink(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lc := loginContext(c)
+	if err := services.CheckIPAllowed(lc.IPAddress); err != nil {
+		respondLoginLocked(c, err)
+		return
+	}
+
+	user, err := services.ConsumeMagicLink(req.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrMagicLinkDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.CheckAccountAllowed(user.ID); err != nil {
+		respondLoginLocked(c, err)
+		return
+	}
+
+	completeLogin(c, user)
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/services"
+	"ecommerce-backend/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFAChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// EnrollMFAWithChallenge dipakai saat role mewajibkan 2FA tetapi user belum
+// mendaftarkan authenticator; user belum punya sesi sehingga memakai challenge token.
+func EnrollMFAWithChallenge(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := utils.ValidatePurposeToken(req.ChallengeToken, utils.TokenPurposeMFA)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+
+	enrollment, err := services.BeginMFAEnrollment(claims.UserID)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// VerifyMFALogin adalah langkah kedua login. Jika user sedang dalam proses
+// enrollment, kode pertama yang valid sekaligus mengaktifkan 2FA.
+func VerifyMFALogin(c *gin.Context) {
+	var req MFAChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := utils.ValidatePurposeToken(req.ChallengeToken, utils.TokenPurposeMFA)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+
+	lc := loginContext(c)
+	if err := services.CheckIPAllowed(lc.IPAddress); err != nil {
+		respondLoginLocked(c, err)
+		return
+	}
+	if err := services.CheckMFAChallengeAllowed(claims.ID); err != nil {
+		respondLoginLocked(c, err)
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Where("id = ? AND is_active = ?", claims.UserID, true).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+	if err := services.CheckAccountAllowed(user.ID); err != nil {
+		respondLoginLocked(c, err)
+		return
+	}
+
+	var extra gin.H
+	if user.MFAEnabled {
+		err = services.VerifyMFA(user.ID, req.Code, req.RecoveryCode)
+	} else {
+		var recoveryCodes []string
+		recoveryCodes, err = services.ConfirmMFAEnrollment(user.ID, req.Code)
+		extra = gin.H{"recovery_codes": recoveryCodes}
+		user.MFAEnabled = err == nil
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMFACode) {
+			services.RecordMFAFailure(user.ID, claims.ID, lc)
+		}
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	startSession(c, &user, "Login successful", extra)
+}
+
+func SetupMFA(c *gin.Context) {
+	enrollment, err := services.BeginMFAEnrollment(c.GetString("userID"))
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func EnableMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recoveryCodes, err := services.ConfirmMFAEnrollment(c.GetString("userID"), req.Code)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+func DisableMFA(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.DisableMFA(c.GetString("userID"), req.Code); err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recoveryCodes, err := services.RegenerateRecoveryCodes(c.GetString("userID"), req.Code)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrMFANotAllowedForRole),
+		errors.Is(err, services.ErrMFARequiredByPolicy):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrMFAAlreadyEnabled),
+		errors.Is(err, services.ErrMFANotEnabled),
+		errors.Is(err, services.ErrMFANotStarted):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
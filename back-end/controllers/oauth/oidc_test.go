@@ -0,0 +1,138 @@
+package oauth_test
+
+import (
+	"context"
+	"ecommerce-backend/controllers/oauth"
+	"ecommerce-backend/controllers/oauth/mockoidc"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const (
+	testClientID    = "mock-client"
+	testRedirectURL = "http://localhost:8080/auth/oauth/mock/callback"
+)
+
+func startMockProvider(t *testing.T, emailVerified bool) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(ts.Close)
+
+	server, err := mockoidc.New(mockoidc.Config{
+		Issuer:        ts.URL,
+		Subject:       "mock-user-1",
+		Email:         "Buyer@Example.com",
+		EmailVerified: emailVerified,
+		Name:          "Mock Buyer",
+	})
+	if err != nil {
+		t.Fatalf("mockoidc.New: %v", err)
+	}
+	ts.Config.Handler = server
+	return ts
+}
+
+// authorize mengikuti URL authorize seperti browser dan mengembalikan code
+// serta state dari redirect ke callback.
+func authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want 302", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	if got := location.Scheme + "://" + location.Host + location.Path; got != testRedirectURL {
+		t.Fatalf("redirect to %s, want %s", got, testRedirectURL)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestOIDCLoginAgainstMockProvider(t *testing.T) {
+	tests := []struct {
+		name          string
+		emailVerified bool
+		// tamper mengubah verifier/nonce yang dipakai saat exchange
+		tamper  func(verifier, nonce string) (string, string)
+		wantErr bool
+	}{
+		{name: "valid login", emailVerified: true},
+		{name: "unverified email is reported", emailVerified: false},
+		{
+			name:    "wrong PKCE verifier",
+			tamper:  func(_, nonce string) (string, string) { return "wrong-verifier", nonce },
+			wantErr: true,
+		},
+		{
+			name:    "nonce mismatch",
+			tamper:  func(verifier, _ string) (string, string) { return verifier, "other-nonce" },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := startMockProvider(t, tt.emailVerified)
+			provider := oauth.NewOIDCProvider(oauth.OIDCConfig{
+				Name:        "mock",
+				Issuer:      ts.URL,
+				ClientID:    testClientID,
+				RedirectURL: testRedirectURL,
+				Scopes:      []string{"openid", "email", "profile"},
+				HTTPClient:  ts.Client(),
+			})
+
+			state, _ := oauth.RandomString()
+			verifier, _ := oauth.RandomString()
+			nonce, _ := oauth.RandomString()
+			ctx := context.Background()
+
+			authURL, err := provider.AuthCodeURL(ctx, state, oauth.CodeChallenge(verifier), nonce)
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+			code, gotState := authorize(t, authURL)
+			if gotState != state {
+				t.Fatalf("state = %q, want %q", gotState, state)
+			}
+
+			if tt.tamper != nil {
+				verifier, nonce = tt.tamper(verifier, nonce)
+			}
+			identity, err := provider.Exchange(ctx, code, verifier, nonce)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Exchange succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+
+			if identity.Subject != "mock-user-1" || identity.Email != "buyer@example.com" || identity.Name != "Mock Buyer" {
+				t.Errorf("identity = %+v", identity)
+			}
+			if identity.EmailVerified != tt.emailVerified {
+				t.Errorf("EmailVerified = %v, want %v", identity.EmailVerified, tt.emailVerified)
+			}
+
+			// Authorization code hanya boleh ditukar sekali
+			if _, err := provider.Exchange(ctx, code, verifier, nonce); err == nil {
+				t.Error("second Exchange with the same code succeeded")
+			}
+		})
+	}
+}
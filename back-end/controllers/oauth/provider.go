@@ -0,0 +1,97 @@
+// Package oauth berisi provider login sosial (OAuth2 authorization code + PKCE
+// dan validasi ID token OpenID Connect) yang dipakai oleh auth controller.
+package oauth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownProvider = errors.New("unknown oauth provider")
+
+// Identity adalah data user yang sudah tervalidasi dari ID token provider.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Provider interface {
+	Name() string
+	// AuthCodeURL membangun URL authorize dengan state, PKCE S256 challenge dan nonce.
+	AuthCodeURL(ctx context.Context, state, codeChallenge, nonce string) (string, error)
+	// Exchange menukar authorization code dan memvalidasi ID token yang diterima.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
+
+var (
+	registry     map[string]Provider
+	registryOnce sync.Once
+)
+
+// Get mengembalikan provider yang dikonfigurasi lewat environment.
+//
+//	OAUTH_PROVIDERS=google,mock
+//	OAUTH_GOOGLE_CLIENT_ID=...            (wajib)
+//	OAUTH_GOOGLE_CLIENT_SECRET=...
+//	OAUTH_GOOGLE_REDIRECT_URL=...         (wajib)
+//	OAUTH_GOOGLE_ISSUER=...               (default untuk google: https://accounts.google.com)
+//	OAUTH_GOOGLE_SCOPES=openid email profile
+func Get(name string) (Provider, error) {
+	registryOnce.Do(func() {
+		registry = loadFromEnv()
+	})
+
+	p, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Register menambahkan atau mengganti provider, misalnya untuk mock server di test.
+func Register(p Provider) {
+	registryOnce.Do(func() {
+		registry = loadFromEnv()
+	})
+	registry[strings.ToLower(p.Name())] = p
+}
+
+var defaultIssuers = map[string]string{
+	"google": "https://accounts.google.com",
+}
+
+func loadFromEnv() map[string]Provider {
+	providers := make(map[string]Provider)
+	for _, name := range strings.Split(os.Getenv("OAUTH_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		issuer := os.Getenv(prefix + "ISSUER")
+		if issuer == "" {
+			issuer = defaultIssuers[name]
+		}
+
+		scopes := strings.Fields(os.Getenv(prefix + "SCOPES"))
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+
+		providers[name] = NewOIDCProvider(OIDCConfig{
+			Name:         name,
+			Issuer:       issuer,
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       scopes,
+		})
+	}
+	return providers
+}
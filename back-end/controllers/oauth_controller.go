@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"ecommerce-backend/controllers/oauth"
+	"ecommerce-backend/models"
+	"ecommerce-backend/services"
+	"ecommerce-backend/utils"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthLogin memulai authorization code flow dengan PKCE. Default-nya
+// redirect ke provider; dengan ?mode=json URL-nya dikembalikan ke front-end.
+func OAuthLogin(c *gin.Context) {
+	authURL, ok := startOAuthFlow(c, "", "")
+	if !ok {
+		return
+	}
+
+	if c.Query("mode") == "json" {
+		c.JSON(http.StatusOK, gin.H{"authorization_url": authURL})
+		return
+	}
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OAuthLink memulai flow untuk menautkan provider ke akun yang sedang login.
+// Password wajib dimasukkan ulang, sehingga access token yang bocor saja tidak
+// cukup untuk menautkan akun provider milik orang lain.
+func OAuthLink(c *gin.Context) {
+	var req struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := services.GetUserByID(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	if !utils.CheckPassword(user.Password, req.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": services.ErrIncorrectPassword.Error()})
+		return
+	}
+
+	authURL, ok := startOAuthFlow(c, user.ID, c.GetString("sessionID"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"authorization_url": authURL})
+}
+
+func startOAuthFlow(c *gin.Context, linkUserID, linkSessionID string) (string, bool) {
+	provider, err := oauth.Get(c.Param("provider"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return "", false
+	}
+
+	state, err := oauth.RandomString()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return "", false
+	}
+	verifier, err := oauth.RandomString()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return "", false
+	}
+	nonce, err := oauth.RandomString()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return "", false
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, oauth.CodeChallenge(verifier), nonce)
+	if err != nil {
+		log.Printf("Failed to build %s authorization URL: %v", provider.Name(), err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Login provider is unavailable"})
+		return "", false
+	}
+
+	if err := services.SaveOAuthState(provider.Name(), state, verifier, nonce, linkUserID, linkSessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return "", false
+	}
+	return authURL, true
+}
+
+// OAuthCallback dibuka browser setelah kembali dari provider, sehingga hasilnya
+// selalu berupa redirect ke FRONTEND_URL/oauth/callback: ?code= (sekali pakai,
+// ditukar lewat POST /auth/oauth/exchange) atau ?error=.
+func OAuthCallback(c *gin.Context) {
+	provider, err := oauth.Get(c.Param("provider"))
+	if err != nil {
+		redirectOAuthResult(c, "error", err.Error())
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		redirectOAuthResult(c, "error", "Login was cancelled or denied: "+providerErr)
+		return
+	}
+
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		redirectOAuthResult(c, "error", "Missing code or state")
+		return
+	}
+
+	saved, err := services.ConsumeOAuthState(provider.Name(), state)
+	if err != nil {
+		redirectOAuthResult(c, "error", err.Error())
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), code, saved.CodeVerifier, saved.Nonce)
+	if err != nil {
+		log.Printf("OAuth exchange with %s failed: %v", provider.Name(), err)
+		redirectOAuthResult(c, "error", "Failed to verify login with provider")
+		return
+	}
+
+	oauthIdentity := services.OAuthIdentity{
+		Provider:      provider.Name(),
+		Subject:       identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		Name:          identity.Name,
+	}
+	var user *models.User
+	if saved.LinkUserID != nil {
+		user, err = services.LinkOAuthIdentity(*saved.LinkUserID, saved.LinkSessionID, oauthIdentity)
+	} else {
+		user, err = services.LinkOrCreateOAuthUser(oauthIdentity)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOAuthEmailNotVerified),
+			errors.Is(err, services.ErrAccountDisabled),
+			errors.Is(err, services.ErrOAuthAccountExists),
+			errors.Is(err, services.ErrOAuthIdentityInUse):
+			redirectOAuthResult(c, "error", err.Error())
+		default:
+			log.Printf("Failed to link %s identity: %v", provider.Name(), err)
+			redirectOAuthResult(c, "error", "Failed to sign in")
+		}
+		return
+	}
+
+	loginCode, err := services.IssueOAuthLoginCode(user.ID)
+	if err != nil {
+		redirectOAuthResult(c, "error", "Failed to sign in")
+		return
+	}
+	redirectOAuthResult(c, "code", loginCode)
+}
+
+func redirectOAuthResult(c *gin.Context, key, value string) {
+	params := url.Values{}
+	params.Set(key, value)
+	c.Redirect(http.StatusFound, services.FrontendURL()+"/oauth/callback?"+params.Encode())
+}
+
+// OAuthExchange menukar code dari redirect callback dengan sesi, atau dengan
+// challenge MFA jika akun mewajibkannya.
+func OAuthExchange(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := services.ConsumeOAuthLoginCode(req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidOAuthLoginCode):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAccountDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		}
+		return
+	}
+
+	completeLogin(c, user)
+}
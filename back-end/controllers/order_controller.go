@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"ecommerce-backend/models"
+	"ecommerce-backend/policy"
 	"ecommerce-backend/services"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -27,6 +29,10 @@ func CreateOrder(c *gin.Context) {
 
 	// Validasi dan buat order
 	if err := services.CreateOrder(&order); err != nil {
+		if errors.Is(err, services.ErrVariantRequired) || errors.Is(err, services.ErrVariantNotFound) || errors.Is(err, services.ErrProductUnavailable) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -51,6 +57,9 @@ func GetOrderByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
 	}
+	if !authorize(c, policy.OrdersRead, order.UserID) {
+		return
+	}
 	c.JSON(http.StatusOK, order)
 }
 
@@ -77,6 +86,18 @@ func UpdateOrder(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if order.ID == "" {
+		order.ID = c.Param("id")
+	}
+
+	existingOrder, err := services.GetOrderByID(order.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+	if !authorize(c, policy.OrdersUpdate, existingOrder.UserID) {
+		return
+	}
 
 	if err := services.UpdateOrder(&order); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -91,6 +112,15 @@ func UpdateOrder(c *gin.Context) {
 // DeleteOrder menangani penghapusan order
 func DeleteOrder(c *gin.Context) {
 	id := c.Param("id")
+	order, err := services.GetOrderByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+	if !authorize(c, policy.OrdersDelete, order.UserID) {
+		return
+	}
+
 	if err := services.DeleteOrder(id); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
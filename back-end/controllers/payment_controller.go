@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"ecommerce-backend/policy"
 	"ecommerce-backend/services"
 	"net/http"
 
@@ -18,6 +19,15 @@ func CreatePayment(c *gin.Context) {
 		return
 	}
 
+	order, err := services.GetOrderByID(req.OrderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+	if !authorize(c, policy.OrdersPay, order.UserID) {
+		return
+	}
+
 	snapToken, err := services.CreateSnapToken(req.OrderID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
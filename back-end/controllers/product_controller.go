@@ -2,9 +2,11 @@ package controllers
 
 import (
 	"ecommerce-backend/models"
+	"ecommerce-backend/policy"
 	"ecommerce-backend/services"
+	"errors"
 	"log"
-	"path/filepath"
+	"sort"
 	"strings"
 
 	"net/http"
@@ -25,6 +27,19 @@ func CreateProduct(c *gin.Context) {
 		return
 	}
 
+	// status opsional: "draft" atau "published" (default)
+	status, err := services.InitialProductStatus(c.PostForm("status"), policy.Can(c.GetString("role"), policy.ProductsReview))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be draft or published"})
+		return
+	}
+
+	// attributes: objek JSON {key: nilai} sesuai skema atribut kategori
+	attributes, ok := productAttributes(c, categoryID, map[string]string{})
+	if !ok {
+		return
+	}
+
 	price, err := strconv.ParseFloat(priceStr, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid price"})
@@ -48,29 +63,27 @@ func CreateProduct(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Image file is required"})
 		return
 	}
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".webp" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File harus berupa gambar (.jpg, .jpeg, .png, .webp)"})
-		return
-	}
-	supa := services.NewSupabaseStorage()
-	imageURL, err := supa.Upload(file)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image"})
+	image, ok := uploadProductImage(c, file)
+	if !ok {
 		return
 	}
 
 	product := models.Product{
-		Name:        name,
-		Description: description,
-		Price:       price,
-		Stock:       stock,
-		SellerID:    userID.(string),
-		CategoryID:  categoryID,
-		ImageURL:    imageURL,
+		Status:          status,
+		Name:            name,
+		Description:     description,
+		Price:           price,
+		Stock:           stock,
+		SellerID:        userID.(string),
+		CategoryID:      categoryID,
+		ImageURL:        image.URL,
+		ImageRenditions: image.Renditions,
+		Images:          []models.ProductImage{{URL: image.URL, AltText: name, IsPrimary: true, Renditions: image.Renditions}},
+		Attributes:      attributes,
 	}
 
 	if err := services.CreateProduct(&product); err != nil {
+		services.DiscardUploadedImage(image)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -84,19 +97,144 @@ func CreateProduct(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Product created successfully", "product": createdProduct})
 }
 
+// GetProducts mendukung:
+//
+//	page, limit                 pagination offset (default page=1, limit=20, maks 100)
+//	cursor                      pagination keyset; kirim cursor= kosong untuk halaman pertama
+//	category_id, category       filter kategori (id atau nama)
+//	seller_id                   filter seller
+//	min_price, max_price        rentang harga
+//	min_rating                  rating minimum
+//	in_stock=true               hanya produk dengan stok
+//	sort                        newest (default), price_asc, price_desc, rating, best_selling
+//	attr.<key>=a,b              produk dengan atribut key bernilai a atau b
+//	attr.<key>.min, .max        rentang untuk atribut number (maks 10 key, harus atribut filterable)
+//	facets=true                 sertakan jumlah produk per nilai atribut
 func GetProducts(c *gin.Context) {
-	products, err := services.GetProducts()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+	params, ok := parseProductListParams(c)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, products)
+	respondProductPage(c, params)
+}
+
+func parseProductListParams(c *gin.Context) (services.ProductListParams, bool) {
+	params := services.ProductListParams{
+		CategoryID: c.Query("category_id"),
+		Category:   c.Query("category"),
+		SellerID:   c.Query("seller_id"),
+		InStock:    c.Query("in_stock") == "true",
+		Sort:       c.DefaultQuery("sort", "newest"),
+		Facets:     c.Query("facets") == "true",
+	}
+	params.Cursor, params.UseCursor = c.GetQuery("cursor")
+	if !parseAttributeFilters(c, &params) {
+		return params, false
+	}
+
+	var err error
+	if params.Page, err = queryInt(c, "page"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return params, false
+	}
+	if params.Limit, err = queryInt(c, "limit"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return params, false
+	}
+	for key, target := range map[string]**float64{
+		"min_price":  &params.MinPrice,
+		"max_price":  &params.MaxPrice,
+		"min_rating": &params.MinRating,
+	} {
+		if *target, err = queryFloat(c, key); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + key})
+			return params, false
+		}
+	}
+	return params, true
 }
 
+func parseAttributeFilters(c *gin.Context, params *services.ProductListParams) bool {
+	filters := map[string]*services.AttributeFilter{}
+	keys := make([]string, 0)
+	for name, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(name, "attr.") || len(values) == 0 || values[0] == "" {
+			continue
+		}
+		key, bound, _ := strings.Cut(strings.TrimPrefix(name, "attr."), ".")
+		filter, ok := filters[key]
+		if !ok {
+			filter = &services.AttributeFilter{Key: key}
+			filters[key] = filter
+			keys = append(keys, key)
+		}
+
+		switch bound {
+		case "":
+			for _, v := range strings.Split(values[0], ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					filter.Values = append(filter.Values, v)
+				}
+			}
+		case "min", "max":
+			f, err := strconv.ParseFloat(values[0], 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+				return false
+			}
+			if bound == "min" {
+				filter.Min = &f
+			} else {
+				filter.Max = &f
+			}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter " + name})
+			return false
+		}
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		params.Attributes = append(params.Attributes, *filters[key])
+	}
+
+	var validationErr *services.AttributeValidationError
+	if err := services.ValidateAttributeFilters(params.Attributes); errors.As(err, &validationErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		return false
+	}
+	return true
+}
+
+func queryInt(c *gin.Context, key string) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func queryFloat(c *gin.Context, key string) (*float64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// GetProductByID hanya menampilkan produk published; seller melihat draft dan
+// arsipnya sendiri lewat GET /seller/products/:id.
 func GetProductByID(c *gin.Context) {
 	id := c.Param("id")
 	product, err := services.GetProductByID(id)
-	if err != nil {
+	if err != nil || product.Status != models.ProductStatusPublished {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
 	}
@@ -112,9 +250,7 @@ func UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("userID")
-	if existingProduct.SellerID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to update this product"})
+	if !authorize(c, policy.ProductsUpdate, existingProduct.SellerID) {
 		return
 	}
 
@@ -135,11 +271,24 @@ func UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	// Tanpa field attributes, nilai lama divalidasi ulang terhadap skema kategori
+	current, err := services.CurrentProductAttributes(existingProduct, categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load product attributes"})
+		return
+	}
+	attributes, ok := productAttributes(c, categoryID, current)
+	if !ok {
+		return
+	}
+
+	before := *existingProduct
 	existingProduct.Name = name
 	existingProduct.Description = description
 	existingProduct.Price = price
 	existingProduct.Stock = stock
 	existingProduct.CategoryID = categoryID
+	existingProduct.Attributes = attributes
 
 	file, err := c.FormFile("image_url")
 	if err != nil {
@@ -147,28 +296,33 @@ func UpdateProduct(c *gin.Context) {
 	} else {
 		log.Println("File ditemukan:", file.Filename)
 
-		ext := strings.ToLower(filepath.Ext(file.Filename))
-		if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".webp" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "File harus berupa gambar (.jpg, .jpeg, .png, .webp)"})
+		image, ok := uploadProductImage(c, file)
+		if !ok {
 			return
 		}
-		supa := services.NewSupabaseStorage()
-		_ = supa.Delete(existingProduct.ImageURL)
 
-		imageURL, err := supa.Upload(file)
-		if err != nil {
-			log.Println("Gagal upload:", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image"})
+		// Gambar baru masuk ke galeri sebagai primary; gambar lama tetap tersimpan
+		input := services.ProductImageInput{URL: image.URL, AltText: name, Renditions: image.Renditions}
+		if _, err := services.AddProductImages(existingProduct.ID, []services.ProductImageInput{input}, true); err != nil {
+			services.DiscardUploadedImage(image)
+			if errors.Is(err, services.ErrTooManyImages) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
 			return
 		}
-
-		existingProduct.ImageURL = imageURL
+		existingProduct.ImageURL = image.URL
 	}
 
-	if err := services.UpdateProduct(existingProduct); err != nil {
+	requireReview := services.NeedsReviewAfterEdit(&before, existingProduct, policy.Can(c.GetString("role"), policy.ProductsReview))
+	if err := services.UpdateProduct(existingProduct, requireReview); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
 		return
 	}
+	if updated, err := services.GetProductByID(existingProduct.ID); err == nil {
+		existingProduct = updated
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Product updated successfully",
@@ -176,6 +330,8 @@ func UpdateProduct(c *gin.Context) {
 	})
 }
 
+// DeleteProduct mengarsipkan produk. Produk tidak dihapus permanen supaya
+// riwayat order, review dan total order tetap utuh.
 func DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 	product, err := services.GetProductByID(id)
@@ -184,32 +340,103 @@ func DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("userID")
-	if product.SellerID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to delete this product"})
+	if !authorize(c, policy.ProductsDelete, product.SellerID) {
 		return
 	}
 
-	if err := services.DeleteProduct(product); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product"})
+	if err := services.ArchiveProduct(product); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive product"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Product deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Product archived successfully", "product": product})
 }
 
+// SearchProducts menerima q beserta filter dan pagination offset yang sama dengan
+// GetProducts. Hasil diurutkan berdasarkan relevansi dan menyertakan score serta
+// highlights (HTML dengan <mark>) untuk nama dan deskripsi.
 func SearchProducts(c *gin.Context) {
-	query := c.Query("q")
+	query := strings.TrimSpace(c.Query("q"))
 	if query == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
 		return
 	}
 
-	products, err := services.SearchProducts(query)
+	params, ok := parseProductListParams(c)
+	if !ok {
+		return
+	}
+
+	results, err := services.SearchProducts(query, params, c.ClientIP())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search products"})
 		return
 	}
 
-	c.JSON(http.StatusOK, products)
+	c.JSON(http.StatusOK, results)
+}
+
+// SuggestProducts mengembalikan autocomplete untuk search bar: nama produk,
+// nama kategori dan query populer yang diawali q, dengan toleransi typo.
+func SuggestProducts(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		return
+	}
+
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	suggestions, err := services.SuggestProducts(query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suggestions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func ReindexProducts(c *gin.Context) {
+	indexed, err := services.ReindexProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rebuild search index"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Search index rebuilt", "indexed": indexed})
+}
+
+// productAttributes membaca field form attributes lalu memvalidasinya terhadap
+// skema atribut kategori; current dipakai jika field tidak dikirim.
+func productAttributes(c *gin.Context, categoryID string, current map[string]string) ([]models.ProductAttributeValue, bool) {
+	input := current
+	if raw, ok := c.GetPostForm("attributes"); ok {
+		var err error
+		if input, err = services.ParseAttributeInput(raw); err != nil {
+			respondAttributeError(c, err)
+			return nil, false
+		}
+	}
+
+	values, err := services.ValidateProductAttributes(categoryID, input)
+	if err != nil {
+		respondAttributeError(c, err)
+		return nil, false
+	}
+	return values, true
+}
+
+func respondAttributeError(c *gin.Context, err error) {
+	var validationErr *services.AttributeValidationError
+	switch {
+	case errors.As(err, &validationErr), errors.Is(err, services.ErrInvalidAttributes):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attributes"})
+	}
 }
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"ecommerce-backend/imageproc"
+	"ecommerce-backend/models"
+	"ecommerce-backend/policy"
+	"ecommerce-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// productForImageChange memuat produk dan memastikan user boleh mengubahnya.
+func productForImageChange(c *gin.Context) (*models.Product, bool) {
+	product, err := services.GetProductByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return nil, false
+	}
+	if !authorize(c, policy.ProductsUpdate, product.SellerID) {
+		return nil, false
+	}
+	return product, true
+}
+
+func GetProductImages(c *gin.Context) {
+	images, err := services.GetProductImages(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product images"})
+		return
+	}
+	c.JSON(http.StatusOK, images)
+}
+
+// AddProductImages menerima multipart form dengan satu atau lebih file "images",
+// "alt_text" opsional dengan urutan yang sama, dan "primary=true" untuk menjadikan
+// gambar pertama sebagai primary.
+func AddProductImages(c *gin.Context) {
+	product, ok := productForImageChange(c)
+	if !ok {
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["images"]) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one file in 'images' is required"})
+		return
+	}
+	files := form.File["images"]
+	if len(product.Images)+len(files) > services.MaxProductImages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrTooManyImages.Error()})
+		return
+	}
+
+	altTexts := form.Value["alt_text"]
+	inputs := make([]services.ProductImageInput, 0, len(files))
+	uploaded := make([]*services.UploadedImage, 0, len(files))
+	for i, file := range files {
+		image, ok := uploadProductImage(c, file)
+		if !ok {
+			for _, u := range uploaded {
+				services.DiscardUploadedImage(u)
+			}
+			return
+		}
+		uploaded = append(uploaded, image)
+		input := services.ProductImageInput{URL: image.URL, AltText: product.Name, Renditions: image.Renditions}
+		if i < len(altTexts) && strings.TrimSpace(altTexts[i]) != "" {
+			input.AltText = strings.TrimSpace(altTexts[i])
+		}
+		inputs = append(inputs, input)
+	}
+
+	images, err := services.AddProductImages(product.ID, inputs, c.PostForm("primary") == "true")
+	if err != nil {
+		for _, u := range uploaded {
+			services.DiscardUploadedImage(u)
+		}
+		respondProductImageError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Images added", "images": images})
+}
+
+type reorderImagesRequest struct {
+	ImageIDs []string `json:"image_ids" binding:"required"`
+}
+
+func ReorderProductImages(c *gin.Context) {
+	product, ok := productForImageChange(c)
+	if !ok {
+		return
+	}
+
+	var req reorderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	images, err := services.ReorderProductImages(product.ID, req.ImageIDs)
+	if err != nil {
+		respondProductImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Images reordered", "images": images})
+}
+
+type updateImageRequest struct {
+	AltText   *string `json:"alt_text" binding:"omitempty,max=255"`
+	IsPrimary bool    `json:"is_primary"`
+}
+
+func UpdateProductImage(c *gin.Context) {
+	product, ok := productForImageChange(c)
+	if !ok {
+		return
+	}
+
+	var req updateImageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	image, err := services.UpdateProductImage(product.ID, c.Param("imageId"), req.AltText, req.IsPrimary)
+	if err != nil {
+		respondProductImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Image updated", "image": image})
+}
+
+func DeleteProductImage(c *gin.Context) {
+	product, ok := productForImageChange(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteProductImage(product.ID, c.Param("imageId")); err != nil {
+		respondProductImageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Image deleted"})
+}
+
+// uploadProductImage menjalankan pipeline gambar dan menulis respons error jika gagal.
+func uploadProductImage(c *gin.Context, file *multipart.FileHeader) (*services.UploadedImage, bool) {
+	image, err := services.UploadProductImage(file)
+	if err != nil {
+		if imageproc.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image"})
+		return nil, false
+	}
+	return image, true
+}
+
+func respondProductImageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrProductImageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTooManyImages), errors.Is(err, services.ErrInvalidImageOrder):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product images"})
+	}
+}
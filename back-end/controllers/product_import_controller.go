@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"ecommerce-backend/services"
+	"ecommerce-backend/spreadsheet"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportProducts menerima multipart form dengan "file" (.csv atau .xlsx) dan
+// opsional dry_run=true. Import berjalan di background; progres dan error per
+// baris bisa dipantau lewat GET /seller/products/imports/:id.
+func ImportProducts(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+
+	job, err := services.StartProductImport(c.GetString("userID"), file, c.PostForm("dry_run") == "true")
+	if err != nil {
+		var headerErr *services.ImportHeaderError
+		switch {
+		case errors.Is(err, services.ErrImportInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrImportTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, spreadsheet.ErrUnsupportedFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "File must be .csv or .xlsx"})
+		case errors.As(err, &headerErr),
+			errors.Is(err, services.ErrImportEmpty),
+			errors.Is(err, spreadsheet.ErrTooManyRows),
+			errors.Is(err, spreadsheet.ErrInvalidFile):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			// Error parsing CSV menyebutkan baris yang rusak, jadi aman diteruskan
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Import started", "job": job})
+}
+
+func GetProductImportJobs(c *gin.Context) {
+	jobs, err := services.GetProductImportJobs(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch import jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": jobs})
+}
+
+func GetProductImportJob(c *gin.Context) {
+	job, err := services.GetProductImportJob(c.GetString("userID"), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrImportJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch import job"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// ExportProducts mengunduh katalog seller dengan kolom yang sama seperti file
+// import. format=csv (default) atau xlsx.
+func ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", spreadsheet.FormatCSV)
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	rows, err := services.ExportSellerProducts(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export products"})
+		return
+	}
+
+	filename := "products-" + time.Now().Format("20060102") + "." + format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Content-Type", spreadsheet.ContentType(format))
+	c.Status(http.StatusOK)
+	if err := spreadsheet.Write(format, c.Writer, rows); err != nil {
+		c.Error(err)
+	}
+}
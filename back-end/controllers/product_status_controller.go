@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"ecommerce-backend/models"
+	"ecommerce-backend/policy"
+	"ecommerce-backend/services"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChangeProductStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// ChangeProductStatus memindahkan produk antar status (draft, published,
+// archived). Jika review produk diwajibkan, publish oleh seller menghasilkan
+// pending_review; admin menyetujuinya dengan status published.
+func ChangeProductStatus(c *gin.Context) {
+	var req ChangeProductStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := services.GetProductByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if !authorize(c, policy.ProductsUpdate, product.SellerID) {
+		return
+	}
+
+	canReview := policy.Can(c.GetString("role"), policy.ProductsReview)
+	if err := services.ChangeProductStatus(product, req.Status, canReview); err != nil {
+		if errors.Is(err, services.ErrInvalidProductStatus) || errors.Is(err, services.ErrInvalidStatusTransition) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change product status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product status updated", "product": product})
+}
+
+// GetSellerProducts menampilkan katalog seller sendiri di semua status.
+// Menerima parameter yang sama dengan GetProducts ditambah status (satu atau
+// beberapa dipisah koma, default semua).
+func GetSellerProducts(c *gin.Context) {
+	params, ok := parseManagedProductListParams(c, "")
+	if !ok {
+		return
+	}
+	params.SellerID = c.GetString("userID")
+	respondProductPage(c, params)
+}
+
+func GetSellerProduct(c *gin.Context) {
+	product, err := services.GetProductByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if !authorize(c, policy.ProductsUpdate, product.SellerID) {
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// GetAdminProducts menampilkan produk semua seller untuk moderasi, default
+// yang menunggu review.
+func GetAdminProducts(c *gin.Context) {
+	params, ok := parseManagedProductListParams(c, models.ProductStatusPendingReview)
+	if !ok {
+		return
+	}
+	respondProductPage(c, params)
+}
+
+func parseManagedProductListParams(c *gin.Context, defaultStatus string) (services.ProductListParams, bool) {
+	params, ok := parseProductListParams(c)
+	if !ok {
+		return params, false
+	}
+
+	status := c.DefaultQuery("status", defaultStatus)
+	if status == "" || status == "all" {
+		params.Statuses = models.ProductStatuses
+		return params, true
+	}
+	for _, s := range strings.Split(status, ",") {
+		s = strings.TrimSpace(s)
+		if !slices.Contains(models.ProductStatuses, s) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status " + s})
+			return params, false
+		}
+		params.Statuses = append(params.Statuses, s)
+	}
+	return params, true
+}
+
+func respondProductPage(c *gin.Context, params services.ProductListParams) {
+	page, err := services.GetProducts(params)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"ecommerce-backend/policy"
+	"ecommerce-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetProductVariants mengganti sumbu opsi dan daftar SKU produk, misalnya:
+//
+//	{"options": [{"name": "Ukuran", "values": ["S", "M"]}],
+//	 "variants": [{"sku": "KAOS-S", "price": 75000, "stock": 10, "options": {"Ukuran": "S"}}]}
+func SetProductVariants(c *gin.Context) {
+	product, err := services.GetProductByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if !authorize(c, policy.ProductsUpdate, product.SellerID) {
+		return
+	}
+
+	var input services.ProductVariantsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := services.SetProductVariants(product.ID, input)
+	if err != nil {
+		respondVariantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product variants updated", "product": updated})
+}
+
+func UpdateProductVariant(c *gin.Context) {
+	product, err := services.GetProductByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if !authorize(c, policy.ProductsUpdate, product.SellerID) {
+		return
+	}
+
+	var input services.ProductVariantUpdate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant, err := services.UpdateProductVariant(product.ID, c.Param("variantId"), input)
+	if err != nil {
+		respondVariantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Variant updated", "variant": variant})
+}
+
+func respondVariantError(c *gin.Context, err error) {
+	var validationErr *services.VariantValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSKUTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrVariantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product variants"})
+	}
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"ecommerce-backend/models"
+	"ecommerce-backend/policy"
 	"ecommerce-backend/services"
 	"net/http"
 
@@ -67,6 +68,15 @@ func (rc *ReviewController) GetReviewsByProduct(c *gin.Context) {
 
 func (rc *ReviewController) DeleteReview(c *gin.Context) {
 	reviewID := c.Param("id")
+	review, err := services.GetReviewByID(reviewID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !authorize(c, policy.ReviewsDelete, review.UserID) {
+		return
+	}
+
 	if err := services.DeleteReview(reviewID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
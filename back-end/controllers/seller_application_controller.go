@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"ecommerce-backend/models"
+	"ecommerce-backend/services"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxSellerApplicationDocuments = 5
+
+// SubmitSellerApplication menerima multipart form: shop_name, legal_name, tax_id
+// dan satu atau lebih file "documents" (KTP, NPWP, dokumen usaha).
+func SubmitSellerApplication(c *gin.Context) {
+	shopName := strings.TrimSpace(c.PostForm("shop_name"))
+	legalName := strings.TrimSpace(c.PostForm("legal_name"))
+	taxID := strings.TrimSpace(c.PostForm("tax_id"))
+	if shopName == "" || legalName == "" || taxID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "shop_name, legal_name, and tax_id are required"})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["documents"]) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one document is required"})
+		return
+	}
+	files := form.File["documents"]
+	if len(files) > maxSellerApplicationDocuments {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many documents, maximum is 5"})
+		return
+	}
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Filename))
+		if ext != ".pdf" && ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Dokumen harus berupa .pdf, .jpg, .jpeg, atau .png"})
+			return
+		}
+	}
+
+	documents := make([]models.SellerApplicationDocument, 0, len(files))
+	for _, file := range files {
+		doc, err := services.UploadSellerDocument(file)
+		if err != nil {
+			services.DiscardSellerDocuments(documents)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload document"})
+			return
+		}
+		documents = append(documents, *doc)
+	}
+
+	application, err := services.SubmitSellerApplication(c.GetString("userID"), services.SellerApplicationInput{
+		ShopName:  shopName,
+		LegalName: legalName,
+		TaxID:     taxID,
+		Documents: documents,
+	})
+	if err != nil {
+		services.DiscardSellerDocuments(documents)
+		c.JSON(sellerApplicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	services.SignSellerDocuments(application)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Seller application submitted", "application": application})
+}
+
+func GetMySellerApplications(c *gin.Context) {
+	applications, err := services.GetMySellerApplications(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch seller applications"})
+		return
+	}
+	signApplications(applications)
+	c.JSON(http.StatusOK, gin.H{"data": applications})
+}
+
+func GetShopProfile(c *gin.Context) {
+	shop, err := services.GetShopProfile(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": shop})
+}
+
+// Admin
+
+func GetSellerApplications(c *gin.Context) {
+	status := c.DefaultQuery("status", models.SellerApplicationPending)
+	if status == "all" {
+		status = ""
+	}
+
+	applications, err := services.GetSellerApplications(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch seller applications"})
+		return
+	}
+	signApplications(applications)
+	c.JSON(http.StatusOK, gin.H{"data": applications})
+}
+
+func signApplications(applications []models.SellerApplication) {
+	for i := range applications {
+		services.SignSellerDocuments(&applications[i])
+	}
+}
+
+func GetSellerApplicationByID(c *gin.Context) {
+	application, err := services.GetSellerApplicationByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	services.SignSellerDocuments(application)
+	c.JSON(http.StatusOK, gin.H{"data": application})
+}
+
+type ReviewSellerApplicationRequest struct {
+	Reason string `json:"reason"`
+}
+
+func ApproveSellerApplication(c *gin.Context) {
+	var req ReviewSellerApplicationRequest
+	_ = c.ShouldBindJSON(&req)
+
+	application, err := services.ApproveSellerApplication(c.Param("id"), c.GetString("userID"), req.Reason)
+	if err != nil {
+		c.JSON(sellerApplicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Seller application approved", "application": application})
+}
+
+func RejectSellerApplication(c *gin.Context) {
+	var req ReviewSellerApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	application, err := services.RejectSellerApplication(c.Param("id"), c.GetString("userID"), req.Reason)
+	if err != nil {
+		c.JSON(sellerApplicationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Seller application rejected", "application": application})
+}
+
+func sellerApplicationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrSellerApplicationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrSellerApplicationPending),
+		errors.Is(err, services.ErrSellerApplicationReviewed),
+		errors.Is(err, services.ErrShopNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrAlreadySeller):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrRejectionReasonRequired):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
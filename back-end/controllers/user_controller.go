@@ -4,6 +4,7 @@ import (
 	"ecommerce-backend/config"
 	"ecommerce-backend/models"
 	"ecommerce-backend/services"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -30,12 +31,13 @@ func (uc *UserController) GetProfile(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":        user.ID,
-		"name":      user.Name,
-		"email":     user.Email,
-		"role":      user.Role,
-		"createdAt": user.CreatedAt,
-		"updatedAt": user.UpdatedAt,
+		"id":            user.ID,
+		"name":          user.Name,
+		"email":         user.Email,
+		"role":          user.Role,
+		"emailVerified": user.EmailVerified,
+		"createdAt":     user.CreatedAt,
+		"updatedAt":     user.UpdatedAt,
 	})
 }
 
@@ -53,21 +55,87 @@ func (uc *UserController) UpdateProfile(c *gin.Context) {
 	}
 
 	updatedUser, err := services.UpdateUser(userID.(string), &userUpdate)
+	if errors.Is(err, services.ErrEmailTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":        updatedUser.ID,
-		"name":      updatedUser.Name,
-		"email":     updatedUser.Email,
-		"role":      updatedUser.Role,
-		"createdAt": updatedUser.CreatedAt,
-		"updatedAt": updatedUser.UpdatedAt,
+		"id":            updatedUser.ID,
+		"name":          updatedUser.Name,
+		"email":         updatedUser.Email,
+		"role":          updatedUser.Role,
+		"emailVerified": updatedUser.EmailVerified,
+		"createdAt":     updatedUser.CreatedAt,
+		"updatedAt":     updatedUser.UpdatedAt,
 	})
 }
 
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	var req struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=8"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := services.ChangePassword(c.GetString("userID"), c.GetString("sessionID"), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, services.ErrIncorrectPassword) || errors.Is(err, services.ErrPasswordUnchanged) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+func (uc *UserController) GetSessions(c *gin.Context) {
+	sessions, err := services.GetActiveSessions(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	currentSessionID := c.GetString("sessionID")
+	response := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, gin.H{
+			"id":           session.ID,
+			"user_agent":   session.UserAgent,
+			"ip_address":   session.IPAddress,
+			"created_at":   session.CreatedAt,
+			"last_seen_at": session.LastSeenAt,
+			"expires_at":   session.ExpiresAt,
+			"current":      session.ID == currentSessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (uc *UserController) RevokeSession(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	if err := services.RevokeUserSession(c.GetString("userID"), sessionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if sessionID == c.GetString("sessionID") {
+		clearAuthCookies(c)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
 // unsecure search user
 func SearchUser(c *gin.Context) {
 	name := c.Query("name")
@@ -0,0 +1,203 @@
+// Package imageproc memvalidasi dan memproses gambar upload: deteksi MIME dari
+// isi file, batas ukuran dan dimensi, membuang metadata EXIF dengan encode ulang,
+// serta membuat rendition dengan beberapa ukuran (dan WebP jika tersedia).
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+
+	"ecommerce-backend/utils"
+
+	_ "golang.org/x/image/webp" // decoder WebP untuk image.Decode
+)
+
+var (
+	ErrUnsupportedType = errors.New("file must be a JPEG, PNG or WebP image")
+	ErrTooLarge        = errors.New("image file is too large")
+	ErrDimensions      = errors.New("image dimensions are out of range")
+	ErrCorrupt         = errors.New("image could not be decoded")
+)
+
+var allowedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// Spec adalah satu rendition; gambar diperkecil sehingga sisi terpanjangnya
+// maksimal MaxSide, dan tidak pernah diperbesar.
+type Spec struct {
+	Name    string
+	MaxSide int
+}
+
+// Renditions diurutkan dari yang terbesar; rendition kecil dibuat dari yang lebih besar.
+var Renditions = []Spec{
+	{Name: "large", MaxSide: 1200},
+	{Name: "medium", MaxSide: 600},
+	{Name: "thumbnail", MaxSide: 200},
+}
+
+type Config struct {
+	MaxBytes     int64
+	MinDimension int
+	MaxDimension int
+	// MaxPixels dicek dari header sebelum decode untuk menolak "decompression bomb".
+	MaxPixels   int
+	JPEGQuality int
+	WebP        bool
+}
+
+// ConfigFromEnv membaca IMAGE_MAX_BYTES, IMAGE_MIN_DIMENSION, IMAGE_MAX_DIMENSION,
+// IMAGE_MAX_PIXELS, IMAGE_JPEG_QUALITY dan IMAGE_WEBP. WebP hanya aktif jika
+// binary cwebp tersedia.
+func ConfigFromEnv() Config {
+	return Config{
+		MaxBytes:     int64(utils.GetEnvInt("IMAGE_MAX_BYTES", 10<<20)),
+		MinDimension: utils.GetEnvInt("IMAGE_MIN_DIMENSION", 100),
+		MaxDimension: utils.GetEnvInt("IMAGE_MAX_DIMENSION", 8000),
+		MaxPixels:    utils.GetEnvInt("IMAGE_MAX_PIXELS", 40_000_000),
+		JPEGQuality:  utils.GetEnvInt("IMAGE_JPEG_QUALITY", 85),
+		WebP:         utils.GetEnvBool("IMAGE_WEBP", true) && webpAvailable(),
+	}
+}
+
+// Output adalah satu file hasil proses yang siap diunggah.
+type Output struct {
+	Rendition   string
+	Format      string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Extension mengembalikan ekstensi file sesuai format, misalnya ".jpg".
+func (o Output) Extension() string {
+	if o.Format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + o.Format
+}
+
+// Process membaca gambar dari r dan mengembalikan semua rendition. Gambar
+// transparan di-encode sebagai PNG, selain itu JPEG; metadata asli tidak ikut.
+func Process(r io.Reader, cfg Config) ([]Output, error) {
+	data, err := io.ReadAll(io.LimitReader(r, cfg.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > cfg.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return nil, ErrUnsupportedType
+	}
+
+	header, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	if header.Width < cfg.MinDimension || header.Height < cfg.MinDimension ||
+		header.Width > cfg.MaxDimension || header.Height > cfg.MaxDimension ||
+		header.Width*header.Height > cfg.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrDimensions, header.Width, header.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = exifOrientation(data)
+	}
+	opaque := isOpaque(src)
+
+	var outputs []Output
+	current := src
+	for i, spec := range Renditions {
+		current = fit(current, spec.MaxSide)
+		img := current
+		if i == 0 {
+			// Orientasi EXIF diterapkan sekali setelah gambar diperkecil karena
+			// metadata EXIF hilang saat encode ulang. Batas sisi terpanjang tidak
+			// berubah oleh rotasi, jadi urutannya aman.
+			current = orient(current, orientation)
+			img = current
+		}
+
+		out, err := encode(img, spec.Name, opaque, cfg.JPEGQuality)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, out)
+
+		if cfg.WebP {
+			webp, err := encodeWebP(img, cfg.JPEGQuality)
+			if err != nil {
+				log.Printf("WebP encoding failed for %s rendition: %v", spec.Name, err)
+				continue
+			}
+			outputs = append(outputs, Output{
+				Rendition:   spec.Name,
+				Format:      "webp",
+				ContentType: "image/webp",
+				Width:       out.Width,
+				Height:      out.Height,
+				Data:        webp,
+			})
+		}
+	}
+	return outputs, nil
+}
+
+func encode(img image.Image, rendition string, opaque bool, quality int) (Output, error) {
+	var buf bytes.Buffer
+	out := Output{
+		Rendition: rendition,
+		Width:     img.Bounds().Dx(),
+		Height:    img.Bounds().Dy(),
+	}
+	if opaque {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return out, err
+		}
+		out.Format, out.ContentType = "jpeg", "image/jpeg"
+	} else {
+		if err := png.Encode(&buf, img); err != nil {
+			return out, err
+		}
+		out.Format, out.ContentType = "png", "image/png"
+	}
+	out.Data = buf.Bytes()
+	return out, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// IsValidationError membedakan input yang ditolak (400) dari kegagalan server.
+func IsValidationError(err error) bool {
+	for _, target := range []error{ErrUnsupportedType, ErrTooLarge, ErrDimensions, ErrCorrupt} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
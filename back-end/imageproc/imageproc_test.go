@@ -0,0 +1,156 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testConfig() Config {
+	return Config{
+		MaxBytes:     1 << 20,
+		MinDimension: 10,
+		MaxDimension: 8000,
+		MaxPixels:    40_000_000,
+		JPEGQuality:  80,
+	}
+}
+
+func encodeJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader membuat PNG yang hanya berisi signature dan chunk IHDR dengan
+// dimensi w x h, tanpa data piksel.
+func pngHeader(w, h uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], w)
+	binary.BigEndian.PutUint32(ihdr[4:], h)
+	ihdr[8], ihdr[9] = 8, 6 // 8 bit, RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestProcessRejectsNonImages(t *testing.T) {
+	// Ekstensi tidak pernah dipercaya; tipe ditentukan dari isi file
+	cases := map[string][]byte{
+		"html":       []byte("<!DOCTYPE html><html><script>alert(1)</script></html>"),
+		"html lower": []byte("<html><body><img src=x onerror=alert(1)></body></html>"),
+		"svg":        []byte(`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"/>`),
+		"svg xml":    []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`),
+		"gif":        []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"),
+		"pdf":        []byte("%PDF-1.7\n"),
+		"text":       []byte("just some text"),
+	}
+	for name, data := range cases {
+		if _, err := Process(bytes.NewReader(data), testConfig()); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("%s: err = %v, want ErrUnsupportedType", name, err)
+		}
+	}
+
+	// Magic JPEG dengan isi HTML lolos sniffing tetapi gagal di-decode
+	polyglot := append([]byte{0xFF, 0xD8, 0xFF}, []byte("<html><script>alert(1)</script></html>")...)
+	if _, err := Process(bytes.NewReader(polyglot), testConfig()); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("jpeg/html polyglot: err = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestProcessRejectsDecompressionBomb(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxDimension = 100_000
+
+	// Header saja: jika batas piksel tidak dicek sebelum decode, hasilnya ErrCorrupt
+	bomb := pngHeader(50_000, 50_000)
+	if _, err := Process(bytes.NewReader(bomb), cfg); !errors.Is(err, ErrDimensions) {
+		t.Fatalf("err = %v, want ErrDimensions", err)
+	}
+
+	cfg.MaxPixels = 99
+	if _, err := Process(bytes.NewReader(encodeJPEG(t, 10, 10)), cfg); !errors.Is(err, ErrDimensions) {
+		t.Errorf("10x10 with MaxPixels 99: err = %v, want ErrDimensions", err)
+	}
+}
+
+func TestProcessLimits(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxBytes = 100
+	if _, err := Process(bytes.NewReader(encodeJPEG(t, 50, 50)), cfg); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("err = %v, want ErrTooLarge", err)
+	}
+
+	cfg = testConfig()
+	cfg.MaxDimension = 40
+	if _, err := Process(bytes.NewReader(encodeJPEG(t, 50, 20)), cfg); !errors.Is(err, ErrDimensions) {
+		t.Errorf("too wide: err = %v, want ErrDimensions", err)
+	}
+	if _, err := Process(bytes.NewReader(encodeJPEG(t, 5, 20)), testConfig()); !errors.Is(err, ErrDimensions) {
+		t.Errorf("too narrow: err = %v, want ErrDimensions", err)
+	}
+	if _, err := Process(bytes.NewReader(encodeJPEG(t, 50, 20)[:200]), testConfig()); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("truncated: err = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestProcessAppliesEXIFOrientation(t *testing.T) {
+	raw := encodeJPEG(t, 1500, 1000)
+	// Sisipkan APP1 Exif dengan Orientation 6 tepat setelah SOI
+	data := append(exifJPEG(binary.BigEndian, 6), raw[2:]...)
+
+	outputs, err := Process(bytes.NewReader(data), testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]int{"large": {800, 1200}, "medium": {400, 600}, "thumbnail": {133, 200}}
+	for _, out := range outputs {
+		size := want[out.Rendition]
+		if out.Width != size[0] || out.Height != size[1] {
+			t.Errorf("%s: %dx%d, want %dx%d", out.Rendition, out.Width, out.Height, size[0], size[1])
+		}
+		if out.Format != "jpeg" {
+			t.Errorf("%s: format %s, want jpeg", out.Rendition, out.Format)
+		}
+		// Output di-encode ulang, jadi segmen EXIF tidak ikut
+		if bytes.Contains(out.Data, []byte("Exif\x00\x00")) {
+			t.Errorf("%s still contains EXIF metadata", out.Rendition)
+		}
+	}
+}
+
+func TestProcessKeepsTransparencyAsPNG(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 128})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := Process(&buf, testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range outputs {
+		if out.Format != "png" || out.Extension() != ".png" {
+			t.Errorf("%s: format %s, want png", out.Rendition, out.Format)
+		}
+	}
+}
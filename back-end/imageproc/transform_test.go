@@ -0,0 +1,141 @@
+package imageproc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+var marker = color.NRGBA{R: 255, A: 255}
+
+// markedImage membuat gambar 3x2 dengan piksel merah di pojok kiri atas.
+func markedImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{B: uint8(40 * (x + 3*y)), A: 255})
+		}
+	}
+	img.SetNRGBA(0, 0, marker)
+	return img
+}
+
+func TestOrient(t *testing.T) {
+	// Posisi piksel kiri atas setelah transformasi menurut spesifikasi EXIF
+	tests := []struct {
+		orientation int
+		w, h        int
+		markerAt    image.Point
+	}{
+		{1, 3, 2, image.Pt(0, 0)}, // normal
+		{2, 3, 2, image.Pt(2, 0)}, // flip horizontal
+		{3, 3, 2, image.Pt(2, 1)}, // rotasi 180
+		{4, 3, 2, image.Pt(0, 1)}, // flip vertikal
+		{5, 2, 3, image.Pt(0, 0)}, // transpose
+		{6, 2, 3, image.Pt(1, 0)}, // rotasi 90 searah jarum jam
+		{7, 2, 3, image.Pt(1, 2)}, // transverse
+		{8, 2, 3, image.Pt(0, 2)}, // rotasi 90 berlawanan jarum jam
+		{0, 3, 2, image.Pt(0, 0)}, // nilai di luar 1-8 diabaikan
+		{9, 3, 2, image.Pt(0, 0)},
+	}
+	for _, tt := range tests {
+		got := orient(markedImage(), tt.orientation)
+		b := got.Bounds()
+		if b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("orientation %d: size %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+			continue
+		}
+		if c := color.NRGBAModel.Convert(got.At(tt.markerAt.X, tt.markerAt.Y)); c != marker {
+			t.Errorf("orientation %d: pixel at %v = %v, want marker", tt.orientation, tt.markerAt, c)
+		}
+	}
+}
+
+// exifJPEG membuat awal file JPEG (SOI + APP1 Exif) dengan tag Orientation.
+func exifJPEG(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8) // offset IFD0
+	order.PutUint16(tiff[8:], 1) // jumlah entry
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:], uint16(len(segment)+2))
+	return append(data, segment...)
+}
+
+func TestExifOrientation(t *testing.T) {
+	for orientation := uint16(1); orientation <= 8; orientation++ {
+		for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			if got := exifOrientation(exifJPEG(order, orientation)); got != int(orientation) {
+				t.Errorf("%v orientation %d: got %d", order, orientation, got)
+			}
+		}
+	}
+
+	// APP0 (JFIF) sebelum APP1 harus dilewati
+	jfif := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x04, 'J', 'F'}
+	withJFIF := append(jfif, exifJPEG(binary.BigEndian, 6)[2:]...)
+	if got := exifOrientation(withJFIF); got != 6 {
+		t.Errorf("after APP0: got %d, want 6", got)
+	}
+}
+
+func TestExifOrientationMalformed(t *testing.T) {
+	valid := exifJPEG(binary.LittleEndian, 6)
+	corrupt := func(f func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		f(b)
+		return b
+	}
+
+	cases := map[string][]byte{
+		"empty":                nil,
+		"png":                  []byte("\x89PNG\r\n\x1a\n"),
+		"soi only":             {0xFF, 0xD8},
+		"segment size zero":    {0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x00, 0x00},
+		"segment size too big": corrupt(func(b []byte) { b[4], b[5] = 0xFF, 0xFF }),
+		"not a marker":         corrupt(func(b []byte) { b[2] = 0x00 }),
+		"sos before exif":      {0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02},
+		"unknown byte order":   corrupt(func(b []byte) { copy(b[12:], "XX") }),
+		"ifd offset too big":   corrupt(func(b []byte) { binary.LittleEndian.PutUint32(b[16:], 0xFFFFFFFF) }),
+		"ifd offset too small": corrupt(func(b []byte) { binary.LittleEndian.PutUint32(b[16:], 2) }),
+		"too many entries": corrupt(func(b []byte) {
+			binary.LittleEndian.PutUint16(b[20:], 0xFFFF)
+			binary.LittleEndian.PutUint16(b[22:], 0x0100) // bukan Orientation, jadi pencarian melewati akhir data
+		}),
+		"orientation zero": corrupt(func(b []byte) { binary.LittleEndian.PutUint16(b[30:], 0) }),
+		"orientation nine": corrupt(func(b []byte) { binary.LittleEndian.PutUint16(b[30:], 9) }),
+	}
+	for i := 0; i < len(valid); i++ {
+		cases[fmt.Sprintf("truncated at %d", i)] = valid[:i]
+	}
+	for name, data := range cases {
+		if got := exifOrientation(data); got != 1 {
+			t.Errorf("%s: got %d, want 1", name, got)
+		}
+	}
+
+	// Byte acak setelah SOI tidak boleh membuat panic
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		data := make([]byte, 2+rng.Intn(64))
+		rng.Read(data)
+		data[0], data[1] = 0xFF, 0xD8
+		if got := exifOrientation(data); got < 1 || got > 8 {
+			t.Fatalf("random input %x: got %d", data, got)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package imageproc
+
+import (
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Go tidak punya encoder WebP di standard library maupun x/image, jadi WebP
+// dibuat dengan cwebp (paket libwebp-tools di image Docker).
+var (
+	cwebpPath string
+	cwebpOnce sync.Once
+)
+
+func webpAvailable() bool {
+	cwebpOnce.Do(func() {
+		cwebpPath, _ = exec.LookPath("cwebp")
+	})
+	return cwebpPath != ""
+}
+
+func encodeWebP(img image.Image, quality int) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "imageproc-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "in.png")
+	out := filepath.Join(dir, "out.webp")
+	f, err := os.Create(in)
+	if err != nil {
+		return nil, err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, cwebpPath, "-quiet", "-metadata", "none", "-q", strconv.Itoa(quality), in, "-o", out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, &webpError{err: err, output: string(output)}
+	}
+	return os.ReadFile(out)
+}
+
+type webpError struct {
+	err    error
+	output string
+}
+
+func (e *webpError) Error() string {
+	return "cwebp: " + e.err.Error() + ": " + e.output
+}
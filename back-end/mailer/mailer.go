@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer adalah abstraksi pengiriman email. Implementasi dipilih lewat
+// MAILER_DRIVER: "smtp" untuk produksi, "log" (default) untuk development dan test.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+var (
+	defaultMailer Mailer
+	once          sync.Once
+)
+
+// Default mengembalikan mailer global yang dibuat dari environment saat pertama dipanggil.
+func Default() Mailer {
+	once.Do(func() {
+		defaultMailer = newFromEnv()
+	})
+	return defaultMailer
+}
+
+// SetDefault mengganti mailer global, berguna untuk test atau wiring manual.
+func SetDefault(m Mailer) {
+	once.Do(func() {})
+	defaultMailer = m
+}
+
+func newFromEnv() Mailer {
+	switch strings.ToLower(os.Getenv("MAILER_DRIVER")) {
+	case "smtp":
+		return NewSMTPMailer(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+	default:
+		m, err := NewLogMailer(os.Getenv("MAILER_LOG_FILE"))
+		if err != nil {
+			log.Printf("Failed to open mailer log file, falling back to stdout: %v", err)
+			m, _ = NewLogMailer("")
+		}
+		return m
+	}
+}
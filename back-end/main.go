@@ -5,7 +5,11 @@ import (
 	"time"
 
 	"ecommerce-backend/config"
+	"ecommerce-backend/middlewares"
 	"ecommerce-backend/routes"
+	"ecommerce-backend/services"
+	"ecommerce-backend/storage"
+	"ecommerce-backend/utils"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -23,9 +27,21 @@ func main() {
 	}
 
 	log.Println("Starting E-Commerce API...")
+	if err := utils.InitJWTKeys(); err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
 	config.InitDB()
+	go services.EnsureSearchIndex()
+	go services.StartSearchMaintenance()
+	services.FailInterruptedProductImports()
 
 	r := gin.Default()
+	// Tanpa daftar ini gin mempercayai X-Forwarded-For dari siapa pun, sehingga
+	// throttle login per IP bisa dilewati dengan header palsu.
+	if err := r.SetTrustedProxies(utils.GetEnvList("TRUSTED_PROXIES")); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	r.Use(middlewares.SecurityHeaders())
 
 	// Health check endpoint for Kubernetes probes
 	r.GET("/health", func(c *gin.Context) {
@@ -71,11 +87,16 @@ func main() {
 				{"path": "/health", "method": "GET", "description": "Health check endpoint"},
 				{"path": "/auth/register", "method": "POST", "description": "User registration"},
 				{"path": "/auth/login", "method": "POST", "description": "User login"},
+				{"path": "/auth/refresh", "method": "POST", "description": "Rotate refresh token"},
+				{"path": "/auth/magic-link", "method": "POST", "description": "Email a one-time login link"},
+				{"path": "/.well-known/jwks.json", "method": "GET", "description": "Public keys for verifying access tokens"},
 				{"path": "/products", "method": "GET", "description": "Get all products"},
 				{"path": "/products/{id}", "method": "GET", "description": "Get product by ID"},
 				{"path": "/categories", "method": "GET", "description": "Get all categories"},
 				{"path": "/cart/{user_id}", "method": "GET", "description": "Get user cart"},
 				{"path": "/orders", "method": "GET", "description": "Get user orders (auth required)"},
+				{"path": "/seller-applications", "method": "POST", "description": "Apply to become a seller"},
+				{"path": "/seller/api-keys", "method": "POST", "description": "Create a scoped seller API key"},
 			},
 			"timestamp": time.Now().Unix(),		})
 	})
@@ -90,7 +111,7 @@ func main() {
 			// Removed wildcard "*" to fix credentials issue
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "Content-Length", "X-API-Key", "X-CSRF-Token"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -106,6 +127,14 @@ func main() {
 	routes.SetupSellerRoutes(r)
 	routes.SetupAdminRoutes(r)
 
+	// Driver local menyajikan file upload sendiri (development/CI tanpa Supabase)
+	if local, ok := storage.Default().(*storage.LocalStorage); ok {
+		r.GET(local.RoutePrefix+"/*key", gin.WrapH(local))
+	}
+	if local, ok := storage.Private().(*storage.LocalStorage); ok {
+		r.GET(local.RoutePrefix+"/*key", gin.WrapH(local))
+	}
+
 	log.Println("Server running on port 8080...")
 	r.Run("0.0.0.0:8080")
 }
@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"ecommerce-backend/models"
+	"ecommerce-backend/services"
 	"ecommerce-backend/utils"
 	"net/http"
 	"strings"
@@ -8,25 +10,35 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware mengautentikasi request dengan JWT (cookie atau Bearer) atau API key seller.
+//
+// API key hanya diterima jika route mendeklarasikan scope yang dibutuhkan,
+// misalnya AuthMiddleware(models.ScopeProductsWrite), dan key tersebut memiliki
+// semua scope itu. Route tanpa scope hanya bisa diakses dengan JWT.
+func AuthMiddleware(requiredScopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Coba dapatkan token dari cookie
-		tokenCookie, err := c.Cookie("token")
-		if err != nil {
-			tokenCookie = ""
-		}
-
-		// Jika tidak ada di cookie, coba dapatkan dari header
-		tokenHeader := ""
+		// API key bisa dikirim lewat X-API-Key atau Authorization: Bearer ek_...
 		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
-			tokenHeader = strings.TrimPrefix(authHeader, "Bearer ")
+		apiKey := c.GetHeader("X-API-Key")
+		if apiKey == "" && strings.HasPrefix(authHeader, "Bearer ") && services.IsAPIKey(strings.TrimPrefix(authHeader, "Bearer ")) {
+			apiKey = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+		if apiKey != "" {
+			authenticateAPIKey(c, apiKey, requiredScopes)
+			return
 		}
 
-		// Gunakan token dari cookie atau header
-		token := tokenCookie
-		if token == "" {
-			token = tokenHeader
+		// Header Authorization didahulukan karena tidak dikirim otomatis oleh browser.
+		// Token dari cookie hanya diterima bersama token CSRF untuk request yang mengubah data.
+		token := ""
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			token = strings.TrimPrefix(authHeader, "Bearer ")
+		} else if tokenCookie, err := c.Cookie("token"); err == nil && tokenCookie != "" {
+			if !isSafeMethod(c.Request.Method) && !validCSRFToken(c) {
+				abortCSRF(c)
+				return
+			}
+			token = tokenCookie
 		}
 
 		// Validasi token
@@ -43,8 +55,92 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if claims.ImpersonatorID != "" {
+			authenticateImpersonation(c, claims)
+			return
+		}
+
+		// Tolak token dari sesi yang sudah di-logout atau dicabut admin
+		if claims.SessionID == "" || !services.ValidateSession(claims.SessionID, claims.UserID) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("authMethod", "jwt")
 		c.Next()
 	}
 }
+
+func authenticateAPIKey(c *gin.Context, rawKey string, requiredScopes []string) {
+	if len(requiredScopes) == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "API keys are not accepted for this endpoint"})
+		c.Abort()
+		return
+	}
+
+	key, user, err := services.AuthenticateAPIKey(rawKey, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		c.Abort()
+		return
+	}
+
+	for _, scope := range requiredScopes {
+		if !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key is missing required scope: " + scope})
+			c.Abort()
+			return
+		}
+	}
+
+	c.Set("userID", user.ID)
+	c.Set("role", user.Role)
+	c.Set("apiKeyID", key.ID)
+	c.Set("authMethod", "api_key")
+	c.Next()
+}
+
+// impersonationWriteAllowlist berisi route (method dan path template gin) yang
+// tetap boleh dipanggil dengan method non-safe saat impersonation.
+var impersonationWriteAllowlist = map[string]bool{
+	"POST /auth/logout": true,
+}
+
+// authenticateImpersonation menerima token impersonation dan mencatat setiap
+// request ke audit log setelah handler selesai, termasuk request yang ditolak.
+// Impersonation hanya untuk melihat apa yang dilihat user, jadi request yang
+// mengubah data ditolak kecuali route-nya ada di impersonationWriteAllowlist.
+func authenticateImpersonation(c *gin.Context, claims *utils.JWTClaims) {
+	if !services.ValidateImpersonation(claims.SessionID, claims.ImpersonatorID, claims.UserID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Impersonation has ended or expired"})
+		c.Abort()
+		return
+	}
+
+	c.Set("userID", claims.UserID)
+	c.Set("role", claims.Role)
+	c.Set("sessionID", claims.SessionID)
+	c.Set("impersonatorID", claims.ImpersonatorID)
+	c.Set("authMethod", "impersonation")
+	if !isSafeMethod(c.Request.Method) && !impersonationWriteAllowlist[c.Request.Method+" "+c.FullPath()] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This action is not allowed while impersonating a user"})
+		c.Abort()
+	} else {
+		c.Next()
+	}
+
+	services.RecordImpersonatedRequest(models.ImpersonationAuditLog{
+		ImpersonationID: claims.SessionID,
+		AdminID:         claims.ImpersonatorID,
+		TargetUserID:    claims.UserID,
+		Method:          c.Request.Method,
+		Path:            c.Request.URL.RequestURI(),
+		StatusCode:      c.Writer.Status(),
+		IPAddress:       c.ClientIP(),
+		UserAgent:       c.Request.UserAgent(),
+	})
+}
@@ -0,0 +1,100 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ecommerce-backend/models"
+	"ecommerce-backend/testutil"
+	"ecommerce-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	testKeyPrefix = "abc123def456"
+	testKeySecret = "s3cr3t-value"
+	testRawKey    = "ek_" + testKeyPrefix + "_" + testKeySecret
+)
+
+// useAPIKeyDB memasang satu API key milik seller aktif dengan scope orders:read.
+func useAPIKeyDB(t *testing.T, revokedAt interface{}) {
+	testutil.UseFakeDB(t, &testutil.FakeDB{
+		Query: func(query string, args []interface{}) ([]string, [][]interface{}, error) {
+			switch {
+			case testutil.IsStatement(query, "SELECT * FROM `api_keys`"):
+				if args[0] != testKeyPrefix {
+					return []string{"id"}, nil, nil
+				}
+				return []string{"id", "user_id", "prefix", "key_hash", "scopes", "revoked_at"},
+					[][]interface{}{{"key-1", "user-1", testKeyPrefix, utils.HashToken(testKeySecret), models.ScopeOrdersRead, revokedAt}}, nil
+			case testutil.IsStatement(query, "SELECT * FROM `users`"):
+				return []string{"id", "role", "is_active"}, [][]interface{}{{"user-1", "seller", true}}, nil
+			}
+			return nil, nil, testutil.ErrUnexpectedQuery
+		},
+		Exec: func(query string, args []interface{}) (int64, error) {
+			if testutil.IsStatement(query, "UPDATE `api_keys`") {
+				return 1, nil
+			}
+			return 0, testutil.ErrUnexpectedQuery
+		},
+	})
+}
+
+func newAPIKeyRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"userID":     c.GetString("userID"),
+			"apiKeyID":   c.GetString("apiKeyID"),
+			"authMethod": c.GetString("authMethod"),
+		})
+	}
+	r.GET("/profile", AuthMiddleware(), handler)
+	r.GET("/orders", AuthMiddleware(models.ScopeOrdersRead), handler)
+	r.POST("/products", AuthMiddleware(models.ScopeProductsWrite), handler)
+	return r
+}
+
+func TestAuthMiddlewareAPIKeyScopes(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		header     string
+		value      string
+		revokedAt  interface{}
+		wantStatus int
+	}{
+		{name: "X-API-Key with scope", method: http.MethodGet, path: "/orders", header: "X-API-Key", value: testRawKey, wantStatus: http.StatusOK},
+		{name: "Bearer API key with scope", method: http.MethodGet, path: "/orders", header: "Authorization", value: "Bearer " + testRawKey, wantStatus: http.StatusOK},
+		{name: "route without scopes", method: http.MethodGet, path: "/profile", header: "X-API-Key", value: testRawKey, wantStatus: http.StatusUnauthorized},
+		{name: "missing scope", method: http.MethodPost, path: "/products", header: "X-API-Key", value: testRawKey, wantStatus: http.StatusForbidden},
+		{name: "wrong secret", method: http.MethodGet, path: "/orders", header: "X-API-Key", value: "ek_" + testKeyPrefix + "_wrong", wantStatus: http.StatusUnauthorized},
+		{name: "malformed key", method: http.MethodGet, path: "/orders", header: "X-API-Key", value: "ek_" + testKeyPrefix, wantStatus: http.StatusUnauthorized},
+		{name: "revoked key", method: http.MethodGet, path: "/orders", header: "X-API-Key", value: testRawKey, revokedAt: time.Now().Add(-time.Minute), wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useAPIKeyDB(t, tt.revokedAt)
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set(tt.header, tt.value)
+			w := httptest.NewRecorder()
+			newAPIKeyRouter().ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				want := `{"apiKeyID":"key-1","authMethod":"api_key","userID":"user-1"}`
+				if w.Body.String() != want {
+					t.Errorf("body = %s, want %s", w.Body.String(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"ecommerce-backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Proteksi CSRF memakai pola double-submit: saat login server mengirim cookie
+// csrf_token yang bisa dibaca JavaScript, dan setiap request yang mengubah data
+// dengan auth dari cookie harus mengirim nilai yang sama di header X-CSRF-Token.
+// Request dengan Authorization header atau API key tidak perlu token CSRF karena
+// browser tidak pernah mengirim header itu secara otomatis.
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func validCSRFToken(c *gin.Context) bool {
+	cookie, err := c.Cookie(utils.CSRFCookieName)
+	if err != nil || cookie == "" {
+		return false
+	}
+	header := c.GetHeader(utils.CSRFHeaderName)
+	return header != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+func abortCSRF(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or missing CSRF token"})
+	c.Abort()
+}
+
+// RequireCSRFForCookie dipakai pada route yang tidak melewati AuthMiddleware tetapi
+// tetap membaca cookie, misalnya /auth/refresh dan /auth/logout dengan refresh_token.
+// Request yang membawa Authorization header dilewati karena header itu hanya bisa
+// dikirim lintas origin setelah lolos preflight CORS.
+func RequireCSRFForCookie(cookieName string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, err := c.Cookie(cookieName)
+		if err == nil && value != "" && !isSafeMethod(c.Request.Method) && c.GetHeader("Authorization") == "" {
+			if !validCSRFToken(c) {
+				abortCSRF(c)
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DenyImpersonation memblokir aksi yang tidak boleh dilakukan admin atas nama user.
+// Request yang mengubah data sudah ditolak oleh AuthMiddleware, jadi middleware ini
+// hanya perlu dipasang di route GET yang sensitif, misalnya ekspor data.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("impersonatorID") != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This action is not allowed while impersonating a user"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"ecommerce-backend/testutil"
+	"ecommerce-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("JWT_SECRET", "middleware-test-secret")
+	os.Exit(m.Run())
+}
+
+// useImpersonationDB memasang sesi impersonation aktif admin-1 atas user-1 dan
+// mengembalikan status code yang tercatat di audit log.
+func useImpersonationDB(t *testing.T) *[]int64 {
+	var audited []int64
+	testutil.UseFakeDB(t, &testutil.FakeDB{
+		Query: func(query string, args []interface{}) ([]string, [][]interface{}, error) {
+			switch {
+			case testutil.IsStatement(query, "SELECT * FROM `impersonation_sessions`"):
+				return []string{"id", "admin_id", "target_user_id", "expires_at", "ended_at"},
+					[][]interface{}{{"imp-1", "admin-1", "user-1", time.Now().Add(time.Hour), nil}}, nil
+			case testutil.IsStatement(query, "SELECT * FROM `users`"):
+				return []string{"id", "role", "is_active"}, [][]interface{}{{"admin-1", "admin", true}}, nil
+			}
+			return nil, nil, testutil.ErrUnexpectedQuery
+		},
+		Exec: func(query string, args []interface{}) (int64, error) {
+			if testutil.IsStatement(query, "INSERT INTO `impersonation_audit_logs`") {
+				for _, arg := range args {
+					if code, ok := arg.(int64); ok && code >= 100 {
+						audited = append(audited, code)
+					}
+				}
+				return 1, nil
+			}
+			return 0, testutil.ErrUnexpectedQuery
+		},
+	})
+	return &audited
+}
+
+func TestImpersonationRejectsWritesByDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/profile", AuthMiddleware(), ok)
+	r.PUT("/profile", AuthMiddleware(), ok)
+	r.DELETE("/cart/:id", AuthMiddleware(), ok)
+	r.POST("/auth/logout", AuthMiddleware(), ok)
+	r.GET("/seller/products/export", AuthMiddleware(), DenyImpersonation(), ok)
+
+	token, err := utils.GenerateImpersonationToken("user-1", "seller", "imp-1", "admin-1", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{http.MethodGet, "/profile", http.StatusOK},
+		{http.MethodPut, "/profile", http.StatusForbidden},
+		{http.MethodDelete, "/cart/1", http.StatusForbidden},
+		{http.MethodPost, "/auth/logout", http.StatusOK},
+		{http.MethodGet, "/seller/products/export", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			audited := useImpersonationDB(t)
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			// Request yang ditolak tetap tercatat di audit log
+			if len(*audited) != 1 || (*audited)[0] != int64(tt.wantStatus) {
+				t.Errorf("audited status codes = %v, want [%d]", *audited, tt.wantStatus)
+			}
+		})
+	}
+}
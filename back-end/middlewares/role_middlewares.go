@@ -1,20 +1,24 @@
 package middlewares
 
 import (
+	"ecommerce-backend/policy"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
+// RequirePermission memastikan role user memiliki semua permission yang dideklarasikan route.
+// Kepemilikan resource (order milik siapa, produk milik seller mana) diperiksa di controller
+// dengan policy.Authorize setelah resource-nya dimuat.
+func RequirePermission(permissions ...policy.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		roleInterface, exists := c.Get("role")
-
 		if !exists {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
+
 		role, ok := roleInterface.(string)
 		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid role format"})
@@ -22,14 +26,13 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 			return
 		}
 
-		for _, allowedRole := range allowedRoles {
-			if role == allowedRole {
-				c.Next()
+		for _, permission := range permissions {
+			if !policy.Can(role, permission) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Access Denied"})
+				c.Abort()
 				return
 			}
 		}
-
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: Access Denied"})
-		c.Abort()
+		c.Next()
 	}
 }
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"ecommerce-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHeaders menambahkan header keamanan standar. API ini hanya mengembalikan
+// JSON, jadi CSP dibuat seketat mungkin. HSTS hanya dikirim jika cookie Secure
+// aktif (artinya aplikasi berjalan di belakang HTTPS), atau lewat HSTS_ENABLED.
+func SecurityHeaders() gin.HandlerFunc {
+	hsts := utils.GetEnvBool("HSTS_ENABLED", utils.GetCookieConfig().Secure)
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		h.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+		h.Set("Cross-Origin-Opener-Policy", "same-origin")
+		h.Set("Permissions-Policy", "camera=(), microphone=(), geolocation=()")
+		if hsts {
+			h.Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scope yang bisa diberikan ke API key seller.
+const (
+	ScopeProductsWrite = "products:write"
+	ScopeOrdersRead    = "orders:read"
+	ScopeOrdersWrite   = "orders:write"
+)
+
+var ValidAPIKeyScopes = []string{ScopeProductsWrite, ScopeOrdersRead, ScopeOrdersWrite}
+
+// APIKey dipakai seller untuk akses terprogram (misalnya sinkronisasi dari ERP).
+// Key aslinya hanya ditampilkan sekali; yang disimpan hanya Prefix untuk lookup dan hash-nya.
+type APIKey struct {
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;uniqueIndex;not null" json:"prefix"`
+	KeyHash    string     `gorm:"size:64;not null" json:"-"`
+	Scopes     string     `gorm:"size:255;not null" json:"-"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `gorm:"size:64" json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	ScopeList []string `gorm:"-" json:"scopes"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	k.ID = uuid.NewString()
+	return
+}
+
+func (k *APIKey) AfterFind(tx *gorm.DB) (err error) {
+	k.ScopeList = strings.Fields(k.Scopes)
+	return
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range strings.Fields(k.Scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
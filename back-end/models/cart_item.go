@@ -11,12 +11,14 @@ type CartItem struct {
 	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
 	UserID    string    `gorm:"type:uuid;not null" json:"user_id"`
 	ProductID string    `gorm:"type:uuid;not null" json:"product_id"`
+	VariantID *string   `gorm:"type:uuid;index" json:"variant_id"`
 	Quantity  int       `gorm:"not null" json:"quantity"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
-	User    User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
-	Product Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE;" json:"product"`
+	User    User            `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+	Product Product         `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE;" json:"product"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE;" json:"variant,omitempty"`
 }
 
 func (c *CartItem) BeforeCreate(tx *gorm.DB) (err error) {
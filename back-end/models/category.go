@@ -4,5 +4,6 @@ type Category struct {
 	ID   string `gorm:"type:uuid;primaryKey" json:"id"`
 	Name string `gorm:"size:255;unique;not null" json:"name"`
 
-	Products []Product `gorm:"foreignKey:CategoryID; references:ID"`
+	Products   []Product           `gorm:"foreignKey:CategoryID; references:ID"`
+	Attributes []CategoryAttribute `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE;" json:"attributes,omitempty"`
 }
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImpersonationSession mencatat admin yang sedang bertindak sebagai user lain.
+type ImpersonationSession struct {
+	ID           string     `gorm:"type:uuid;primaryKey" json:"id"`
+	AdminID      string     `gorm:"type:uuid;not null;index" json:"admin_id"`
+	TargetUserID string     `gorm:"type:uuid;not null;index" json:"target_user_id"`
+	Reason       string     `gorm:"type:text;not null" json:"reason"`
+	IPAddress    string     `gorm:"size:45" json:"ip_address"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	EndedAt      *time.Time `json:"ended_at"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	Admin      *User `gorm:"foreignKey:AdminID" json:"admin,omitempty"`
+	TargetUser *User `gorm:"foreignKey:TargetUserID" json:"target_user,omitempty"`
+}
+
+func (s *ImpersonationSession) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.NewString()
+	return
+}
+
+// ImpersonationAuditLog berisi satu baris untuk setiap request yang dibuat dengan token impersonation.
+type ImpersonationAuditLog struct {
+	ID              string    `gorm:"type:uuid;primaryKey" json:"id"`
+	ImpersonationID string    `gorm:"type:uuid;not null;index" json:"impersonation_id"`
+	AdminID         string    `gorm:"type:uuid;not null;index" json:"admin_id"`
+	TargetUserID    string    `gorm:"type:uuid;not null" json:"target_user_id"`
+	Method          string    `gorm:"size:10" json:"method"`
+	Path            string    `gorm:"size:255" json:"path"`
+	StatusCode      int       `json:"status_code"`
+	IPAddress       string    `gorm:"size:45" json:"ip_address"`
+	UserAgent       string    `gorm:"size:255" json:"user_agent"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (l *ImpersonationAuditLog) BeforeCreate(tx *gorm.DB) (err error) {
+	l.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginThrottle menghitung percobaan login gagal per key, yaitu
+// "user:<id>" untuk akun atau "ip:<alamat>" untuk client IP.
+type LoginThrottle struct {
+	ThrottleKey    string     `gorm:"size:100;primaryKey" json:"throttle_key"`
+	FailedAttempts int        `gorm:"not null;default:0" json:"failed_attempts"`
+	LockedUntil    *time.Time `json:"locked_until"`
+	LastFailedAt   time.Time  `json:"last_failed_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type SecurityEvent struct {
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
+	Type      string    `gorm:"size:50;not null;index" json:"type"`
+	UserID    string    `gorm:"size:36;index" json:"user_id"`
+	IPAddress string    `gorm:"size:64" json:"ip_address"`
+	UserAgent string    `gorm:"size:255" json:"user_agent"`
+	Detail    string    `gorm:"type:text" json:"detail"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+const (
+	SecurityEventLoginFailed        = "login_failed"
+	SecurityEventAccountLocked      = "account_locked"
+	SecurityEventIPLocked           = "ip_locked"
+	SecurityEventAccountUnlocked    = "account_unlocked"
+	SecurityEventSuspiciousLogin    = "suspicious_login"
+	SecurityEventMFAFailed          = "mfa_failed"
+	SecurityEventRefreshTokenReused = "refresh_token_reused"
+	SecurityEventImpersonationStart = "impersonation_started"
+	SecurityEventImpersonationEnd   = "impersonation_ended"
+)
+
+func (e *SecurityEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MFARecoveryCode hanya menyimpan hash; kode aslinya ditampilkan sekali saat dibuat.
+type MFARecoveryCode struct {
+	ID        string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null;index" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+func (r *MFARecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	r.ID = uuid.NewString()
+	return
+}
+
+// RoleAuthPolicy menyimpan kebijakan autentikasi per role yang diatur admin.
+// Role tanpa baris policy memakai nilai default kolom.
+type RoleAuthPolicy struct {
+	Role             string    `gorm:"type:varchar(20);primaryKey" json:"role"`
+	MFARequired      bool      `gorm:"default:false" json:"mfa_required"`
+	MagicLinkEnabled bool      `gorm:"default:true" json:"magic_link_enabled"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
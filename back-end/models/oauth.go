@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity menghubungkan akun lokal dengan akun di provider login sosial.
+type UserIdentity struct {
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    string    `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider  string    `gorm:"size:50;not null;uniqueIndex:idx_identity_provider_subject" json:"provider"`
+	Subject   string    `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject" json:"subject"`
+	Email     string    `gorm:"size:255" json:"email"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID = uuid.NewString()
+	return
+}
+
+// OAuthState menyimpan state, nonce dan PKCE verifier selama user berada di halaman provider.
+// LinkUserID terisi jika flow dimulai untuk menautkan provider ke akun yang sedang login;
+// LinkSessionID adalah sesi yang memulainya, yang tetap berlaku setelah penautan.
+type OAuthState struct {
+	StateHash     string    `gorm:"size:64;primaryKey" json:"-"`
+	Provider      string    `gorm:"size:50;not null" json:"provider"`
+	CodeVerifier  string    `gorm:"size:128;not null" json:"-"`
+	Nonce         string    `gorm:"size:128;not null" json:"-"`
+	LinkUserID    *string   `gorm:"size:36" json:"-"`
+	LinkSessionID string    `gorm:"size:36" json:"-"`
+	ExpiresAt     time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// OAuthLoginCode adalah code sekali pakai berumur pendek yang dibawa redirect
+// callback ke front-end, lalu ditukar dengan sesi lewat POST /auth/oauth/exchange.
+type OAuthLoginCode struct {
+	CodeHash  string    `gorm:"size:64;primaryKey" json:"-"`
+	UserID    string    `gorm:"type:uuid;not null;index" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+}
@@ -4,11 +4,25 @@ type OrderItem struct {
 	ID        string  `gorm:"type:uuid;primaryKey" json:"id"`
 	OrderID   string  `gorm:"type:uuid;not null" json:"order_id"`
 	ProductID string  `gorm:"type:uuid;not null" json:"product_id"`
+	VariantID *string `gorm:"type:uuid;index" json:"variant_id"`
 	Quantity  int     `gorm:"not null" json:"quantity"`
-	Price     float64 `gorm:"not null" json:"price"`
-	Status    string  `gorm:"type:enum('pending','paid','processing','shipped','delivered','cancelled');default:'pending'" json:"status"`
-	Order     Order   `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE;" json:"order"`
-	Product   Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	// Price adalah total baris (UnitPrice x Quantity).
+	Price  float64 `gorm:"not null" json:"price"`
+	Status string  `gorm:"type:enum('pending','paid','processing','shipped','delivered','cancelled');default:'pending'" json:"status"`
+
+	// Snapshot produk saat checkout. Riwayat order, tampilan seller dan invoice
+	// memakai kolom ini, bukan data produk terkini yang bisa berubah atau diarsipkan.
+	ProductName    string            `gorm:"size:255;not null;default:''" json:"product_name"`
+	SKU            string            `gorm:"size:64" json:"sku"`
+	VariantOptions map[string]string `gorm:"type:text;serializer:json" json:"variant_options,omitempty"`
+	UnitPrice      float64           `gorm:"not null;default:0" json:"unit_price"`
+	ImageURL       string            `gorm:"type:text;index:idx_order_items_image_url,length:255" json:"image_url"` // dicek sebelum file gambar produk dihapus
+	SellerID       *string           `gorm:"type:uuid;index" json:"seller_id"`
+	SellerName     string            `gorm:"size:255" json:"seller_name"`
+
+	Order   Order           `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE;" json:"order"`
+	Product *Product        `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID" json:"variant,omitempty"`
 }
 
 const (
@@ -25,4 +25,5 @@ const (
 	PaymentStatusFailed  = "failed"
 	PaymentStatusExpired = "expired"
 	PaymentStatusCancel  = "cancel"
+	PaymentStatusRefund  = "refund"
 )
@@ -7,29 +7,74 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	ProductStatusDraft         = "draft"
+	ProductStatusPendingReview = "pending_review"
+	ProductStatusPublished     = "published"
+	ProductStatusArchived      = "archived"
+)
+
+// ProductStatuses berisi semua status produk yang valid.
+var ProductStatuses = []string{ProductStatusDraft, ProductStatusPendingReview, ProductStatusPublished, ProductStatusArchived}
+
+// Index idx_products_status_* berbentuk (status, kolom urutan, id) supaya listing
+// publik dan keyset pagination per sort bisa dilayani satu range scan.
 type Product struct {
-	ID          string  `gorm:"type:uuid;primaryKey" json:"id"`
+	ID          string  `gorm:"type:uuid;primaryKey;index:idx_products_status_created,priority:3;index:idx_products_status_price,priority:3;index:idx_products_status_rating,priority:3;index:idx_products_status_sold,priority:3" json:"id"`
 	Name        string  `gorm:"size:255;not null" json:"name"`
 	Description string  `gorm:"type:text" json:"description"`
-	Price       float64 `gorm:"not null" json:"price"`
+	Price       float64 `gorm:"not null;index;index:idx_products_category_price,priority:2;index:idx_products_status_price,priority:2" json:"price"`
 	Stock       int     `gorm:"not null" json:"stock"`
 	ImageURL    string  `gorm:"type:text" json:"image_url"`
+	// Rendition gambar primary untuk srcset di listing; disinkronkan dari galeri.
+	ImageRenditions ImageRenditions `gorm:"type:text;serializer:json" json:"image_renditions,omitempty"`
 
-	SellerID   string    `gorm:"type:uuid;not null" json:"seller_id"`
-	CategoryID string    `gorm:"type:uuid;not null" json:"category_id"`
-	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	SellerID   string    `gorm:"type:uuid;not null;index;uniqueIndex:idx_products_seller_sku,priority:1" json:"seller_id"`
+	CategoryID string    `gorm:"type:uuid;not null;index:idx_products_category_price,priority:1" json:"category_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index;index:idx_products_status_created,priority:2" json:"created_at"`
 	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
+	// SKU milik seller, unik per seller; dipakai untuk upsert saat import katalog.
+	SKU *string `gorm:"size:64;uniqueIndex:idx_products_seller_sku,priority:2" json:"sku"`
+
+	// Hanya produk published yang tampil di listing publik dan bisa dibeli. Produk
+	// yang "dihapus" diarsipkan supaya riwayat order dan review tetap utuh.
+	Status      string     `gorm:"type:enum('draft','pending_review','published','archived');not null;default:'published';index;index:idx_products_status_created,priority:1;index:idx_products_status_price,priority:1;index:idx_products_status_rating,priority:1;index:idx_products_status_sold,priority:1" json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+
+	// Didenormalisasi supaya listing bisa difilter dan diurutkan tanpa join ke reviews/order_items.
+	// Rating dan ReviewCount diperbarui saat review dibuat/dihapus, SoldCount saat pembayaran sukses
+	// dan dikurangi lagi saat item dibatalkan atau pembayaran di-refund.
+	Rating      float64 `gorm:"not null;default:0;index;index:idx_products_status_rating,priority:2" json:"rating"`
+	ReviewCount int     `gorm:"not null;default:0" json:"review_count"`
+	SoldCount   int     `gorm:"not null;default:0;index;index:idx_products_status_sold,priority:2" json:"sold_count"`
+
 	SellerName   string `gorm:"-" json:"seller_name"`
 	CategoryName string `gorm:"-" json:"category_name"`
 
 	Seller   *User     `gorm:"foreignKey:SellerID;references:ID" json:"seller"`
 	Category *Category `gorm:"foreignKey:CategoryID;references:ID" json:"category"`
-	Rating   float64   `gorm:"-" json:"rating"`
 	Reviews  []Review  `gorm:"foreignKey:ProductID" json:"-"`
+
+	// Untuk produk bervarian, Price adalah harga varian termurah dan Stock adalah
+	// total stok semua varian aktif; keduanya disinkronkan saat varian berubah.
+	Options  []ProductOption  `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE;" json:"options,omitempty"`
+	Variants []ProductVariant `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE;" json:"variants,omitempty"`
+	Images   []ProductImage   `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE;" json:"images,omitempty"`
+
+	// Nilai atribut terstruktur sesuai skema kategori (CategoryAttribute).
+	Attributes []ProductAttributeValue `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE;" json:"attributes,omitempty"`
 }
 
 func (p *Product) BeforeCreate(tx *gorm.DB) (err error) {
 	p.ID = uuid.NewString()
+	if p.Status == "" {
+		p.Status = ProductStatusPublished
+	}
+	if p.Status == ProductStatusPublished && p.PublishedAt == nil {
+		now := time.Now()
+		p.PublishedAt = &now
+	}
 	return
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	AttributeTypeText   = "text"
+	AttributeTypeNumber = "number"
+	AttributeTypeEnum   = "enum"
+)
+
+// CategoryAttribute adalah skema atribut terstruktur untuk produk di sebuah
+// kategori, misalnya brand (enum), bahan (text) atau berat (number, unit "kg").
+// Key dipakai di filter (?attr.<key>=...) dan boleh sama di beberapa kategori.
+type CategoryAttribute struct {
+	ID         string `gorm:"type:uuid;primaryKey" json:"id"`
+	CategoryID string `gorm:"type:uuid;not null;uniqueIndex:idx_category_attribute_key,priority:1" json:"category_id"`
+	// Kolom attr_key karena "key" adalah kata kunci di MySQL.
+	Key        string   `gorm:"column:attr_key;size:64;not null;uniqueIndex:idx_category_attribute_key,priority:2;index" json:"key"`
+	Name       string   `gorm:"size:100;not null" json:"name"`
+	Type       string   `gorm:"type:enum('text','number','enum');not null" json:"type"`
+	Options    []string `gorm:"type:text;serializer:json" json:"options,omitempty"`
+	Unit       string   `gorm:"size:20" json:"unit,omitempty"`
+	Required   bool     `gorm:"not null;default:false" json:"required"`
+	Filterable bool     `gorm:"not null;default:true" json:"filterable"`
+	Position   int      `gorm:"not null;default:0" json:"position"`
+}
+
+func (a *CategoryAttribute) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.NewString()
+	return
+}
+
+// ProductAttributeValue menyimpan nilai satu atribut untuk satu produk. Nilai
+// number disimpan di NumberValue supaya bisa difilter dengan rentang; nilai
+// lain (dan bentuk teks dari number) di TextValue untuk facet.
+type ProductAttributeValue struct {
+	ID          string   `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID   string   `gorm:"type:uuid;not null;uniqueIndex:idx_product_attribute,priority:1" json:"product_id"`
+	AttributeID string   `gorm:"type:uuid;not null;uniqueIndex:idx_product_attribute,priority:2;index:idx_attribute_text_value,priority:1;index:idx_attribute_number_value,priority:1" json:"attribute_id"`
+	TextValue   string   `gorm:"size:255;not null;default:'';index:idx_attribute_text_value,priority:2" json:"value"`
+	NumberValue *float64 `gorm:"index:idx_attribute_number_value,priority:2" json:"number_value,omitempty"`
+
+	Attribute *CategoryAttribute `gorm:"foreignKey:AttributeID;constraint:OnDelete:CASCADE;" json:"attribute,omitempty"`
+}
+
+func (v *ProductAttributeValue) BeforeCreate(tx *gorm.DB) (err error) {
+	v.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductImage adalah satu gambar di galeri produk. Product.ImageURL selalu
+// berisi URL gambar primary supaya listing tidak perlu memuat galeri.
+type ProductImage struct {
+	ID        string    `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID string    `gorm:"type:uuid;not null;index" json:"product_id"`
+	URL       string    `gorm:"type:text;not null" json:"url"`
+	AltText   string    `gorm:"size:255" json:"alt_text"`
+	Position  int       `gorm:"not null;default:0" json:"position"`
+	IsPrimary bool      `gorm:"not null;default:false" json:"is_primary"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Renditions berisi versi thumbnail/medium/large; kosong untuk gambar lama
+	// yang diunggah sebelum ada pipeline pemrosesan.
+	Renditions ImageRenditions `gorm:"type:text;serializer:json" json:"renditions,omitempty"`
+}
+
+// ImageRendition adalah satu ukuran gambar beserta versi WebP-nya (jika ada).
+type ImageRendition struct {
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	URL     string `json:"url"`
+	WebPURL string `json:"webp_url,omitempty"`
+}
+
+// ImageRenditions dikunci dengan nama rendition: "thumbnail", "medium", "large".
+type ImageRenditions map[string]ImageRendition
+
+// URLs mengembalikan semua file rendition, untuk dibersihkan dari storage.
+func (r ImageRenditions) URLs() []string {
+	var urls []string
+	for _, rendition := range r {
+		urls = append(urls, rendition.URL)
+		if rendition.WebPURL != "" {
+			urls = append(urls, rendition.WebPURL)
+		}
+	}
+	return urls
+}
+
+// StorageURLs mengembalikan URL gambar beserta semua rendition-nya tanpa duplikat.
+func (i ProductImage) StorageURLs() []string {
+	urls := []string{i.URL}
+	for _, u := range i.Renditions.URLs() {
+		if u != i.URL {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+func (i *ProductImage) BeforeCreate(tx *gorm.DB) (err error) {
+	i.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ProductImportPending   = "pending"
+	ProductImportRunning   = "running"
+	ProductImportCompleted = "completed"
+	ProductImportFailed    = "failed"
+)
+
+// ProductImportRowError menjelaskan kenapa satu baris file import ditolak.
+// Row adalah nomor baris di file, sama seperti yang terlihat di spreadsheet.
+type ProductImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ProductImportJob mencatat progres import katalog seller yang berjalan di background.
+type ProductImportJob struct {
+	ID       string `gorm:"type:uuid;primaryKey" json:"id"`
+	SellerID string `gorm:"type:uuid;not null;index" json:"seller_id"`
+	FileName string `gorm:"size:255" json:"file_name"`
+	Format   string `gorm:"size:10" json:"format"`
+	// DryRun hanya memvalidasi baris tanpa menyimpan perubahan.
+	DryRun bool   `gorm:"not null;default:false" json:"dry_run"`
+	Status string `gorm:"type:enum('pending','running','completed','failed');default:'pending';index" json:"status"`
+
+	TotalRows     int `gorm:"not null;default:0" json:"total_rows"`
+	ProcessedRows int `gorm:"not null;default:0" json:"processed_rows"`
+	CreatedCount  int `gorm:"not null;default:0" json:"created_count"`
+	UpdatedCount  int `gorm:"not null;default:0" json:"updated_count"`
+	FailedCount   int `gorm:"not null;default:0" json:"failed_count"`
+
+	// Errors dibatasi jumlahnya; FailedCount tetap menghitung semua baris gagal.
+	Errors []ProductImportRowError `gorm:"type:longtext;serializer:json" json:"errors"`
+	Error  string                  `gorm:"type:text" json:"error,omitempty"`
+
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+func (j *ProductImportJob) BeforeCreate(tx *gorm.DB) (err error) {
+	j.ID = uuid.NewString()
+	return
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductOption adalah satu sumbu varian produk, misalnya "Ukuran" dengan nilai S, M, L.
+type ProductOption struct {
+	ID        string   `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID string   `gorm:"type:uuid;not null;uniqueIndex:idx_product_option_name" json:"product_id"`
+	Name      string   `gorm:"size:50;not null;uniqueIndex:idx_product_option_name" json:"name"`
+	Values    []string `gorm:"type:text;serializer:json" json:"values"`
+	Position  int      `gorm:"not null;default:0" json:"position"`
+}
+
+func (o *ProductOption) BeforeCreate(tx *gorm.DB) (err error) {
+	o.ID = uuid.NewString()
+	return
+}
+
+// ProductVariant adalah satu SKU dengan harga, stok dan gambarnya sendiri.
+// Options memetakan nama sumbu ke nilainya, misalnya {"Ukuran": "M", "Warna": "Merah"}.
+//
+// Varian yang sudah pernah dipesan tidak dihapus saat seller mengubah daftar
+// varian, melainkan dinonaktifkan supaya order item lama tetap merujuk ke SKU-nya.
+//
+// Seperti SKU produk, SKU varian unik per seller; SellerID disalin dari produk
+// supaya keunikan itu bisa dijaga oleh index.
+type ProductVariant struct {
+	ID        string            `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID string            `gorm:"type:uuid;not null;index" json:"product_id"`
+	SellerID  string            `gorm:"type:uuid;not null;uniqueIndex:idx_product_variants_seller_sku,priority:1" json:"-"`
+	SKU       string            `gorm:"size:64;not null;uniqueIndex:idx_product_variants_seller_sku,priority:2" json:"sku"`
+	Options   map[string]string `gorm:"type:text;serializer:json" json:"options"`
+	Price     float64           `gorm:"not null" json:"price"`
+	Stock     int               `gorm:"not null" json:"stock"`
+	ImageURL  string            `gorm:"type:text" json:"image_url"`
+	IsActive  bool              `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (v *ProductVariant) BeforeCreate(tx *gorm.DB) (err error) {
+	v.ID = uuid.NewString()
+	return
+}
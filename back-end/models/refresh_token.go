@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken disimpan dalam bentuk hash. Semua token hasil rotasi dari satu
+// login berbagi FamilyID yang sama (yaitu Session.ID), sehingga satu family bisa dicabut sekaligus.
+type RefreshToken struct {
+	ID           string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID       string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	FamilyID     string     `gorm:"type:uuid;not null;index" json:"family_id"`
+	TokenHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID string     `gorm:"size:36" json:"replaced_by_id"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+func (r *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// SearchTerm adalah satu baris inverted index: frekuensi sebuah term di satu
+// field produk. FieldLength disimpan untuk normalisasi panjang pada BM25.
+type SearchTerm struct {
+	Term        string `gorm:"size:64;primaryKey"`
+	DocumentID  string `gorm:"size:36;primaryKey;index"`
+	Field       string `gorm:"size:16;primaryKey"`
+	Frequency   int    `gorm:"not null"`
+	FieldLength int    `gorm:"not null"`
+}
+
+// SearchWord adalah kosakata kata asli (belum di-stem) untuk autocomplete dan
+// koreksi ejaan. Kata tidak dihapus saat produknya dihapus, tetapi kata yang tidak
+// terlihat lagi (LastSeenAt) selama reindex penuh dibuang. Length dipakai untuk
+// menyaring kandidat koreksi ejaan tanpa membaca seluruh kosakata.
+type SearchWord struct {
+	Word       string    `gorm:"size:64;primaryKey;index:idx_search_words_length,priority:2"`
+	Length     int       `gorm:"not null;default:0;index:idx_search_words_length,priority:1"`
+	LastSeenAt time.Time `gorm:"index"`
+}
+
+type SearchDocument struct {
+	DocumentID        string    `gorm:"size:36;primaryKey"`
+	NameLength        int       `gorm:"not null"`
+	DescriptionLength int       `gorm:"not null"`
+	IndexedAt         time.Time `gorm:"autoUpdateTime"`
+}
+
+// SearchQueryStat mencatat query pencarian yang sudah dinormalisasi untuk saran
+// "pencarian populer". Hanya query yang pernah memberi hasil dan dicari oleh
+// cukup banyak pencari berbeda (SearcherCount) yang disarankan.
+type SearchQueryStat struct {
+	Query          string    `gorm:"size:100;primaryKey"`
+	SearchCount    int       `gorm:"not null;default:0;index"`
+	SearcherCount  int       `gorm:"not null;default:0"`
+	ResultCount    int64     `gorm:"not null;default:0"`
+	LastSearchedAt time.Time `gorm:"not null;index"`
+
+	Searchers []SearchQuerySearcher `gorm:"foreignKey:Query;references:Query;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// SearchQuerySearcher menyimpan hash pencari (IP) sebuah query sampai jumlahnya
+// mencapai batas query populer, sehingga tabel ini tidak tumbuh tanpa batas.
+type SearchQuerySearcher struct {
+	Query       string    `gorm:"size:100;primaryKey"`
+	SearcherKey string    `gorm:"size:64;primaryKey"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	SellerApplicationPending  = "pending"
+	SellerApplicationApproved = "approved"
+	SellerApplicationRejected = "rejected"
+)
+
+// SellerApplication diajukan buyer yang ingin berjualan dan direview oleh admin.
+type SellerApplication struct {
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	ShopName   string     `gorm:"size:100;not null" json:"shop_name"`
+	LegalName  string     `gorm:"size:255;not null" json:"legal_name"`
+	TaxID      string     `gorm:"size:50;not null" json:"tax_id"`
+	Status     string     `gorm:"type:enum('pending','approved','rejected');default:'pending';index" json:"status"`
+	ReviewNote string     `gorm:"type:text" json:"review_note"`
+	ReviewedBy string     `gorm:"size:36" json:"reviewed_by"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	User      *User                       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Documents []SellerApplicationDocument `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE;" json:"documents"`
+}
+
+func (a *SellerApplication) BeforeCreate(tx *gorm.DB) (err error) {
+	a.ID = uuid.NewString()
+	return
+}
+
+// SellerApplicationDocument menyimpan key objek di storage privat (StorageKey).
+// FileURL hanya terisi untuk dokumen lama yang dulu diunggah ke bucket publik.
+type SellerApplicationDocument struct {
+	ID            string    `gorm:"type:uuid;primaryKey" json:"id"`
+	ApplicationID string    `gorm:"type:uuid;not null;index" json:"application_id"`
+	FileName      string    `gorm:"size:255" json:"file_name"`
+	StorageKey    string    `gorm:"size:255" json:"-"`
+	FileURL       string    `gorm:"type:text" json:"-"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// DownloadURL adalah signed URL berumur pendek, diisi saat dokumen ditampilkan.
+	DownloadURL string `gorm:"-" json:"download_url,omitempty"`
+}
+
+func (d *SellerApplicationDocument) BeforeCreate(tx *gorm.DB) (err error) {
+	d.ID = uuid.NewString()
+	return
+}
+
+// ShopProfile dibuat saat aplikasi seller disetujui.
+type ShopProfile struct {
+	ID          string    `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      string    `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	ShopName    string    `gorm:"size:100;not null;uniqueIndex" json:"shop_name"`
+	LegalName   string    `gorm:"size:255;not null" json:"legal_name"`
+	TaxID       string    `gorm:"size:50;not null" json:"tax_id"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (s *ShopProfile) BeforeCreate(tx *gorm.DB) (err error) {
+	s.ID = uuid.NewString()
+	return
+}
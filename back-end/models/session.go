@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Session mewakili satu login di satu perangkat. ID-nya dipakai sebagai
+// FamilyID untuk semua refresh token hasil rotasi dan sebagai claim "sid" di access token.
+type Session struct {
+	ID         string     `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	UserAgent  string     `gorm:"size:255" json:"user_agent"`
+	IPAddress  string     `gorm:"size:64" json:"ip_address"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+}
@@ -17,6 +17,14 @@ type User struct {
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
+	EmailVerified   bool       `gorm:"default:false" json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	MFAEnabled       bool   `gorm:"default:false" json:"mfa_enabled"`
+	MFASecret        string `gorm:"size:64" json:"-"`
+	MFAPendingSecret string `gorm:"size:64" json:"-"`
+	MFALastUsedStep  int64  `gorm:"default:0" json:"-"`
+
 	Products []Product `gorm:"foreignKey:SellerID;references:ID" json:"-"`
 	Orders   []Order   `gorm:"foreignKey:UserID;references:ID" json:"-"`
 	Reviews  []Review  `gorm:"foreignKey:UserID;references:ID" json:"-"`
@@ -0,0 +1,154 @@
+// Package policy adalah satu-satunya tempat aturan otorisasi: permission apa
+// yang dimiliki setiap role, dan apakah permission itu berlaku untuk semua
+// resource atau hanya resource milik user sendiri.
+package policy
+
+import "errors"
+
+type Permission string
+
+const (
+	ProductsCreate Permission = "products:create"
+	ProductsUpdate Permission = "products:update"
+	ProductsDelete Permission = "products:delete"
+	ProductsImport Permission = "products:import"
+	// Menyetujui produk pending_review dan melihat produk semua seller di semua status
+	ProductsReview Permission = "products:review"
+
+	OrdersCreate Permission = "orders:create"
+	OrdersRead   Permission = "orders:read"
+	OrdersUpdate Permission = "orders:update"
+	OrdersDelete Permission = "orders:delete"
+	OrdersPay    Permission = "orders:pay"
+
+	// Item pesanan yang produknya dijual oleh seller tersebut
+	SellerOrdersRead   Permission = "seller_orders:read"
+	SellerOrdersUpdate Permission = "seller_orders:update"
+	SellerAPIKeys      Permission = "seller_api_keys:manage"
+	ShopManage         Permission = "shop:manage"
+
+	CartManage Permission = "cart:manage"
+
+	SellerApplicationsSubmit Permission = "seller_applications:submit"
+	SellerApplicationsReview Permission = "seller_applications:review"
+
+	ReviewsCreate Permission = "reviews:create"
+	ReviewsDelete Permission = "reviews:delete"
+
+	CategoriesManage Permission = "categories:manage"
+	UsersManage      Permission = "users:manage"
+	UsersImpersonate Permission = "users:impersonate"
+	SecurityManage   Permission = "security:manage"
+	SearchManage     Permission = "search:manage"
+)
+
+// Scope menentukan resource mana yang boleh disentuh dengan sebuah permission.
+type Scope int
+
+const (
+	// ScopeOwn: hanya resource yang pemiliknya adalah user itu sendiri.
+	ScopeOwn Scope = iota + 1
+	// ScopeAny: semua resource.
+	ScopeAny
+)
+
+var ErrForbidden = errors.New("forbidden: access denied")
+
+type grants map[Permission]Scope
+
+var rolePermissions = map[string]grants{
+	"buyer": {
+		OrdersCreate:  ScopeOwn,
+		OrdersRead:    ScopeOwn,
+		OrdersDelete:  ScopeOwn,
+		OrdersPay:     ScopeOwn,
+		CartManage:    ScopeOwn,
+		ReviewsCreate: ScopeOwn,
+		ReviewsDelete: ScopeOwn,
+
+		SellerApplicationsSubmit: ScopeOwn,
+	},
+	"seller": {
+		ProductsCreate:     ScopeOwn,
+		ProductsUpdate:     ScopeOwn,
+		ProductsDelete:     ScopeOwn,
+		ProductsImport:     ScopeOwn,
+		SellerOrdersRead:   ScopeOwn,
+		SellerOrdersUpdate: ScopeOwn,
+		SellerAPIKeys:      ScopeOwn,
+		ShopManage:         ScopeOwn,
+		OrdersCreate:       ScopeOwn,
+		OrdersRead:         ScopeOwn,
+		OrdersDelete:       ScopeOwn,
+		OrdersPay:          ScopeOwn,
+		CartManage:         ScopeOwn,
+		ReviewsCreate:      ScopeOwn,
+		ReviewsDelete:      ScopeOwn,
+	},
+	"admin": {
+		ProductsUpdate:   ScopeAny,
+		ProductsDelete:   ScopeAny,
+		ProductsReview:   ScopeAny,
+		OrdersRead:       ScopeAny,
+		OrdersUpdate:     ScopeAny,
+		OrdersDelete:     ScopeAny,
+		CartManage:       ScopeAny,
+		ReviewsDelete:    ScopeAny,
+		CategoriesManage: ScopeAny,
+		UsersManage:      ScopeAny,
+		UsersImpersonate: ScopeAny,
+		SecurityManage:   ScopeAny,
+		SearchManage:     ScopeAny,
+
+		SellerApplicationsReview: ScopeAny,
+	},
+}
+
+// Subject adalah user yang sedang melakukan request.
+type Subject struct {
+	UserID string
+	Role   string
+}
+
+// Can memeriksa apakah role memiliki permission, tanpa melihat resource tertentu.
+// Dipakai di level route; kepemilikan diperiksa kemudian dengan Authorize.
+func Can(role string, permission Permission) bool {
+	_, ok := rolePermissions[role][permission]
+	return ok
+}
+
+// Authorize memeriksa permission terhadap resource yang dimiliki ownerID.
+func Authorize(subject Subject, permission Permission, ownerID string) error {
+	scope, ok := rolePermissions[subject.Role][permission]
+	if !ok {
+		return ErrForbidden
+	}
+	if scope == ScopeAny {
+		return nil
+	}
+	if subject.UserID == "" || ownerID != subject.UserID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Permissions mengembalikan permission milik role, misalnya untuk ditampilkan di frontend.
+func Permissions(role string) []Permission {
+	perms := make([]Permission, 0, len(rolePermissions[role]))
+	for _, p := range allPermissions {
+		if _, ok := rolePermissions[role][p]; ok {
+			perms = append(perms, p)
+		}
+	}
+	return perms
+}
+
+var allPermissions = []Permission{
+	ProductsCreate, ProductsUpdate, ProductsDelete, ProductsImport, ProductsReview,
+	OrdersCreate, OrdersRead, OrdersUpdate, OrdersDelete, OrdersPay,
+	SellerOrdersRead, SellerOrdersUpdate, SellerAPIKeys, ShopManage,
+	CartManage,
+	SellerApplicationsSubmit, SellerApplicationsReview,
+	ReviewsCreate, ReviewsDelete,
+	CategoriesManage, UsersManage, UsersImpersonate, SecurityManage, SearchManage,
+}
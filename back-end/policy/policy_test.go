@@ -0,0 +1,122 @@
+package policy
+
+import "testing"
+
+// Matriks lengkap: permission yang tidak tercantum untuk sebuah role harus ditolak.
+var expectedScopes = map[Permission]map[string]Scope{
+	ProductsCreate: {"seller": ScopeOwn},
+	ProductsUpdate: {"seller": ScopeOwn, "admin": ScopeAny},
+	ProductsDelete: {"seller": ScopeOwn, "admin": ScopeAny},
+	ProductsImport: {"seller": ScopeOwn},
+	ProductsReview: {"admin": ScopeAny},
+
+	OrdersCreate: {"buyer": ScopeOwn, "seller": ScopeOwn},
+	OrdersRead:   {"buyer": ScopeOwn, "seller": ScopeOwn, "admin": ScopeAny},
+	OrdersUpdate: {"admin": ScopeAny},
+	OrdersDelete: {"buyer": ScopeOwn, "seller": ScopeOwn, "admin": ScopeAny},
+	OrdersPay:    {"buyer": ScopeOwn, "seller": ScopeOwn},
+
+	SellerOrdersRead:   {"seller": ScopeOwn},
+	SellerOrdersUpdate: {"seller": ScopeOwn},
+	SellerAPIKeys:      {"seller": ScopeOwn},
+	ShopManage:         {"seller": ScopeOwn},
+
+	CartManage: {"buyer": ScopeOwn, "seller": ScopeOwn, "admin": ScopeAny},
+
+	SellerApplicationsSubmit: {"buyer": ScopeOwn},
+	SellerApplicationsReview: {"admin": ScopeAny},
+
+	ReviewsCreate: {"buyer": ScopeOwn, "seller": ScopeOwn},
+	ReviewsDelete: {"buyer": ScopeOwn, "seller": ScopeOwn, "admin": ScopeAny},
+
+	CategoriesManage: {"admin": ScopeAny},
+	UsersManage:      {"admin": ScopeAny},
+	UsersImpersonate: {"admin": ScopeAny},
+	SecurityManage:   {"admin": ScopeAny},
+	SearchManage:     {"admin": ScopeAny},
+}
+
+var roles = []string{"buyer", "seller", "admin", "", "unknown"}
+
+func TestMatrixCoversAllPermissions(t *testing.T) {
+	if len(expectedScopes) != len(allPermissions) {
+		t.Fatalf("expectedScopes has %d permissions, allPermissions has %d", len(expectedScopes), len(allPermissions))
+	}
+	for _, p := range allPermissions {
+		if _, ok := expectedScopes[p]; !ok {
+			t.Errorf("permission %s missing from test matrix", p)
+		}
+	}
+	for role, perms := range rolePermissions {
+		for p := range perms {
+			if _, ok := expectedScopes[p]; !ok {
+				t.Errorf("role %s grants %s which is not in allPermissions", role, p)
+			}
+		}
+	}
+}
+
+func TestCan(t *testing.T) {
+	for _, p := range allPermissions {
+		for _, role := range roles {
+			_, want := expectedScopes[p][role]
+			if got := Can(role, p); got != want {
+				t.Errorf("Can(%q, %s) = %v, want %v", role, p, got, want)
+			}
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	const self = "user-1"
+	const other = "user-2"
+
+	for _, p := range allPermissions {
+		for _, role := range roles {
+			scope := expectedScopes[p][role]
+			tests := []struct {
+				name    string
+				subject Subject
+				ownerID string
+				want    bool
+			}{
+				{"own resource", Subject{UserID: self, Role: role}, self, scope != 0},
+				{"other resource", Subject{UserID: self, Role: role}, other, scope == ScopeAny},
+				{"no owner", Subject{UserID: self, Role: role}, "", scope == ScopeAny},
+				// Subject tanpa ID tidak boleh cocok dengan resource tanpa pemilik
+				{"anonymous subject", Subject{Role: role}, "", scope == ScopeAny},
+			}
+			for _, tt := range tests {
+				err := Authorize(tt.subject, p, tt.ownerID)
+				if got := err == nil; got != tt.want {
+					t.Errorf("Authorize(%s, %s, %s): err = %v, want allowed=%v", role, p, tt.name, err, tt.want)
+				}
+				if err != nil && err != ErrForbidden {
+					t.Errorf("Authorize(%s, %s, %s) returned %v, want ErrForbidden", role, p, tt.name, err)
+				}
+			}
+		}
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	for _, role := range roles {
+		var want []Permission
+		for _, p := range allPermissions {
+			if _, ok := expectedScopes[p][role]; ok {
+				want = append(want, p)
+			}
+		}
+
+		got := Permissions(role)
+		if len(got) != len(want) {
+			t.Errorf("Permissions(%q) = %v, want %v", role, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("Permissions(%q)[%d] = %s, want %s", role, i, got[i], want[i])
+			}
+		}
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"ecommerce-backend/controllers"
 	"ecommerce-backend/middlewares"
+	"ecommerce-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,10 +11,22 @@ import (
 func SetupAdminRoutes(router *gin.Engine) {
 	adminGroup := router.Group("/api/admin")
 	adminGroup.Use(middlewares.AuthMiddleware())
-	adminGroup.Use(middlewares.RoleMiddleware("admin"))
-
-	adminGroup.GET("/users", controllers.GetAllUsers)
-	adminGroup.GET("/users/:id", controllers.GetUserListByID)
-	adminGroup.PUT("/users/:id/role", controllers.UpdateUserRole)
-	adminGroup.PUT("/users/:id/active-status", controllers.ToggleUserActiveStatus)
+	adminGroup.GET("/users", middlewares.RequirePermission(policy.UsersManage), controllers.GetAllUsers)
+	adminGroup.GET("/users/:id", middlewares.RequirePermission(policy.UsersManage), controllers.GetUserListByID)
+	adminGroup.PUT("/users/:id/role", middlewares.RequirePermission(policy.UsersManage), controllers.UpdateUserRole)
+	adminGroup.PUT("/users/:id/active-status", middlewares.RequirePermission(policy.UsersManage), controllers.ToggleUserActiveStatus)
+	adminGroup.POST("/users/:id/unlock", middlewares.RequirePermission(policy.SecurityManage), controllers.UnlockUserAccount)
+	adminGroup.GET("/security-events", middlewares.RequirePermission(policy.SecurityManage), controllers.GetSecurityEvents)
+	adminGroup.GET("/auth-policies", middlewares.RequirePermission(policy.SecurityManage), controllers.GetAuthPolicies)
+	adminGroup.PUT("/auth-policies/:role", middlewares.RequirePermission(policy.SecurityManage), controllers.UpdateAuthPolicy)
+	adminGroup.POST("/users/:id/impersonate", middlewares.RequirePermission(policy.UsersImpersonate), controllers.ImpersonateUser)
+	adminGroup.GET("/impersonations", middlewares.RequirePermission(policy.UsersImpersonate), controllers.GetImpersonations)
+	adminGroup.GET("/impersonations/:id/audit", middlewares.RequirePermission(policy.UsersImpersonate), controllers.GetImpersonationAuditLog)
+	adminGroup.POST("/impersonations/:id/end", middlewares.RequirePermission(policy.UsersImpersonate), controllers.EndImpersonation)
+	adminGroup.GET("/seller-applications", middlewares.RequirePermission(policy.SellerApplicationsReview), controllers.GetSellerApplications)
+	adminGroup.GET("/seller-applications/:id", middlewares.RequirePermission(policy.SellerApplicationsReview), controllers.GetSellerApplicationByID)
+	adminGroup.POST("/seller-applications/:id/approve", middlewares.RequirePermission(policy.SellerApplicationsReview), controllers.ApproveSellerApplication)
+	adminGroup.POST("/seller-applications/:id/reject", middlewares.RequirePermission(policy.SellerApplicationsReview), controllers.RejectSellerApplication)
+	adminGroup.GET("/products", middlewares.RequirePermission(policy.ProductsReview), controllers.GetAdminProducts)
+	adminGroup.POST("/search/reindex", middlewares.RequirePermission(policy.SearchManage), controllers.ReindexProducts)
 }
@@ -8,11 +8,26 @@ import (
 )
 
 func SetupAuthRoutes(r *gin.Engine) {
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS)
+
 	authRoutes := r.Group("/auth")
 	{
 		authRoutes.POST("/register", controllers.Register)
 		authRoutes.POST("/login", controllers.Login)
-		authRoutes.POST("/logout", controllers.Logout)
+		authRoutes.POST("/refresh", middlewares.RequireCSRFForCookie("refresh_token"), controllers.RefreshToken)
+		authRoutes.POST("/verify-email", controllers.VerifyEmail)
+		authRoutes.POST("/resend-verification", controllers.ResendVerificationEmail)
+		authRoutes.POST("/forgot-password", controllers.ForgotPassword)
+		authRoutes.POST("/reset-password", controllers.ResetPassword)
+		authRoutes.POST("/magic-link", controllers.RequestMagicLink)
+		authRoutes.POST("/magic-link/consume", controllers.ConsumeMagicLink)
+		authRoutes.POST("/mfa/enroll", controllers.EnrollMFAWithChallenge)
+		authRoutes.POST("/mfa/verify", controllers.VerifyMFALogin)
+		authRoutes.GET("/oauth/:provider/login", controllers.OAuthLogin)
+		authRoutes.GET("/oauth/:provider/callback", controllers.OAuthCallback)
+		authRoutes.POST("/oauth/exchange", controllers.OAuthExchange)
+		authRoutes.POST("/logout", middlewares.RequireCSRFForCookie("refresh_token"), controllers.Logout)
+		authRoutes.GET("/csrf", controllers.GetCSRFToken)
 		authRoutes.GET("/status", middlewares.AuthMiddleware(), controllers.GetAuthStatus)
 	}
 }
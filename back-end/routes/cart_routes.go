@@ -2,12 +2,15 @@ package routes
 
 import (
 	"ecommerce-backend/controllers"
+	"ecommerce-backend/middlewares"
+	"ecommerce-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
 
 func CartRoutes(router *gin.Engine) {
 	cart := router.Group("/cart")
+	cart.Use(middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.CartManage))
 	{
 		cart.POST("/", controllers.AddToCart)
 		cart.GET("/:user_id", controllers.GetCartByUser)
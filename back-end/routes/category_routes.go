@@ -3,6 +3,7 @@ package routes
 import (
 	"ecommerce-backend/controllers"
 	"ecommerce-backend/middlewares"
+	"ecommerce-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,9 +11,11 @@ import (
 func SetupCategoryRoutes(r *gin.Engine) {
 	categoryRoutes := r.Group("/categories")
 	{
-		categoryRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.CreateCategory)
+		categoryRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.CategoriesManage), controllers.CreateCategory)
 		categoryRoutes.GET("/", controllers.GetCategories)
 		categoryRoutes.GET("/:id", controllers.GetCategoryByID)
-		categoryRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), controllers.DeleteCategory)
+		categoryRoutes.GET("/:id/attributes", controllers.GetCategoryAttributes)
+		categoryRoutes.PUT("/:id/attributes", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.CategoriesManage), controllers.SetCategoryAttributes)
+		categoryRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.CategoriesManage), controllers.DeleteCategory)
 	}
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"ecommerce-backend/controllers"
 	"ecommerce-backend/middlewares"
+	"ecommerce-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,10 +11,11 @@ import (
 func SetupOrderRoutes(r *gin.Engine) {
 	orderGroup := r.Group("/orders").Use(middlewares.AuthMiddleware())
 	{
-		orderGroup.POST("/", controllers.CreateOrder)      // Membuat pesanan
-		orderGroup.GET("/:id", controllers.GetOrderByID)   // Mengambil pesanan berdasarkan ID
-		orderGroup.GET("/", controllers.GetOrdersByUserID) // Mengambil semua pesanan untuk pengguna yang terautentikasi
-		orderGroup.PUT("/:id", controllers.UpdateOrder)    // Memperbarui pesanan berdasarkan ID
-		orderGroup.DELETE("/:id", controllers.DeleteOrder) // Menghapus pesanan berdasarkan ID
+		orderGroup.POST("/", middlewares.RequirePermission(policy.OrdersCreate), middlewares.RequireVerifiedEmail(), controllers.CreateOrder) // Membuat pesanan
+		orderGroup.GET("/:id", middlewares.RequirePermission(policy.OrdersRead), controllers.GetOrderByID)                                    // Mengambil pesanan berdasarkan ID
+		orderGroup.GET("/:id/invoice", middlewares.RequirePermission(policy.OrdersRead), controllers.GetOrderInvoice)                         // Invoice pesanan (JSON atau ?format=html)
+		orderGroup.GET("/", middlewares.RequirePermission(policy.OrdersRead), controllers.GetOrdersByUserID)                                  // Mengambil semua pesanan untuk pengguna yang terautentikasi
+		orderGroup.PUT("/:id", middlewares.RequirePermission(policy.OrdersUpdate), controllers.UpdateOrder)                                   // Memperbarui pesanan berdasarkan ID
+		orderGroup.DELETE("/:id", middlewares.RequirePermission(policy.OrdersDelete), controllers.DeleteOrder)                                // Menghapus pesanan berdasarkan ID
 	}
 }
@@ -2,6 +2,8 @@ package routes
 
 import (
 	"ecommerce-backend/controllers"
+	"ecommerce-backend/middlewares"
+	"ecommerce-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -9,7 +11,7 @@ import (
 func RegisterPaymentRoutes(r *gin.Engine) {
 	payment := r.Group("/payment")
 	{
-		payment.POST("/", controllers.CreatePayment)
+		payment.POST("/", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.OrdersPay), controllers.CreatePayment)
 		payment.POST("/webhook", controllers.MidtransWebhook)
 		payment.GET("/:order_id", controllers.GetPayment)
 	}
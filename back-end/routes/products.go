@@ -3,6 +3,8 @@ package routes
 import (
 	"ecommerce-backend/controllers"
 	"ecommerce-backend/middlewares"
+	"ecommerce-backend/models"
+	"ecommerce-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,11 +14,20 @@ func SetupProductRoutes(r *gin.Engine) {
 	{
 		productRoutes.GET("", controllers.GetProducts)
 		productRoutes.GET("/:id", controllers.GetProductByID)
+		productRoutes.GET("/:id/images", controllers.GetProductImages)
 		productRoutes.GET("/search", controllers.SearchProducts)
+		productRoutes.GET("/suggest", controllers.SuggestProducts)
 
-		productRoutes.Use(middlewares.AuthMiddleware())
-		productRoutes.POST("", controllers.CreateProduct)
-		productRoutes.PUT("/:id", controllers.UpdateProduct)
-		productRoutes.DELETE("/:id", controllers.DeleteProduct)
+		productRoutes.Use(middlewares.AuthMiddleware(models.ScopeProductsWrite))
+		productRoutes.POST("", middlewares.RequirePermission(policy.ProductsCreate), middlewares.RequireVerifiedEmail(), controllers.CreateProduct)
+		productRoutes.PUT("/:id", middlewares.RequirePermission(policy.ProductsUpdate), controllers.UpdateProduct)
+		productRoutes.PATCH("/:id/status", middlewares.RequirePermission(policy.ProductsUpdate), controllers.ChangeProductStatus)
+		productRoutes.PUT("/:id/variants", middlewares.RequirePermission(policy.ProductsUpdate), controllers.SetProductVariants)
+		productRoutes.PATCH("/:id/variants/:variantId", middlewares.RequirePermission(policy.ProductsUpdate), controllers.UpdateProductVariant)
+		productRoutes.POST("/:id/images", middlewares.RequirePermission(policy.ProductsUpdate), controllers.AddProductImages)
+		productRoutes.PUT("/:id/images/order", middlewares.RequirePermission(policy.ProductsUpdate), controllers.ReorderProductImages)
+		productRoutes.PATCH("/:id/images/:imageId", middlewares.RequirePermission(policy.ProductsUpdate), controllers.UpdateProductImage)
+		productRoutes.DELETE("/:id/images/:imageId", middlewares.RequirePermission(policy.ProductsUpdate), controllers.DeleteProductImage)
+		productRoutes.DELETE("/:id", middlewares.RequirePermission(policy.ProductsDelete), controllers.DeleteProduct)
 	}
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"ecommerce-backend/controllers"
 	"ecommerce-backend/middlewares"
+	"ecommerce-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,9 +12,9 @@ func RegisterReviewRoutes(router *gin.Engine) {
 	reviewController := controllers.NewReviewController()
 	reviewRoutes := router.Group("/reviews")
 	{
-		reviewRoutes.POST("/", middlewares.AuthMiddleware(), reviewController.CreateReview)
+		reviewRoutes.POST("/", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.ReviewsCreate), reviewController.CreateReview)
 		reviewRoutes.GET("/", reviewController.GetReviews)
 		reviewRoutes.GET("/:product_id", reviewController.GetReviewsByProduct)
-		reviewRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RoleMiddleware("admin"), reviewController.DeleteReview)
+		reviewRoutes.DELETE("/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.ReviewsDelete), reviewController.DeleteReview)
 	}
 }
@@ -3,16 +3,31 @@ package routes
 import (
 	"ecommerce-backend/controllers"
 	"ecommerce-backend/middlewares"
+	"ecommerce-backend/models"
+	"ecommerce-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
 
 func SetupSellerRoutes(r *gin.Engine) {
 	sellerRoutes := r.Group("/seller")
-	sellerRoutes.Use(middlewares.AuthMiddleware(), middlewares.RoleMiddleware("seller"))
 	{
-		sellerRoutes.GET("/order-items", controllers.GetSellerOrderItems)
-		sellerRoutes.GET("/order-items/:id", controllers.GetSellerOrderItemByID)
-		sellerRoutes.PATCH("/order-items/:id/status", controllers.UpdateOrderItemStatus)
+		sellerRoutes.GET("/order-items", middlewares.AuthMiddleware(models.ScopeOrdersRead), middlewares.RequirePermission(policy.SellerOrdersRead), controllers.GetSellerOrderItems)
+		sellerRoutes.GET("/order-items/:id", middlewares.AuthMiddleware(models.ScopeOrdersRead), middlewares.RequirePermission(policy.SellerOrdersRead), controllers.GetSellerOrderItemByID)
+		sellerRoutes.GET("/orders/:id/invoice", middlewares.AuthMiddleware(models.ScopeOrdersRead), middlewares.RequirePermission(policy.SellerOrdersRead), controllers.GetSellerInvoice)
+		sellerRoutes.PATCH("/order-items/:id/status", middlewares.AuthMiddleware(models.ScopeOrdersWrite), middlewares.RequirePermission(policy.SellerOrdersUpdate), controllers.UpdateOrderItemStatus)
+		sellerRoutes.GET("/shop", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.ShopManage), controllers.GetShopProfile)
+
+		sellerRoutes.GET("/products", middlewares.AuthMiddleware(models.ScopeProductsWrite), middlewares.RequirePermission(policy.ProductsUpdate), controllers.GetSellerProducts)
+		sellerRoutes.GET("/products/:id", middlewares.AuthMiddleware(models.ScopeProductsWrite), middlewares.RequirePermission(policy.ProductsUpdate), controllers.GetSellerProduct)
+		sellerRoutes.POST("/products/import", middlewares.AuthMiddleware(models.ScopeProductsWrite), middlewares.RequirePermission(policy.ProductsImport), middlewares.RequireVerifiedEmail(), controllers.ImportProducts)
+		sellerRoutes.GET("/products/imports", middlewares.AuthMiddleware(models.ScopeProductsWrite), middlewares.RequirePermission(policy.ProductsImport), controllers.GetProductImportJobs)
+		sellerRoutes.GET("/products/imports/:id", middlewares.AuthMiddleware(models.ScopeProductsWrite), middlewares.RequirePermission(policy.ProductsImport), controllers.GetProductImportJob)
+		sellerRoutes.GET("/products/export", middlewares.AuthMiddleware(models.ScopeProductsWrite), middlewares.DenyImpersonation(), middlewares.RequirePermission(policy.ProductsImport), controllers.ExportProducts)
+
+		// API key hanya bisa dikelola dari sesi login, bukan dengan API key lain
+		sellerRoutes.GET("/api-keys", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.SellerAPIKeys), controllers.GetAPIKeys)
+		sellerRoutes.POST("/api-keys", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.SellerAPIKeys), controllers.CreateAPIKey)
+		sellerRoutes.DELETE("/api-keys/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.SellerAPIKeys), controllers.RevokeAPIKey)
 	}
 }
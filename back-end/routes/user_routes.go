@@ -3,6 +3,7 @@ package routes
 import (
 	"ecommerce-backend/controllers"
 	"ecommerce-backend/middlewares"
+	"ecommerce-backend/policy"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +13,16 @@ func UserRoutes(r *gin.Engine) {
 
 	r.GET("/profile", middlewares.AuthMiddleware(), userController.GetProfile)
 	r.PUT("/profile", middlewares.AuthMiddleware(), userController.UpdateProfile)
+	r.PUT("/profile/password", middlewares.AuthMiddleware(), userController.ChangePassword)
+	r.GET("/profile/sessions", middlewares.AuthMiddleware(), userController.GetSessions)
+	r.DELETE("/profile/sessions/:id", middlewares.AuthMiddleware(), userController.RevokeSession)
+	r.POST("/profile/oauth/:provider/link", middlewares.AuthMiddleware(), controllers.OAuthLink)
+	r.POST("/profile/mfa/setup", middlewares.AuthMiddleware(), controllers.SetupMFA)
+	r.POST("/profile/mfa/enable", middlewares.AuthMiddleware(), controllers.EnableMFA)
+	r.POST("/profile/mfa/disable", middlewares.AuthMiddleware(), controllers.DisableMFA)
+	r.POST("/profile/mfa/recovery-codes", middlewares.AuthMiddleware(), controllers.RegenerateRecoveryCodes)
+	r.POST("/seller-applications", middlewares.AuthMiddleware(), middlewares.RequirePermission(policy.SellerApplicationsSubmit), controllers.SubmitSellerApplication)
+	r.GET("/seller-applications/me", middlewares.AuthMiddleware(), controllers.GetMySellerApplications)
 	r.GET("/search-user", controllers.SearchUser)
 
 }
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+const maxTermLength = 64
+
+var stopwords = map[string]bool{
+	// English
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "the": true, "to": true, "with": true,
+	// Indonesia
+	"ada": true, "adalah": true, "akan": true, "atau": true, "dan": true, "dari": true,
+	"dengan": true, "di": true, "ini": true, "itu": true, "ke": true, "pada": true,
+	"dalam": true, "untuk": true, "yang": true, "juga": true,
+}
+
+type token struct {
+	text  string
+	start int
+	end   int
+}
+
+// tokenize memecah teks menjadi kata (huruf/angka) beserta posisi byte-nya.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{text: text[start:i], start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{text: text[start:], start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// normalize mengubah satu kata menjadi term index, atau "" jika kata diabaikan.
+func normalize(word string) string {
+	word = strings.ToLower(word)
+	if len(word) < 2 || stopwords[word] {
+		return ""
+	}
+	term := Stem(word)
+	if len(term) > maxTermLength {
+		term = term[:maxTermLength]
+	}
+	return term
+}
+
+// Analyze mengembalikan term dari teks, termasuk duplikat (untuk menghitung frekuensi).
+func Analyze(text string) []string {
+	tokens := tokenize(text)
+	terms := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if term := normalize(t.text); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// vocabulary mengembalikan kata unik (huruf kecil, belum di-stem) dari teks
+// untuk kosakata autocomplete dan koreksi ejaan.
+func vocabulary(texts ...string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, text := range texts {
+		for _, t := range tokenize(text) {
+			word := strings.ToLower(t.text)
+			if len(word) < 3 || len(word) > maxTermLength || !isAlpha(word) || stopwords[word] || seen[word] {
+				continue
+			}
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// QueryTerms mengembalikan term unik dari teks pencarian.
+func QueryTerms(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range Analyze(text) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
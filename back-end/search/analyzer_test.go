@@ -0,0 +1,68 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []token
+	}{
+		{name: "empty", text: "", want: nil},
+		{
+			name: "punctuation and digits",
+			text: "Kaos Polos—100% Katun!",
+			want: []token{
+				{text: "Kaos", start: 0, end: 4},
+				{text: "Polos", start: 5, end: 10},
+				{text: "100", start: 13, end: 16},
+				{text: "Katun", start: 18, end: 23},
+			},
+		},
+		{
+			name: "multi-byte letters keep byte offsets",
+			text: "café crème",
+			want: []token{
+				{text: "café", start: 0, end: 5},
+				{text: "crème", start: 6, end: 12},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Sepatu dan Tas untuk the Kids", []string{"sepatu", "tas", "kid"}},
+		{"a b c", []string{}},
+		{"Mainan mainan", []string{"main", "main"}},
+		{"iPhone 15 Pro", []string{"iphone", "15", "pro"}},
+	}
+
+	for _, tt := range tests {
+		if got := Analyze(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Analyze(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestQueryTermsAreUnique(t *testing.T) {
+	got := QueryTerms("sepatu Sepatu SEPATU lari berlari")
+	want := []string{"sepatu", "lari"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryTerms = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package search
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Parameter BM25 standar.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// DBIndex menyimpan inverted index di tabel search_terms dan menghitung skor
+// BM25 di database, sehingga tidak butuh service tambahan untuk dijalankan.
+type DBIndex struct {
+	db *gorm.DB
+}
+
+// NewDBIndex membuat index di atas koneksi db; nil berarti memakai config.DB.
+func NewDBIndex(db *gorm.DB) *DBIndex {
+	return &DBIndex{db: db}
+}
+
+func (i *DBIndex) conn() *gorm.DB {
+	if i.db != nil {
+		return i.db
+	}
+	return config.DB
+}
+
+func (i *DBIndex) Index(doc Document) error {
+	nameTerms := Analyze(doc.Name)
+	descTerms := Analyze(doc.Description)
+
+	var rows []models.SearchTerm
+	rows = appendTermRows(rows, doc.ID, FieldName, nameTerms)
+	rows = appendTermRows(rows, doc.ID, FieldDescription, descTerms)
+
+	return i.conn().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ?", doc.ID).Delete(&models.SearchTerm{}).Error; err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+				return err
+			}
+		}
+		if words := vocabulary(doc.Name, doc.Description); len(words) > 0 {
+			now := time.Now()
+			rows := make([]models.SearchWord, len(words))
+			for n, w := range words {
+				rows[n] = models.SearchWord{Word: w, Length: len(w), LastSeenAt: now}
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+			}).CreateInBatches(rows, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.SearchDocument{
+			DocumentID:        doc.ID,
+			NameLength:        len(nameTerms),
+			DescriptionLength: len(descTerms),
+		}).Error
+	})
+}
+
+func appendTermRows(rows []models.SearchTerm, docID, field string, terms []string) []models.SearchTerm {
+	freq := make(map[string]int)
+	var order []string
+	for _, term := range terms {
+		if freq[term] == 0 {
+			order = append(order, term)
+		}
+		freq[term]++
+	}
+	for _, term := range order {
+		rows = append(rows, models.SearchTerm{
+			Term:        term,
+			DocumentID:  docID,
+			Field:       field,
+			Frequency:   freq[term],
+			FieldLength: len(terms),
+		})
+	}
+	return rows
+}
+
+func (i *DBIndex) Delete(id string) error {
+	return i.conn().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ?", id).Delete(&models.SearchTerm{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("document_id = ?", id).Delete(&models.SearchDocument{}).Error
+	})
+}
+
+func (i *DBIndex) Count() (int64, error) {
+	var count int64
+	err := i.conn().Model(&models.SearchDocument{}).Count(&count).Error
+	return count, err
+}
+
+func (i *DBIndex) Vocabulary(prefix string, limit int) ([]string, error) {
+	var words []string
+	err := i.conn().Model(&models.SearchWord{}).
+		Where("word LIKE ?", LikePrefix(strings.ToLower(prefix))).
+		Order("word").
+		Limit(limit).
+		Pluck("word", &words).Error
+	return words, err
+}
+
+func (i *DBIndex) FuzzyCandidates(first string, minLen, maxLen, limit int) ([]string, error) {
+	query := i.conn().Model(&models.SearchWord{}).
+		Where("word LIKE ?", LikePrefix(strings.ToLower(first))).
+		Where("length >= ?", minLen)
+	if maxLen > 0 {
+		query = query.Where("length <= ?", maxLen)
+	}
+	var words []string
+	err := query.Order("length").Order("word").Limit(limit).Pluck("word", &words).Error
+	return words, err
+}
+
+func (i *DBIndex) PruneVocabulary(seenBefore time.Time) (int64, error) {
+	result := i.conn().
+		Where("last_seen_at < ? OR last_seen_at IS NULL", seenBefore).
+		Delete(&models.SearchWord{})
+	return result.RowsAffected, result.Error
+}
+
+// Search memakai semantik OR: dokumen yang cocok dengan lebih banyak term (dan
+// term yang lebih jarang) mendapat skor lebih tinggi.
+func (i *DBIndex) Search(q Query) ([]Hit, error) {
+	terms := QueryTerms(q.Text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	db := i.conn()
+
+	var stats struct {
+		Total   int64
+		AvgName float64
+		AvgDesc float64
+	}
+	if err := db.Model(&models.SearchDocument{}).
+		Select("COUNT(*) AS total, COALESCE(AVG(name_length), 0) AS avg_name, COALESCE(AVG(description_length), 0) AS avg_desc").
+		Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	if stats.Total == 0 {
+		return nil, nil
+	}
+
+	var dfs []struct {
+		Term string
+		DF   int64 `gorm:"column:df"`
+	}
+	if err := db.Model(&models.SearchTerm{}).
+		Select("term, COUNT(DISTINCT document_id) AS df").
+		Where("term IN ?", terms).
+		Group("term").
+		Scan(&dfs).Error; err != nil {
+		return nil, err
+	}
+	if len(dfs) == 0 {
+		return nil, nil
+	}
+
+	// Bobot IDF dihitung di Go lalu disisipkan sebagai CASE supaya skor akhir
+	// (BM25 per field x boost field) tetap dijumlahkan di database.
+	var idfCase strings.Builder
+	var args []interface{}
+	idfCase.WriteString("CASE term")
+	for _, d := range dfs {
+		idfCase.WriteString(" WHEN ? THEN ?")
+		args = append(args, d.Term, idf(stats.Total, d.DF))
+	}
+	idfCase.WriteString(" ELSE 0 END")
+
+	avgName := nonZero(stats.AvgName)
+	avgDesc := nonZero(stats.AvgDesc)
+	scoreExpr := "SUM((" + idfCase.String() + ")" +
+		" * (frequency * ?) / (frequency + ? * (1 - ? + ? * field_length / (CASE field WHEN ? THEN ? ELSE ? END)))" +
+		" * (CASE field WHEN ? THEN ? ELSE ? END)) AS score"
+	args = append(args,
+		bm25K1+1, bm25K1, bm25B, bm25B, FieldName, avgName, avgDesc,
+		FieldName, fieldBoosts[FieldName], fieldBoosts[FieldDescription],
+	)
+
+	var hits []struct {
+		DocumentID string
+		Score      float64
+	}
+	query := db.Model(&models.SearchTerm{}).
+		Select("document_id, "+scoreExpr, args...).
+		Where("term IN ?", terms).
+		Group("document_id").
+		Order("score DESC").
+		Order("document_id")
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+	if err := query.Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]Hit, len(hits))
+	for n, h := range hits {
+		result[n] = Hit{ID: h.DocumentID, Score: h.Score}
+	}
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package search
+
+import (
+	"strings"
+)
+
+// Jumlah kandidat kosakata yang dibandingkan per kata saat mencari koreksi ejaan.
+// Kandidat sudah disaring berdasarkan huruf awal dan panjang kata, jadi batas ini
+// hanya pengaman untuk kosakata yang sangat besar.
+const maxFuzzyCandidates = 5000
+
+// Levenshtein menghitung edit distance antar rune.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// MaxEdits adalah toleransi typo berdasarkan panjang kata; kata pendek harus tepat.
+func MaxEdits(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 3:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// LikePrefix meng-escape wildcard LIKE pada s dan menambahkan % di akhir.
+func LikePrefix(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s) + "%"
+}
+
+// Correct mencari kata di kosakata index yang paling dekat dengan word. Kandidat
+// diambil dari kata dengan huruf awal yang sama (typo di huruf pertama jarang
+// terjadi) dan panjang yang berbeda paling banyak MaxEdits, karena kata di luar
+// rentang itu tidak mungkin cukup dekat.
+func Correct(idx Index, word string) (string, bool, error) {
+	return closest(idx, word, true, func(candidate string) int {
+		return Levenshtein(word, candidate)
+	})
+}
+
+// CorrectPrefix seperti Correct tetapi untuk kata yang belum selesai diketik:
+// "sepat" cocok dengan "sepatu", dan "separ" (typo) juga dianggap cocok.
+func CorrectPrefix(idx Index, prefix string) (string, bool, error) {
+	n := len([]rune(prefix))
+	// Kandidat boleh lebih panjang dari prefix karena hanya awalannya yang dibandingkan
+	return closest(idx, prefix, false, func(candidate string) int {
+		rc := []rune(candidate)
+		best := -1
+		// Bandingkan dengan awalan kandidat sepanjang n-1..n+1 supaya huruf yang
+		// terlewat atau berlebih tetap dianggap satu edit.
+		for l := n - 1; l <= n+1; l++ {
+			if l <= 0 || l > len(rc) {
+				continue
+			}
+			if d := Levenshtein(prefix, string(rc[:l])); best < 0 || d < best {
+				best = d
+			}
+		}
+		if best < 0 {
+			return Levenshtein(prefix, candidate)
+		}
+		return best
+	})
+}
+
+func closest(idx Index, word string, bounded bool, distance func(string) int) (string, bool, error) {
+	word = strings.ToLower(word)
+	maxEdits := MaxEdits(word)
+	if maxEdits == 0 {
+		return "", false, nil
+	}
+	n := len([]rune(word))
+	maxLen := 0
+	if bounded {
+		maxLen = n + maxEdits
+	}
+	first := string([]rune(word)[:1])
+	candidates, err := idx.FuzzyCandidates(first, n-maxEdits, maxLen, maxFuzzyCandidates)
+	if err != nil {
+		return "", false, err
+	}
+
+	best, bestDist := "", maxEdits+1
+	for _, c := range candidates {
+		d := distance(c)
+		// Jarak sama: pilih kata yang panjangnya paling dekat, lalu urutan kandidat
+		// (terpendek lalu alfabet).
+		if d < bestDist || d == bestDist && best != "" && abs(len(c)-len(word)) < abs(len(best)-len(word)) {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" || best == word {
+		return "", false, nil
+	}
+	return best, true, nil
+}
+
+// DidYouMean mengganti setiap kata di text yang tidak ada di kosakata dengan kata
+// terdekat. Mengembalikan "" jika tidak ada kata yang bisa dikoreksi.
+func DidYouMean(idx Index, text string) (string, error) {
+	tokens := tokenize(strings.ToLower(text))
+	words := make([]string, 0, len(tokens))
+	changed := false
+	for _, t := range tokens {
+		word := t.text
+		if normalize(word) != "" {
+			known, err := idx.Vocabulary(word, 1)
+			if err != nil {
+				return "", err
+			}
+			if len(known) == 0 || known[0] != word {
+				corrected, ok, err := Correct(idx, word)
+				if err != nil {
+					return "", err
+				}
+				if ok {
+					word = corrected
+					changed = true
+				}
+			}
+		}
+		words = append(words, word)
+	}
+	if !changed {
+		return "", nil
+	}
+	return strings.Join(words, " "), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"sepatu", "sepatu", 0},
+		{"sepatu", "sepato", 1},
+		{"sepatu", "spatu", 1},
+		{"sepatu", "sepatuu", 1},
+		{"kemeja", "kmj", 3},
+		{"", "abc", 3},
+		{"café", "cafe", 1},
+	}
+	for _, tt := range tests {
+		if got := Levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func fuzzyIndex(t *testing.T, names ...string) *MemoryIndex {
+	t.Helper()
+	idx := NewMemoryIndex()
+	for i, name := range names {
+		if err := idx.Index(Document{ID: fmt.Sprint(i), Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return idx
+}
+
+func TestCorrect(t *testing.T) {
+	idx := fuzzyIndex(t, "sepatu lari", "kemeja batik", "celana panjang")
+	tests := []struct {
+		word   string
+		want   string
+		wantOK bool
+	}{
+		{"sepato", "sepatu", true},
+		{"kemaja", "kemeja", true},
+		{"sepatu", "", false},
+		// Huruf pertama berbeda tidak dikoreksi
+		{"xepatu", "", false},
+		// Kata pendek tidak dikoreksi
+		{"la", "", false},
+	}
+	for _, tt := range tests {
+		got, ok, err := Correct(idx, tt.word)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Correct(%q) = %q, %v; want %q, %v", tt.word, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCorrectIgnoresCandidatesOutsideLengthRange(t *testing.T) {
+	// Kosakata berisi banyak kata berawalan sama yang urut lebih dulu secara
+	// alfabet; kandidat yang panjangnya cocok tetap harus ditemukan.
+	names := make([]string, 0, maxFuzzyCandidates+1)
+	for i := 0; i < maxFuzzyCandidates; i++ {
+		names = append(names, fmt.Sprintf("saaaaaaaaaaa%c%c", 'a'+i/26%26, 'a'+i%26))
+	}
+	names = append(names, "sepatu")
+	idx := fuzzyIndex(t, names...)
+
+	got, ok, err := Correct(idx, "sepato")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || got != "sepatu" {
+		t.Errorf("Correct(sepato) = %q, %v; want sepatu, true", got, ok)
+	}
+}
+
+func TestCorrectPrefix(t *testing.T) {
+	idx := fuzzyIndex(t, "sepatu lari", "kemeja batik")
+	got, ok, err := CorrectPrefix(idx, "separ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || got != "sepatu" {
+		t.Errorf("CorrectPrefix(separ) = %q, %v; want sepatu, true", got, ok)
+	}
+}
+
+func TestPruneVocabulary(t *testing.T) {
+	idx := fuzzyIndex(t, "sepatu lari")
+	cutoff := time.Now().Add(time.Second)
+	if err := idx.Index(Document{ID: "0", Name: "sepatu kulit"}); err != nil {
+		t.Fatal(err)
+	}
+	// Semua kata dianggap dilihat sebelum cutoff, jadi semuanya dibuang
+	pruned, err := idx.PruneVocabulary(cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned == 0 {
+		t.Fatalf("PruneVocabulary pruned nothing")
+	}
+	words, err := idx.Vocabulary("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(words) != 0 {
+		t.Errorf("vocabulary after prune = %v, want empty", words)
+	}
+}
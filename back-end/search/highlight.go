@@ -0,0 +1,92 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+	// Jumlah byte sebelum kecocokan pertama yang ikut ditampilkan di snippet.
+	snippetLeadIn = 40
+)
+
+// Highlight mengembalikan potongan teks (maksimal sekitar maxLen byte) di sekitar
+// kecocokan pertama, dengan setiap kata yang cocok dibungkus <mark>. Teks di-escape
+// sehingga hasilnya aman dirender sebagai HTML. maxLen <= 0 berarti tanpa batas.
+func Highlight(text, query string, maxLen int) string {
+	terms := make(map[string]bool)
+	for _, term := range QueryTerms(query) {
+		terms[term] = true
+	}
+
+	tokens := tokenize(text)
+	start, end := 0, len(text)
+	if maxLen > 0 && len(text) > maxLen {
+		first := -1
+		for _, t := range tokens {
+			if terms[normalize(t.text)] {
+				first = t.start
+				break
+			}
+		}
+		if first > snippetLeadIn {
+			start = wordBoundaryAfter(text, tokens, first-snippetLeadIn)
+		}
+		end = wordBoundaryBefore(tokens, start+maxLen)
+		if end < start {
+			end = start
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, t := range tokens {
+		if t.start < start {
+			continue
+		}
+		if t.end > end {
+			break
+		}
+		if !terms[normalize(t.text)] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:t.start]))
+		b.WriteString(highlightOpen)
+		b.WriteString(html.EscapeString(t.text))
+		b.WriteString(highlightClose)
+		pos = t.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// wordBoundaryAfter mengembalikan awal kata pertama yang dimulai pada atau setelah offset.
+func wordBoundaryAfter(text string, tokens []token, offset int) int {
+	for _, t := range tokens {
+		if t.start >= offset {
+			return t.start
+		}
+	}
+	return len(text)
+}
+
+// wordBoundaryBefore mengembalikan akhir kata terakhir yang selesai sebelum limit,
+// supaya snippet tidak memotong kata di tengah (atau karakter multi-byte).
+func wordBoundaryBefore(tokens []token, limit int) int {
+	end := 0
+	for _, t := range tokens {
+		if t.end > limit {
+			break
+		}
+		end = t.end
+	}
+	return end
+}
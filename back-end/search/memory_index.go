@@ -0,0 +1,173 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MemoryIndex menyimpan index di memori dengan skor BM25 yang sama seperti
+// DBIndex. Dipakai untuk test dan development; isinya hilang saat proses berhenti.
+type MemoryIndex struct {
+	mu    sync.RWMutex
+	docs  map[string]map[string][]string // document ID -> field -> term
+	words map[string]time.Time           // kata -> terakhir terlihat saat indexing
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:  make(map[string]map[string][]string),
+		words: make(map[string]time.Time),
+	}
+}
+
+func (i *MemoryIndex) Index(doc Document) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.docs[doc.ID] = map[string][]string{
+		FieldName:        Analyze(doc.Name),
+		FieldDescription: Analyze(doc.Description),
+	}
+	now := time.Now()
+	for _, w := range vocabulary(doc.Name, doc.Description) {
+		i.words[w] = now
+	}
+	return nil
+}
+
+func (i *MemoryIndex) Delete(id string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.docs, id)
+	return nil
+}
+
+func (i *MemoryIndex) Count() (int64, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return int64(len(i.docs)), nil
+}
+
+func (i *MemoryIndex) Vocabulary(prefix string, limit int) ([]string, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	prefix = strings.ToLower(prefix)
+	var words []string
+	for w := range i.words {
+		if strings.HasPrefix(w, prefix) {
+			words = append(words, w)
+		}
+	}
+	sort.Strings(words)
+	if limit > 0 && len(words) > limit {
+		words = words[:limit]
+	}
+	return words, nil
+}
+
+func (i *MemoryIndex) FuzzyCandidates(first string, minLen, maxLen, limit int) ([]string, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	first = strings.ToLower(first)
+	var words []string
+	for w := range i.words {
+		n := utf8.RuneCountInString(w)
+		if strings.HasPrefix(w, first) && n >= minLen && (maxLen <= 0 || n <= maxLen) {
+			words = append(words, w)
+		}
+	}
+	sort.Slice(words, func(a, b int) bool {
+		if len(words[a]) != len(words[b]) {
+			return len(words[a]) < len(words[b])
+		}
+		return words[a] < words[b]
+	})
+	if limit > 0 && len(words) > limit {
+		words = words[:limit]
+	}
+	return words, nil
+}
+
+func (i *MemoryIndex) PruneVocabulary(seenBefore time.Time) (int64, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var pruned int64
+	for w, seen := range i.words {
+		if seen.Before(seenBefore) {
+			delete(i.words, w)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+func (i *MemoryIndex) Search(q Query) ([]Hit, error) {
+	terms := QueryTerms(q.Text)
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if len(terms) == 0 || len(i.docs) == 0 {
+		return nil, nil
+	}
+
+	avg := make(map[string]float64)
+	df := make(map[string]int64)
+	for _, fields := range i.docs {
+		for field, fieldTerms := range fields {
+			avg[field] += float64(len(fieldTerms))
+		}
+		for _, term := range terms {
+			if containsTerm(fields[FieldName], term) || containsTerm(fields[FieldDescription], term) {
+				df[term]++
+			}
+		}
+	}
+	total := int64(len(i.docs))
+	for field := range avg {
+		avg[field] = nonZero(avg[field] / float64(total))
+	}
+
+	var hits []Hit
+	for id, fields := range i.docs {
+		score := 0.0
+		for _, term := range terms {
+			if df[term] == 0 {
+				continue
+			}
+			for field, fieldTerms := range fields {
+				if freq := countTerm(fieldTerms, term); freq > 0 {
+					score += bm25(idf(total, df[term]), freq, len(fieldTerms), avg[field]) * fieldBoosts[field]
+				}
+			}
+		}
+		if score > 0 {
+			hits = append(hits, Hit{ID: id, Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].ID < hits[b].ID
+	})
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, nil
+}
+
+func containsTerm(terms []string, term string) bool {
+	return countTerm(terms, term) > 0
+}
+
+func countTerm(terms []string, term string) int {
+	n := 0
+	for _, t := range terms {
+		if t == term {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,129 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMemoryIndexRanking(t *testing.T) {
+	tests := []struct {
+		name  string
+		docs  []Document
+		query string
+		want  []string
+	}{
+		{
+			name: "name match beats description match",
+			docs: []Document{
+				{ID: "desc", Name: "Tas ransel", Description: "Cocok dipakai bersama sepatu"},
+				{ID: "name", Name: "Sepatu lari", Description: "Ringan dan nyaman"},
+			},
+			query: "sepatu",
+			want:  []string{"name", "desc"},
+		},
+		{
+			name: "more matched terms rank higher",
+			docs: []Document{
+				{ID: "one", Name: "Sepatu kulit"},
+				{ID: "two", Name: "Sepatu lari"},
+				{ID: "none", Name: "Kemeja batik"},
+			},
+			query: "sepatu lari",
+			want:  []string{"two", "one"},
+		},
+		{
+			name: "rarer term weighs more",
+			docs: []Document{
+				{ID: "common", Name: "Kaos hitam"},
+				{ID: "rare", Name: "Kaos polos"},
+				{ID: "other", Name: "Celana hitam"},
+				{ID: "other2", Name: "Topi hitam"},
+			},
+			query: "hitam polos",
+			want:  []string{"rare", "common", "other", "other2"},
+		},
+		{
+			name: "shorter field ranks higher for the same term",
+			docs: []Document{
+				{ID: "long", Name: "Botol minum stainless anti bocor ukuran besar"},
+				{ID: "short", Name: "Botol minum"},
+			},
+			query: "botol",
+			want:  []string{"short", "long"},
+		},
+		{
+			name: "stemmed query matches affixed words",
+			docs: []Document{
+				{ID: "toy", Name: "Mainan anak"},
+				{ID: "shirt", Name: "Kemeja anak"},
+			},
+			query: "bermain",
+			want:  []string{"toy"},
+		},
+		{
+			name:  "stopwords only",
+			docs:  []Document{{ID: "a", Name: "Sepatu dan tas"}},
+			query: "dan yang",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := NewMemoryIndex()
+			for _, doc := range tt.docs {
+				if err := idx.Index(doc); err != nil {
+					t.Fatalf("Index(%s): %v", doc.ID, err)
+				}
+			}
+
+			hits, err := idx.Search(Query{Text: tt.query})
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			var got []string
+			for _, h := range hits {
+				got = append(got, h.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryIndexLimitAndDelete(t *testing.T) {
+	idx := NewMemoryIndex()
+	for _, id := range []string{"a", "b", "c"} {
+		idx.Index(Document{ID: id, Name: "Sepatu " + id})
+	}
+
+	hits, _ := idx.Search(Query{Text: "sepatu"})
+	if len(hits) != 3 {
+		t.Fatalf("unlimited search returned %d hits, want 3", len(hits))
+	}
+	hits, _ = idx.Search(Query{Text: "sepatu", Limit: 2})
+	if len(hits) != 2 {
+		t.Fatalf("limited search returned %d hits, want 2", len(hits))
+	}
+
+	idx.Delete("a")
+	if n, _ := idx.Count(); n != 2 {
+		t.Errorf("Count after delete = %d, want 2", n)
+	}
+	hits, _ = idx.Search(Query{Text: "sepatu"})
+	for _, h := range hits {
+		if h.ID == "a" {
+			t.Error("deleted document still returned")
+		}
+	}
+}
+
+func TestIDF(t *testing.T) {
+	if idf(100, 1) <= idf(100, 50) {
+		t.Error("rare terms must have a higher idf than common terms")
+	}
+	if v := idf(10, 10); v <= 0 {
+		t.Errorf("idf of a term in every document = %v, want > 0", v)
+	}
+}
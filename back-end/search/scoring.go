@@ -0,0 +1,22 @@
+package search
+
+import "math"
+
+// idf memakai rumus BM25 (Robertson-Sparck Jones) yang selalu positif.
+func idf(total, df int64) float64 {
+	return math.Log(1 + (float64(total)-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// bm25 menghitung skor satu term di satu field. Rumusnya sama dengan ekspresi
+// SQL di DBIndex.Search.
+func bm25(idf float64, freq, fieldLength int, avgFieldLength float64) float64 {
+	tf := float64(freq)
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(fieldLength)/avgFieldLength))
+}
+
+func nonZero(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,89 @@
+// Package search menyediakan full-text search produk di balik interface Index,
+// sehingga implementasinya (inverted index di database, MySQL FULLTEXT, Bleve,
+// Elasticsearch) bisa diganti tanpa mengubah service.
+package search
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Field yang diindeks beserta bobotnya. Kecocokan di nama produk lebih relevan
+// daripada kecocokan di deskripsi.
+const (
+	FieldName        = "name"
+	FieldDescription = "description"
+)
+
+var fieldBoosts = map[string]float64{
+	FieldName:        3.0,
+	FieldDescription: 1.0,
+}
+
+type Document struct {
+	ID          string
+	Name        string
+	Description string
+}
+
+type Query struct {
+	Text string
+	// Limit <= 0 berarti semua dokumen yang cocok.
+	Limit int
+}
+
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+type Index interface {
+	Index(doc Document) error
+	Delete(id string) error
+	// Search mengembalikan dokumen yang cocok, diurutkan dari skor tertinggi.
+	Search(q Query) ([]Hit, error)
+	Count() (int64, error)
+	// Vocabulary mengembalikan kata asli yang diawali prefix, terurut alfabet.
+	Vocabulary(prefix string, limit int) ([]string, error)
+	// FuzzyCandidates mengembalikan kata yang diawali first dengan panjang minLen
+	// sampai maxLen (maxLen <= 0 berarti tanpa batas atas), terpendek lebih dulu.
+	FuzzyCandidates(first string, minLen, maxLen, limit int) ([]string, error)
+	// PruneVocabulary menghapus kata yang tidak terlihat lagi sejak seenBefore,
+	// dipanggil setelah reindex penuh yang dimulai pada waktu itu.
+	PruneVocabulary(seenBefore time.Time) (int64, error)
+}
+
+var (
+	defaultIndex Index
+	once         sync.Once
+)
+
+// Default mengembalikan index global. SEARCH_DRIVER: "db" (default) atau "memory"
+// untuk development tanpa database.
+func Default() Index {
+	once.Do(func() {
+		defaultIndex = newFromEnv()
+	})
+	return defaultIndex
+}
+
+// SetDefault mengganti index global, berguna untuk test atau wiring manual.
+func SetDefault(idx Index) {
+	once.Do(func() {})
+	defaultIndex = idx
+}
+
+func newFromEnv() Index {
+	switch driver := strings.ToLower(os.Getenv("SEARCH_DRIVER")); driver {
+	case "", "db":
+		return NewDBIndex(nil)
+	case "memory":
+		return NewMemoryIndex()
+	default:
+		log.Printf("Unknown SEARCH_DRIVER %q, falling back to db", driver)
+		return NewDBIndex(nil)
+	}
+}
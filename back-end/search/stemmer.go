@@ -0,0 +1,186 @@
+package search
+
+import "strings"
+
+// Stem menerapkan stemmer Bahasa Indonesia (varian ringan algoritma Tala), dan
+// stemmer sufiks bahasa Inggris hanya jika kata tidak berimbuhan Indonesia.
+// Keduanya sengaja konservatif: kata yang terlalu pendek dibiarkan apa adanya
+// supaya "baju" tidak menjadi "baj".
+func Stem(word string) string {
+	if protectedWords[word] || !isAlpha(word) {
+		return word
+	}
+	if stem := stemIndonesian(word); stem != word {
+		return stem
+	}
+	return stemEnglish(word)
+}
+
+// protectedWords berisi kata dasar yang kebetulan diawali imbuhan sehingga
+// akan rusak jika di-stem, misalnya "kemeja" -> "meja".
+var protectedWords = map[string]bool{
+	"kemeja": true, "kerudung": true, "keripik": true, "keramik": true, "kereta": true,
+	"keranjang": true, "kecap": true, "sepatu": true, "sepeda": true, "selimut": true,
+	"senter": true, "setrika": true, "selai": true, "sendok": true, "seprai": true,
+	"sejadah": true, "serum": true, "pensil": true, "permen": true, "perban": true,
+	"terpal": true, "termos": true, "teko": true, "dinding": true, "mesin": true,
+	"mentega": true, "merica": true, "beras": true, "bedak": true, "berlian": true,
+	"bantal": true, "celana": true, "pekan": true, "peniti": true, "tempat": true,
+}
+
+func isAlpha(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isVowel(b byte) bool {
+	return b == 'a' || b == 'e' || b == 'i' || b == 'o' || b == 'u'
+}
+
+// syllables memperkirakan jumlah suku kata dari jumlah vokal, seperti pada algoritma Tala.
+func syllables(word string) int {
+	count := 0
+	for i := 0; i < len(word); i++ {
+		if isVowel(word[i]) {
+			count++
+		}
+	}
+	return count
+}
+
+func stripSuffix(word string, suffixes ...string) (string, bool) {
+	for _, s := range suffixes {
+		if !strings.HasSuffix(word, s) {
+			continue
+		}
+		stem := word[:len(word)-len(s)]
+		// "pakai" dan "ramai" bukan bentuk berakhiran -i
+		if s == "i" && isVowel(stem[len(stem)-1]) {
+			continue
+		}
+		if syllables(stem) >= 2 {
+			return stem, true
+		}
+	}
+	return word, false
+}
+
+func stemIndonesian(word string) string {
+	if syllables(word) <= 2 {
+		return word
+	}
+
+	word, _ = stripSuffix(word, "kah", "lah", "tah", "pun")
+	word, _ = stripSuffix(word, "nya", "ku", "mu")
+	if protectedWords[word] {
+		return word
+	}
+
+	if stem, ok := stripFirstOrderPrefix(word); ok {
+		word = stem
+		if stem, ok := stripSuffix(word, "kan", "an", "i"); ok {
+			word = stem
+			word, _ = stripSecondOrderPrefix(word)
+		}
+		return word
+	}
+
+	if stem, ok := stripSecondOrderPrefix(word); ok {
+		word = stem
+	}
+	word, _ = stripSuffix(word, "kan", "an", "i")
+	return word
+}
+
+// Prefiks urutan pertama beserta penggantinya jika huruf awal kata dasar luluh,
+// misalnya "menyapu" -> "sapu" dan "memakai" -> "pakai".
+// minStem menaikkan panjang minimal kata dasar untuk prefiks yang sering muncul
+// sebagai awal kata biasa: tanpa itu typo "sepato" menjadi "pato".
+var firstOrderPrefixes = []struct {
+	prefix      string
+	replacement string
+	beforeVowel bool
+	minStem     int
+}{
+	{"meng", "", false, 0},
+	{"meny", "s", true, 0},
+	{"men", "", false, 0},
+	{"mem", "p", true, 0},
+	{"mem", "", false, 0},
+	{"me", "", false, 0},
+	{"peng", "", false, 0},
+	{"peny", "s", true, 0},
+	{"pen", "", false, 0},
+	{"pem", "p", true, 0},
+	{"pem", "", false, 0},
+	{"di", "", false, 0},
+	{"ter", "", false, 0},
+	{"ke", "", false, 5},
+	{"se", "", false, 5},
+}
+
+func stripFirstOrderPrefix(word string) (string, bool) {
+	for _, p := range firstOrderPrefixes {
+		if !strings.HasPrefix(word, p.prefix) {
+			continue
+		}
+		rest := word[len(p.prefix):]
+		if rest == "" || p.beforeVowel != isVowel(rest[0]) && p.replacement != "" {
+			continue
+		}
+		stem := p.replacement + rest
+		if syllables(stem) >= 2 && len(stem) >= max(p.minStem, 4) {
+			return stem, true
+		}
+	}
+	return word, false
+}
+
+func stripSecondOrderPrefix(word string) (string, bool) {
+	for _, prefix := range []string{"ber", "bel", "per", "pel", "be", "pe"} {
+		if !strings.HasPrefix(word, prefix) {
+			continue
+		}
+		stem := word[len(prefix):]
+		if syllables(stem) >= 2 && len(stem) >= 4 {
+			return stem, true
+		}
+	}
+	return word, false
+}
+
+func stemEnglish(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ing") && len(word) >= 7:
+		return undouble(word[:len(word)-3])
+	case strings.HasSuffix(word, "ed") && len(word) >= 5:
+		return undouble(word[:len(word)-2])
+	case strings.HasSuffix(word, "ly") && len(word) >= 5:
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// undouble mengubah "runn" (dari "running") menjadi "run".
+func undouble(word string) string {
+	n := len(word)
+	if n >= 3 && word[n-1] == word[n-2] && !isVowel(word[n-1]) && !strings.ContainsRune("lsz", rune(word[n-1])) {
+		return word[:n-1]
+	}
+	return word
+}
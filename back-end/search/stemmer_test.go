@@ -0,0 +1,50 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		// Imbuhan Indonesia
+		{"memakai", "pakai"},
+		{"menyapu", "sapu"},
+		{"pembersih", "bersih"},
+		{"bermain", "main"},
+		{"mainan", "main"},
+		{"dibelikan", "beli"},
+		{"bajunya", "baju"},
+		{"celananya", "celana"},
+		{"sepasang", "pasang"},
+		{"seharga", "harga"},
+		{"penggaris", "garis"},
+
+		// Kata dasar yang tidak boleh rusak
+		{"baju", "baju"},
+		{"sepatu", "sepatu"},
+		{"kemeja", "kemeja"},
+		{"kebaya", "kebaya"},
+		// Akar pendek tidak dipotong dari prefiks "se"
+		{"sepato", "sepato"},
+		{"selada", "selada"},
+
+		// Bahasa Inggris
+		{"running", "run"},
+		{"shoes", "shoe"},
+		{"boxes", "box"},
+		{"batteries", "battery"},
+		{"stopped", "stop"},
+		{"quickly", "quick"},
+		{"glass", "glass"},
+
+		// Selain huruf a-z tidak di-stem
+		{"usb3", "usb3"},
+	}
+
+	for _, tt := range tests {
+		if got := Stem(tt.word); got != tt.want {
+			t.Errorf("Stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
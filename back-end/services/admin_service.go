@@ -30,7 +30,15 @@ func ToggleUserActiveStatus(id string, isActive bool) error {
 		return errors.New("user not found")
 	}
 
-	return config.DB.Model(&user).Update("is_active", isActive).Error
+	if err := config.DB.Model(&user).Update("is_active", isActive).Error; err != nil {
+		return err
+	}
+
+	// User yang dinonaktifkan harus langsung kehilangan semua sesinya
+	if !isActive {
+		return RevokeAllUserSessions(user.ID)
+	}
+	return nil
 }
 
 func GetUserListByID(id string) (*models.User, error) {
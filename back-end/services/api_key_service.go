@@ -0,0 +1,154 @@
+package services
+
+import (
+	"crypto/subtle"
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Format key: ek_<prefix>_<secret>. Prefix dipakai untuk mencari record,
+// secret dibandingkan lewat hash.
+const apiKeyPrefix = "ek_"
+
+var (
+	ErrInvalidAPIKey        = errors.New("invalid api key")
+	ErrInvalidAPIKeyScope   = errors.New("invalid api key scope")
+	ErrAPIKeyRoleNotAllowed = errors.New("only sellers can create api keys")
+)
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+func CreateAPIKey(userID, name string, scopes []string, expiresAt *time.Time) (string, *models.APIKey, error) {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return "", nil, errors.New("user not found")
+	}
+	if user.Role != "seller" {
+		return "", nil, ErrAPIKeyRoleNotAllowed
+	}
+
+	normalized, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	if expiresAt != nil && expiresAt.Before(time.Now()) {
+		return "", nil, errors.New("expiry must be in the future")
+	}
+
+	prefixToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	prefix := strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(prefixToken))[:12]
+
+	key := models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   utils.HashToken(secret),
+		Scopes:    strings.Join(normalized, " "),
+		ExpiresAt: expiresAt,
+		ScopeList: normalized,
+	}
+	if err := config.DB.Create(&key).Error; err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("%s%s_%s", apiKeyPrefix, prefix, secret), &key, nil
+}
+
+func GetAPIKeys(userID string) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := config.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func RevokeAPIKey(userID, keyID string) error {
+	result := config.DB.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", keyID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("api key not found")
+	}
+	return nil
+}
+
+// AuthenticateAPIKey memvalidasi key mentah dan mengembalikan pemiliknya.
+// Hanya seller aktif yang boleh memakai API key.
+func AuthenticateAPIKey(rawKey, ip string) (*models.APIKey, *models.User, error) {
+	rest := strings.TrimPrefix(rawKey, apiKeyPrefix)
+	parts := strings.SplitN(rest, "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var key models.APIKey
+	if err := config.DB.First(&key, "prefix = ?", parts[0]).Error; err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(utils.HashToken(parts[1]))) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt)) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ? AND is_active = ?", key.UserID, true).Error; err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if user.Role != "seller" {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > time.Minute || key.LastUsedIP != ip {
+		now := time.Now()
+		config.DB.Model(&key).UpdateColumns(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		})
+	}
+
+	return &key, &user, nil
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidAPIKeyScope
+	}
+
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		valid := false
+		for _, allowed := range models.ValidAPIKeyScopes {
+			if scope == allowed {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAPIKeyScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
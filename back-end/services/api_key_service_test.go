@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ecommerce-backend/models"
+	"ecommerce-backend/testutil"
+	"ecommerce-backend/utils"
+)
+
+// apiKeyStore menyimulasikan satu baris api_keys beserta pemiliknya.
+type apiKeyStore struct {
+	prefix     string
+	secret     string
+	scopes     string
+	expiresAt  *time.Time
+	revokedAt  *time.Time
+	userRole   string
+	userActive bool
+	lastUsedIP string
+}
+
+func newAPIKeyStore() *apiKeyStore {
+	return &apiKeyStore{
+		prefix:     "abc123def456",
+		secret:     "s3cr3t-value",
+		scopes:     models.ScopeProductsWrite + " " + models.ScopeOrdersRead,
+		userRole:   "seller",
+		userActive: true,
+	}
+}
+
+func (s *apiKeyStore) rawKey() string {
+	return "ek_" + s.prefix + "_" + s.secret
+}
+
+func (s *apiKeyStore) fakeDB() *testutil.FakeDB {
+	return &testutil.FakeDB{
+		Query: func(query string, args []interface{}) ([]string, [][]interface{}, error) {
+			switch {
+			case testutil.IsStatement(query, "SELECT * FROM `api_keys`"):
+				if args[0] != s.prefix {
+					return []string{"id"}, nil, nil
+				}
+				return []string{"id", "user_id", "prefix", "key_hash", "scopes", "expires_at", "revoked_at"},
+					[][]interface{}{{"key-1", "user-1", s.prefix, utils.HashToken(s.secret), s.scopes, timeValue(s.expiresAt), timeValue(s.revokedAt)}}, nil
+			case testutil.IsStatement(query, "SELECT * FROM `users`"):
+				// AuthenticateAPIKey hanya mencari user aktif
+				if args[0] != "user-1" || (len(args) > 1 && args[1] == true && !s.userActive) {
+					return []string{"id"}, nil, nil
+				}
+				return []string{"id", "role", "is_active"}, [][]interface{}{{"user-1", s.userRole, s.userActive}}, nil
+			}
+			return nil, nil, testutil.ErrUnexpectedQuery
+		},
+		Exec: func(query string, args []interface{}) (int64, error) {
+			switch {
+			case testutil.IsStatement(query, "UPDATE `api_keys` SET `last_used_at`=?,`last_used_ip`=?"):
+				s.lastUsedIP = args[1].(string)
+				return 1, nil
+			case testutil.IsStatement(query, "INSERT INTO `api_keys`"):
+				return 1, nil
+			}
+			return 0, testutil.ErrUnexpectedQuery
+		},
+	}
+}
+
+func timeValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func TestIsAPIKey(t *testing.T) {
+	for token, want := range map[string]bool{
+		"ek_abc_def":      true,
+		"ek_":             true,
+		"eyJhbGciOi.x.y":  false,
+		"EK_abc_def":      false,
+		"":                false,
+		" ek_abc_def":     false,
+		"Bearer ek_a_b":   false,
+		"sk_live_abc_def": false,
+	} {
+		if got := IsAPIKey(token); got != want {
+			t.Errorf("IsAPIKey(%q) = %v, want %v", token, got, want)
+		}
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		modify  func(s *apiKeyStore)
+		rawKey  func(s *apiKeyStore) string
+		wantErr bool
+	}{
+		{name: "valid key"},
+		{name: "valid key with future expiry", modify: func(s *apiKeyStore) { s.expiresAt = &future }},
+		{name: "wrong secret", rawKey: func(s *apiKeyStore) string { return "ek_" + s.prefix + "_wrong" }, wantErr: true},
+		{name: "unknown prefix", rawKey: func(s *apiKeyStore) string { return "ek_000000000000_" + s.secret }, wantErr: true},
+		{name: "missing secret", rawKey: func(s *apiKeyStore) string { return "ek_" + s.prefix + "_" }, wantErr: true},
+		{name: "missing separator", rawKey: func(s *apiKeyStore) string { return "ek_" + s.prefix + s.secret }, wantErr: true},
+		{name: "missing prefix", rawKey: func(s *apiKeyStore) string { return "ek__" + s.secret }, wantErr: true},
+		// Secret boleh berisi "_"; hanya pemisah pertama yang memisahkan prefix
+		{name: "secret containing underscore", modify: func(s *apiKeyStore) { s.secret = "part_one_two" }},
+		{name: "revoked key", modify: func(s *apiKeyStore) { s.revokedAt = &past }, wantErr: true},
+		{name: "expired key", modify: func(s *apiKeyStore) { s.expiresAt = &past }, wantErr: true},
+		{name: "inactive owner", modify: func(s *apiKeyStore) { s.userActive = false }, wantErr: true},
+		{name: "owner no longer seller", modify: func(s *apiKeyStore) { s.userRole = "buyer" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newAPIKeyStore()
+			if tt.modify != nil {
+				tt.modify(store)
+			}
+			testutil.UseFakeDB(t, store.fakeDB())
+			raw := store.rawKey()
+			if tt.rawKey != nil {
+				raw = tt.rawKey(store)
+			}
+
+			key, user, err := AuthenticateAPIKey(raw, "10.0.0.1")
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAPIKey) {
+					t.Errorf("err = %v, want ErrInvalidAPIKey", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if key.ID != "key-1" || user.ID != "user-1" {
+				t.Errorf("got key %s for user %s", key.ID, user.ID)
+			}
+			if store.lastUsedIP != "10.0.0.1" {
+				t.Errorf("last_used_ip = %q, want 10.0.0.1", store.lastUsedIP)
+			}
+		})
+	}
+}
+
+func TestCreateAPIKeyFormat(t *testing.T) {
+	store := newAPIKeyStore()
+	testutil.UseFakeDB(t, store.fakeDB())
+
+	raw, key, err := CreateAPIKey("user-1", "ERP", []string{models.ScopeOrdersRead, " " + models.ScopeOrdersRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest, ok := strings.CutPrefix(raw, "ek_")
+	if !ok || !IsAPIKey(raw) {
+		t.Fatalf("key %q does not start with ek_", raw)
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || prefix != key.Prefix || len(prefix) != 12 || strings.ContainsAny(prefix, "_-") {
+		t.Errorf("prefix %q does not match stored prefix %q", prefix, key.Prefix)
+	}
+	if key.KeyHash != utils.HashToken(secret) || strings.Contains(key.KeyHash, secret) {
+		t.Error("stored hash does not match the secret part of the key")
+	}
+	if key.Scopes != models.ScopeOrdersRead {
+		t.Errorf("scopes = %q, want %q", key.Scopes, models.ScopeOrdersRead)
+	}
+
+	store.userRole = "buyer"
+	if _, _, err := CreateAPIKey("user-1", "ERP", []string{models.ScopeOrdersRead}, nil); err != ErrAPIKeyRoleNotAllowed {
+		t.Errorf("buyer: err = %v, want ErrAPIKeyRoleNotAllowed", err)
+	}
+}
+
+func TestNormalizeScopes(t *testing.T) {
+	tests := []struct {
+		in      []string
+		want    string
+		wantErr bool
+	}{
+		{in: []string{models.ScopeProductsWrite}, want: models.ScopeProductsWrite},
+		{in: []string{" orders:read ", "orders:read", "orders:write"}, want: "orders:read orders:write"},
+		{in: nil, wantErr: true},
+		{in: []string{"admin"}, wantErr: true},
+		{in: []string{"orders:read", "products:*"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeScopes(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidAPIKeyScope) {
+				t.Errorf("normalizeScopes(%q) err = %v, want ErrInvalidAPIKeyScope", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || strings.Join(got, " ") != tt.want {
+			t.Errorf("normalizeScopes(%q) = %v, %v; want %s", tt.in, got, err, tt.want)
+		}
+	}
+}
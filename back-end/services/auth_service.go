@@ -0,0 +1,266 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    string
+	ExpiresIn    time.Duration
+}
+
+// CreateSession mencatat sesi baru untuk perangkat yang baru saja login,
+// lalu menerbitkan pasangan access token dan refresh token pertamanya.
+func CreateSession(user *models.User, lc LoginContext) (*AuthTokens, error) {
+	now := time.Now()
+	session := models.Session{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		UserAgent:  truncate(lc.UserAgent, 255),
+		IPAddress:  lc.IPAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(utils.RefreshTokenTTL()),
+	}
+
+	var rawRefresh string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		var err error
+		_, rawRefresh, err = issueRefreshToken(tx, user.ID, session.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Role, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: rawRefresh,
+		SessionID:    session.ID,
+		ExpiresIn:    utils.AccessTokenTTL(),
+	}, nil
+}
+
+// RotateRefreshToken menukar refresh token dengan pasangan token baru.
+// Refresh token yang sudah pernah dipakai dianggap bocor, sehingga seluruh
+// family-nya dicabut.
+func RotateRefreshToken(rawToken string) (*AuthTokens, error) {
+	var tokens *AuthTokens
+	var reused bool
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		// Row lock supaya dua refresh paralel dengan token yang sama tidak
+		// sama-sama lolos pengecekan UsedAt.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(rawToken)).
+			First(&current).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		if current.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		if current.UsedAt != nil {
+			reused = true
+			return ErrRefreshTokenReused
+		}
+
+		var user models.User
+		if err := tx.First(&user, "id = ? AND is_active = ?", current.UserID, true).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		next, rawRefresh, err := issueRefreshToken(tx, user.ID, current.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"used_at":        now,
+			"replaced_by_id": next.ID,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Session{}).Where("id = ?", current.FamilyID).Updates(map[string]interface{}{
+			"last_seen_at": now,
+			"expires_at":   next.ExpiresAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		accessToken, err := utils.GenerateToken(user.ID, user.Role, current.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		tokens = &AuthTokens{
+			AccessToken:  accessToken,
+			RefreshToken: rawRefresh,
+			SessionID:    current.FamilyID,
+			ExpiresIn:    utils.AccessTokenTTL(),
+		}
+		return nil
+	})
+
+	if reused {
+		var current models.RefreshToken
+		if err := config.DB.Where("token_hash = ?", utils.HashToken(rawToken)).First(&current).Error; err == nil {
+			log.Printf("Refresh token reuse detected for user %s, revoking family %s", current.UserID, current.FamilyID)
+			recordSecurityEvent(models.SecurityEventRefreshTokenReused, current.UserID, LoginContext{}, "token family "+current.FamilyID+" revoked")
+			if err := RevokeSession(current.FamilyID); err != nil {
+				log.Printf("Failed to revoke token family %s: %v", current.FamilyID, err)
+			}
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RevokeRefreshToken mencabut sesi dari refresh token yang diberikan (dipakai saat logout).
+func RevokeRefreshToken(rawToken string) error {
+	var token models.RefreshToken
+	if err := config.DB.Where("token_hash = ?", utils.HashToken(rawToken)).First(&token).Error; err != nil {
+		return ErrInvalidRefreshToken
+	}
+	return RevokeSession(token.FamilyID)
+}
+
+// RevokeSession mencabut satu sesi beserta seluruh refresh token family-nya.
+func RevokeSession(sessionID string) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.Session{}).
+			Where("id = ? AND revoked_at IS NULL", sessionID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", sessionID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeAllUserSessions mencabut semua sesi milik user, misalnya saat akun
+// dinonaktifkan atau password di-reset.
+func RevokeAllUserSessions(userID string) error {
+	return revokeUserSessions(userID, "")
+}
+
+// RevokeOtherUserSessions mencabut semua sesi user kecuali sesi yang sedang dipakai.
+func RevokeOtherUserSessions(userID, keepSessionID string) error {
+	return revokeUserSessions(userID, keepSessionID)
+}
+
+func revokeUserSessions(userID, keepSessionID string) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		sessions := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		tokens := tx.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if keepSessionID != "" {
+			sessions = sessions.Where("id <> ?", keepSessionID)
+			tokens = tokens.Where("family_id <> ?", keepSessionID)
+		}
+
+		if err := sessions.Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tokens.Update("revoked_at", now).Error
+	})
+}
+
+// ValidateSession dipakai AuthMiddleware untuk menolak access token dari sesi
+// yang sudah di-logout atau dicabut, walaupun token itu sendiri belum kedaluwarsa.
+// LastSeenAt ikut diperbarui, paling sering sekali per menit.
+func ValidateSession(sessionID, userID string) bool {
+	var session models.Session
+	if err := config.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return false
+	}
+	if session.UserID != userID || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return false
+	}
+
+	if time.Since(session.LastSeenAt) > time.Minute {
+		config.DB.Model(&session).UpdateColumn("last_seen_at", time.Now())
+	}
+	return true
+}
+
+func GetActiveSessions(userID string) ([]models.Session, error) {
+	var sessions []models.Session
+	err := config.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// RevokeUserSession dipakai user untuk keluar dari perangkat lain miliknya sendiri.
+func RevokeUserSession(userID, sessionID string) error {
+	var session models.Session
+	if err := config.DB.First(&session, "id = ? AND user_id = ?", sessionID, userID).Error; err != nil {
+		return errors.New("session not found")
+	}
+	return RevokeSession(session.ID)
+}
+
+func issueRefreshToken(tx *gorm.DB, userID, familyID string) (*models.RefreshToken, string, error) {
+	raw, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(raw),
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL()),
+	}
+	if err := tx.Create(&token).Error; err != nil {
+		return nil, "", err
+	}
+	return &token, raw, nil
+}
+
+// truncate memotong s menjadi paling banyak max karakter tanpa memecah
+// karakter multi-byte (kolom varchar menghitung karakter, bukan byte).
+func truncate(s string, max int) string {
+	count := 0
+	for i := range s {
+		if count == max {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
@@ -9,23 +9,39 @@ import (
 	"github.com/google/uuid"
 )
 
-func AddToCart(userID, productID string, quantity int) (*models.CartItem, error) {
+func AddToCart(userID, productID string, variantID *string, quantity int) (*models.CartItem, error) {
 	var product models.Product
 	if err := config.DB.First(&product, "id = ?", productID).Error; err != nil {
 		return nil, errors.New("product not found")
 	}
+	if err := requirePublished(&product); err != nil {
+		return nil, err
+	}
+
+	variant, err := resolveVariant(config.DB, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Varian berbeda dari produk yang sama disimpan sebagai item keranjang terpisah
+	query := config.DB.Where("user_id = ? AND product_id = ?", userID, productID)
+	if variant != nil {
+		variantID = &variant.ID
+		query = query.Where("variant_id = ?", variant.ID)
+	} else {
+		variantID = nil
+		query = query.Where("variant_id IS NULL")
+	}
 
 	var existingCartItem models.CartItem
-	if err := config.DB.
-		Where("user_id = ? AND product_id = ?", userID, productID).
-		First(&existingCartItem).Error; err == nil {
+	if err := query.First(&existingCartItem).Error; err == nil {
 
 		existingCartItem.Quantity += quantity
 		if err := config.DB.Save(&existingCartItem).Error; err != nil {
 			return nil, err
 		}
 
-		if err := config.DB.Preload("Product").First(&existingCartItem, "id = ?", existingCartItem.ID).Error; err != nil {
+		if err := config.DB.Preload("Product").Preload("Variant").First(&existingCartItem, "id = ?", existingCartItem.ID).Error; err != nil {
 			return nil, err
 		}
 
@@ -36,13 +52,14 @@ func AddToCart(userID, productID string, quantity int) (*models.CartItem, error)
 		ID:        uuid.New().String(),
 		UserID:    userID,
 		ProductID: productID,
+		VariantID: variantID,
 		Quantity:  quantity,
 	}
 	if err := config.DB.Create(&cartItem).Error; err != nil {
 		return nil, err
 	}
 
-	if err := config.DB.Preload("Product").First(&cartItem, "id = ?", cartItem.ID).Error; err != nil {
+	if err := config.DB.Preload("Product").Preload("Variant").First(&cartItem, "id = ?", cartItem.ID).Error; err != nil {
 		return nil, err
 	}
 
@@ -51,16 +68,25 @@ func AddToCart(userID, productID string, quantity int) (*models.CartItem, error)
 
 func GetCartByUser(userID string) ([]models.CartItem, error) {
 	var cartItems []models.CartItem
-	if err := config.DB.Preload("Product").Where("user_id = ?", userID).Find(&cartItems).Error; err != nil {
+	if err := config.DB.Preload("Product").Preload("Variant").Where("user_id = ?", userID).Find(&cartItems).Error; err != nil {
 		return nil, err
 	}
 	return cartItems, nil
 }
 
+func GetCartItemByID(cartItemID string) (*models.CartItem, error) {
+	var cartItem models.CartItem
+	if err := config.DB.First(&cartItem, "id = ?", cartItemID).Error; err != nil {
+		return nil, errors.New("cart item not found")
+	}
+	return &cartItem, nil
+}
+
 func UpdateCartItem(cartItemID string, newQuantity int) (*models.CartItem, error) {
 	var cartItem models.CartItem
 	if err := config.DB.
 		Preload("Product").
+		Preload("Variant").
 		First(&cartItem, "id = ?", cartItemID).Error; err != nil {
 		return nil, errors.New("cart item not found")
 	}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/mailer"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrVerificationThrottled    = errors.New("verification email was sent recently, please try again later")
+)
+
+func verificationTokenTTL() time.Duration {
+	return utils.GetEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+}
+
+func verificationResendInterval() time.Duration {
+	return utils.GetEnvDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute)
+}
+
+// FrontendURL adalah base URL yang dipakai untuk link di dalam email.
+func FrontendURL() string {
+	if base := os.Getenv("FRONTEND_URL"); base != "" {
+		return base
+	}
+	return "http://localhost:3000"
+}
+
+// SendVerificationEmail membuat token verifikasi baru dan mengirimkannya ke email user.
+// Pengiriman ulang dibatasi oleh EMAIL_VERIFICATION_RESEND_INTERVAL.
+func SendVerificationEmail(user *models.User) error {
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	var last models.EmailVerificationToken
+	err := config.DB.Where("user_id = ?", user.ID).Order("created_at DESC").First(&last).Error
+	if err == nil && time.Since(last.CreatedAt) < verificationResendInterval() {
+		return ErrVerificationThrottled
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	rawToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	token := models.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(verificationTokenTTL()),
+	}
+	if err := config.DB.Create(&token).Error; err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", FrontendURL(), url.QueryEscape(rawToken))
+	return mailer.Default().Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email Anda",
+		Body: fmt.Sprintf("Halo %s,\n\nSilakan verifikasi email Anda dengan membuka link berikut:\n%s\n\nLink ini berlaku selama %s.",
+			user.Name, link, verificationTokenTTL()),
+	})
+}
+
+// ResendVerificationEmail dipakai endpoint publik. Email yang tidak terdaftar,
+// sudah terverifikasi atau masih dalam jeda kirim ulang sengaja tidak dibedakan
+// agar tidak membocorkan akun mana yang ada; hanya kegagalan kirim yang
+// dikembalikan untuk dicatat di log.
+func ResendVerificationEmail(email string) error {
+	var user models.User
+	if err := config.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	if err := SendVerificationEmail(&user); err != nil && !errors.Is(err, ErrVerificationThrottled) {
+		return err
+	}
+	return nil
+}
+
+func VerifyEmail(rawToken string) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var token models.EmailVerificationToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(rawToken)).
+			First(&token).Error; err != nil {
+			return ErrInvalidVerificationToken
+		}
+
+		if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+			return ErrInvalidVerificationToken
+		}
+
+		now := time.Now()
+		if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", token.UserID).Updates(map[string]interface{}{
+			"email_verified":    true,
+			"email_verified_at": now,
+		}).Error
+	})
+}
+
+func IsEmailVerified(userID string) bool {
+	var user models.User
+	if err := config.DB.Select("email_verified").First(&user, "id = ?", userID).Error; err != nil {
+		return false
+	}
+	return user.EmailVerified
+}
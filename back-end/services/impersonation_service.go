@@ -0,0 +1,139 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var (
+	ErrImpersonationNotAllowed  = errors.New("this user cannot be impersonated")
+	ErrImpersonationNotFound    = errors.New("impersonation session not found")
+	ErrImpersonationReasonEmpty = errors.New("a reason is required to impersonate a user")
+)
+
+type ImpersonationResult struct {
+	Session   *models.ImpersonationSession
+	Token     string
+	ExpiresIn int64
+}
+
+// StartImpersonation menerbitkan access token atas nama targetUserID untuk admin.
+// Token tidak punya refresh token dan berhenti berlaku setelah ImpersonationTTL.
+func StartImpersonation(adminID, targetUserID, reason string, lc LoginContext) (*ImpersonationResult, error) {
+	if reason == "" {
+		return nil, ErrImpersonationReasonEmpty
+	}
+	if adminID == targetUserID {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	var target models.User
+	if err := config.DB.First(&target, "id = ?", targetUserID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	// Admin lain dan akun nonaktif tidak boleh di-impersonate
+	if target.Role == "admin" || !target.IsActive {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	ttl := utils.ImpersonationTTL()
+	session := models.ImpersonationSession{
+		AdminID:      adminID,
+		TargetUserID: target.ID,
+		Reason:       reason,
+		IPAddress:    lc.IPAddress,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+	if err := config.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateImpersonationToken(target.ID, target.Role, session.ID, adminID, session.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	recordSecurityEvent(models.SecurityEventImpersonationStart, target.ID, lc,
+		fmt.Sprintf("admin %s started impersonation %s: %s", adminID, session.ID, reason))
+
+	return &ImpersonationResult{
+		Session:   &session,
+		Token:     token,
+		ExpiresIn: int64(ttl.Seconds()),
+	}, nil
+}
+
+// ValidateImpersonation dipakai AuthMiddleware sebagai pengganti ValidateSession
+// untuk token impersonation. Admin yang dinonaktifkan atau diturunkan role-nya
+// langsung kehilangan akses.
+func ValidateImpersonation(impersonationID, adminID, userID string) bool {
+	var session models.ImpersonationSession
+	if err := config.DB.First(&session, "id = ?", impersonationID).Error; err != nil {
+		return false
+	}
+	if session.AdminID != adminID || session.TargetUserID != userID ||
+		session.EndedAt != nil || time.Now().After(session.ExpiresAt) {
+		return false
+	}
+
+	var admin models.User
+	if err := config.DB.First(&admin, "id = ?", adminID).Error; err != nil {
+		return false
+	}
+	return admin.Role == "admin" && admin.IsActive
+}
+
+func EndImpersonation(impersonationID, adminID string) error {
+	result := config.DB.Model(&models.ImpersonationSession{}).
+		Where("id = ? AND admin_id = ? AND ended_at IS NULL", impersonationID, adminID).
+		Update("ended_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrImpersonationNotFound
+	}
+
+	recordSecurityEvent(models.SecurityEventImpersonationEnd, "", LoginContext{},
+		fmt.Sprintf("admin %s ended impersonation %s", adminID, impersonationID))
+	return nil
+}
+
+func RecordImpersonatedRequest(entry models.ImpersonationAuditLog) {
+	entry.Path = truncate(entry.Path, 255)
+	entry.UserAgent = truncate(entry.UserAgent, 255)
+	if err := config.DB.Create(&entry).Error; err != nil {
+		log.Printf("Failed to write impersonation audit log for %s: %v", entry.ImpersonationID, err)
+	}
+}
+
+func GetImpersonationSessions(adminID string, limit int) ([]models.ImpersonationSession, error) {
+	query := config.DB.Preload("Admin").Preload("TargetUser").Order("created_at DESC")
+	if adminID != "" {
+		query = query.Where("admin_id = ?", adminID)
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	var sessions []models.ImpersonationSession
+	if err := query.Limit(limit).Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func GetImpersonationAuditLog(impersonationID string) ([]models.ImpersonationAuditLog, error) {
+	var entries []models.ImpersonationAuditLog
+	if err := config.DB.Where("impersonation_id = ?", impersonationID).
+		Order("created_at ASC").
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
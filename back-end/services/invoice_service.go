@@ -0,0 +1,120 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+type InvoiceLine struct {
+	ProductName    string            `json:"product_name"`
+	SKU            string            `json:"sku,omitempty"`
+	VariantOptions map[string]string `json:"variant_options,omitempty"`
+	SellerName     string            `json:"seller_name"`
+	Quantity       int               `json:"quantity"`
+	UnitPrice      float64           `json:"unit_price"`
+	Total          float64           `json:"total"`
+	Status         string            `json:"status"`
+}
+
+// Invoice dibangun dari snapshot order item, sehingga isinya tetap sama
+// walaupun produk sudah diubah atau diarsipkan.
+type Invoice struct {
+	Number      string        `json:"number"`
+	OrderID     string        `json:"order_id"`
+	IssuedAt    time.Time     `json:"issued_at"`
+	OrderStatus string        `json:"order_status"`
+	BuyerName   string        `json:"buyer_name"`
+	BuyerEmail  string        `json:"buyer_email"`
+	SellerName  string        `json:"seller_name,omitempty"`
+	Lines       []InvoiceLine `json:"lines"`
+	Total       float64       `json:"total"`
+}
+
+// GetOrderInvoice mengembalikan invoice seluruh order beserta order-nya untuk
+// pemeriksaan kepemilikan di controller.
+func GetOrderInvoice(orderID string) (*Invoice, *models.Order, error) {
+	order, items, err := loadInvoiceOrder(orderID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return buildInvoice(order, items, ""), order, nil
+}
+
+// GetSellerInvoice mengembalikan invoice yang hanya berisi item milik seller
+// dari sebuah order.
+func GetSellerInvoice(orderID, sellerID string) (*Invoice, error) {
+	order, items, err := loadInvoiceOrder(orderID, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrInvoiceNotFound
+	}
+	return buildInvoice(order, items, items[0].SellerName), nil
+}
+
+func loadInvoiceOrder(orderID, sellerID string) (*models.Order, []models.OrderItem, error) {
+	var order models.Order
+	err := config.DB.Preload("User").First(&order, "id = ?", orderID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := config.DB.Where("order_id = ?", orderID)
+	if sellerID != "" {
+		query = query.Where("seller_id = ?", sellerID)
+	}
+	var items []models.OrderItem
+	if err := query.Order("seller_name, product_name").Find(&items).Error; err != nil {
+		return nil, nil, err
+	}
+	return &order, items, nil
+}
+
+func buildInvoice(order *models.Order, items []models.OrderItem, sellerName string) *Invoice {
+	invoice := &Invoice{
+		Number:      invoiceNumber(order),
+		OrderID:     order.ID,
+		IssuedAt:    order.CreatedAt,
+		OrderStatus: order.Status,
+		SellerName:  sellerName,
+		Lines:       make([]InvoiceLine, 0, len(items)),
+	}
+	if order.User != nil {
+		invoice.BuyerName = order.User.Name
+		invoice.BuyerEmail = order.User.Email
+	}
+	for _, item := range items {
+		invoice.Lines = append(invoice.Lines, InvoiceLine{
+			ProductName:    item.ProductName,
+			SKU:            item.SKU,
+			VariantOptions: item.VariantOptions,
+			SellerName:     item.SellerName,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			Total:          item.Price,
+			Status:         item.Status,
+		})
+		invoice.Total += item.Price
+	}
+	return invoice
+}
+
+// Nomor invoice diturunkan dari tanggal dan ID order supaya stabil tanpa tabel tambahan.
+func invoiceNumber(order *models.Order) string {
+	short := strings.ToUpper(strings.ReplaceAll(order.ID, "-", ""))
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	return "INV-" + order.CreatedAt.Format("20060102") + "-" + short
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginContext berisi informasi client yang dicatat bersama event keamanan.
+type LoginContext struct {
+	IPAddress string
+	UserAgent string
+}
+
+type loginLimit struct {
+	maxAttempts int
+	baseLockout time.Duration
+	maxLockout  time.Duration
+}
+
+func accountLoginLimit() loginLimit {
+	return loginLimit{
+		maxAttempts: utils.GetEnvInt("LOGIN_MAX_ATTEMPTS", 5),
+		baseLockout: utils.GetEnvDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		maxLockout:  utils.GetEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+	}
+}
+
+// Batas per IP lebih longgar karena satu IP bisa dipakai banyak user (NAT, kantor).
+func ipLoginLimit() loginLimit {
+	return loginLimit{
+		maxAttempts: utils.GetEnvInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+		baseLockout: utils.GetEnvDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		maxLockout:  utils.GetEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+	}
+}
+
+// Satu challenge MFA hanya boleh ditebak beberapa kali; setelah itu dikunci
+// sepanjang umur challenge sehingga user harus login ulang dengan password.
+func mfaChallengeLimit() loginLimit {
+	return loginLimit{
+		maxAttempts: utils.GetEnvInt("MFA_CHALLENGE_MAX_ATTEMPTS", 5),
+		baseLockout: utils.MFAChallengeTTL(),
+		maxLockout:  utils.MFAChallengeTTL(),
+	}
+}
+
+// Percobaan gagal yang lebih lama dari window ini tidak lagi dihitung.
+func loginFailureWindow() time.Duration {
+	return utils.GetEnvDuration("LOGIN_FAILURE_WINDOW", 24*time.Hour)
+}
+
+func accountThrottleKey(userID string) string {
+	return "user:" + userID
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+func mfaChallengeThrottleKey(challengeID string) string {
+	return "mfa:" + challengeID
+}
+
+// LoginLockedError dikembalikan saat akun atau IP sedang dikunci.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// CheckIPAllowed dipanggil sebelum kredensial diperiksa.
+func CheckIPAllowed(ip string) error {
+	return checkThrottle(ipThrottleKey(ip))
+}
+
+// CheckAccountAllowed dipanggil setelah user ditemukan, sebelum password dibandingkan.
+func CheckAccountAllowed(userID string) error {
+	return checkThrottle(accountThrottleKey(userID))
+}
+
+// CheckMFAChallengeAllowed dipanggil sebelum kode 2FA dari challenge ini diperiksa.
+func CheckMFAChallengeAllowed(challengeID string) error {
+	return checkThrottle(mfaChallengeThrottleKey(challengeID))
+}
+
+func checkThrottle(key string) error {
+	var throttle models.LoginThrottle
+	if err := config.DB.First(&throttle, "throttle_key = ?", key).Error; err != nil {
+		return nil
+	}
+	if throttle.LockedUntil != nil && time.Now().Before(*throttle.LockedUntil) {
+		return &LoginLockedError{RetryAfter: time.Until(*throttle.LockedUntil)}
+	}
+	return nil
+}
+
+// RecordLoginFailure menambah hitungan gagal untuk IP dan (jika diketahui) akun.
+// Setelah melewati batas, durasi lock naik eksponensial: base, 2x base, 4x base, ...
+func RecordLoginFailure(userID, identifier string, lc LoginContext) {
+	recordSecurityEvent(models.SecurityEventLoginFailed, userID, lc, "identifier: "+identifier)
+
+	if lockedFor, locked := registerFailure(ipThrottleKey(lc.IPAddress), ipLoginLimit()); locked {
+		recordSecurityEvent(models.SecurityEventIPLocked, "", lc, fmt.Sprintf("locked for %s", lockedFor))
+	}
+
+	if userID != "" {
+		if lockedFor, locked := registerFailure(accountThrottleKey(userID), accountLoginLimit()); locked {
+			recordSecurityEvent(models.SecurityEventAccountLocked, userID, lc, fmt.Sprintf("locked for %s", lockedFor))
+		}
+	}
+}
+
+// RecordLoginSuccess mereset hitungan akun. Dipanggil hanya setelah autentikasi
+// lengkap (termasuk 2FA), supaya password yang benar tidak menghapus hitungan
+// tebakan kode 2FA. Hitungan IP tidak direset supaya satu akun valid tidak bisa
+// dipakai untuk menghapus jejak brute-force dari IP yang sama.
+func RecordLoginSuccess(userID string, lc LoginContext) {
+	var throttle models.LoginThrottle
+	key := accountThrottleKey(userID)
+	if err := config.DB.First(&throttle, "throttle_key = ?", key).Error; err != nil {
+		return
+	}
+
+	if throttle.FailedAttempts >= 3 {
+		recordSecurityEvent(models.SecurityEventSuspiciousLogin, userID, lc,
+			fmt.Sprintf("successful login after %d failed attempts", throttle.FailedAttempts))
+	}
+	config.DB.Delete(&throttle)
+}
+
+// RecordMFAFailure menghitung kode 2FA yang salah per akun, per IP dan per challenge.
+func RecordMFAFailure(userID, challengeID string, lc LoginContext) {
+	recordSecurityEvent(models.SecurityEventMFAFailed, userID, lc, "")
+	if lockedFor, locked := registerFailure(ipThrottleKey(lc.IPAddress), ipLoginLimit()); locked {
+		recordSecurityEvent(models.SecurityEventIPLocked, "", lc, fmt.Sprintf("locked for %s", lockedFor))
+	}
+	if challengeID != "" {
+		registerFailure(mfaChallengeThrottleKey(challengeID), mfaChallengeLimit())
+	}
+	if lockedFor, locked := registerFailure(accountThrottleKey(userID), accountLoginLimit()); locked {
+		recordSecurityEvent(models.SecurityEventAccountLocked, userID, lc, fmt.Sprintf("locked for %s", lockedFor))
+	}
+}
+
+func UnlockAccount(userID, adminID string) error {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := config.DB.Where("throttle_key = ?", accountThrottleKey(userID)).Delete(&models.LoginThrottle{}).Error; err != nil {
+		return err
+	}
+
+	recordSecurityEvent(models.SecurityEventAccountUnlocked, userID, LoginContext{}, "unlocked by admin "+adminID)
+	return nil
+}
+
+type SecurityEventFilter struct {
+	Type   string
+	UserID string
+	Limit  int
+}
+
+func GetSecurityEvents(filter SecurityEventFilter) ([]models.SecurityEvent, error) {
+	query := config.DB.Order("created_at DESC")
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+
+	var events []models.SecurityEvent
+	if err := query.Limit(filter.Limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func registerFailure(key string, limit loginLimit) (time.Duration, bool) {
+	var lockedFor time.Duration
+	var locked bool
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var throttle models.LoginThrottle
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&throttle, "throttle_key = ?", key).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			throttle = models.LoginThrottle{ThrottleKey: key}
+		} else if err != nil {
+			return err
+		}
+
+		if !throttle.LastFailedAt.IsZero() && now.Sub(throttle.LastFailedAt) > loginFailureWindow() {
+			throttle.FailedAttempts = 0
+		}
+		throttle.FailedAttempts++
+		throttle.LastFailedAt = now
+
+		if throttle.FailedAttempts >= limit.maxAttempts {
+			lockedFor = lockoutDuration(throttle.FailedAttempts-limit.maxAttempts, limit)
+			until := now.Add(lockedFor)
+			throttle.LockedUntil = &until
+			locked = true
+		}
+
+		return tx.Save(&throttle).Error
+	})
+	if err != nil {
+		log.Printf("Failed to record login failure for %s: %v", key, err)
+		return 0, false
+	}
+	return lockedFor, locked
+}
+
+func lockoutDuration(exponent int, limit loginLimit) time.Duration {
+	d := limit.baseLockout
+	for i := 0; i < exponent; i++ {
+		d *= 2
+		if d >= limit.maxLockout {
+			return limit.maxLockout
+		}
+	}
+	return d
+}
+
+func recordSecurityEvent(eventType, userID string, lc LoginContext, detail string) {
+	event := models.SecurityEvent{
+		Type:      eventType,
+		UserID:    userID,
+		IPAddress: lc.IPAddress,
+		UserAgent: truncate(lc.UserAgent, 255),
+		Detail:    detail,
+	}
+	if err := config.DB.Create(&event).Error; err != nil {
+		log.Printf("Failed to record security event %s: %v", eventType, err)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/mailer"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidMagicLink  = errors.New("invalid or expired login link")
+	ErrMagicLinkDisabled = errors.New("magic link login is disabled for this account")
+	ErrInvalidRole       = errors.New("invalid role")
+)
+
+func magicLinkTTL() time.Duration {
+	return utils.GetEnvDuration("MAGIC_LINK_TTL", 15*time.Minute)
+}
+
+func magicLinkResendInterval() time.Duration {
+	return utils.GetEnvDuration("MAGIC_LINK_RESEND_INTERVAL", time.Minute)
+}
+
+// IsMagicLinkEnabledForRole bernilai true jika admin belum membuat policy untuk role tersebut.
+func IsMagicLinkEnabledForRole(role string) bool {
+	var policy models.RoleAuthPolicy
+	if err := config.DB.First(&policy, "role = ?", role).Error; err != nil {
+		return true
+	}
+	return policy.MagicLinkEnabled
+}
+
+// RequestMagicLink mengirim link login sekali pakai. Seperti reset password, email yang
+// tidak terdaftar, akun nonaktif, atau role yang dimatikan tetap dianggap sukses
+// supaya endpoint tidak membocorkan akun mana yang ada.
+func RequestMagicLink(email string, lc LoginContext) error {
+	var user models.User
+	if err := config.DB.Where("email = ? AND is_active = ?", email, true).First(&user).Error; err != nil {
+		return nil
+	}
+	if !IsMagicLinkEnabledForRole(user.Role) {
+		return nil
+	}
+
+	var last models.MagicLinkToken
+	err := config.DB.Where("user_id = ?", user.ID).Order("created_at DESC").First(&last).Error
+	if err == nil && time.Since(last.CreatedAt) < magicLinkResendInterval() {
+		return nil
+	}
+
+	rawToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Hanya link terbaru yang berlaku
+		if err := tx.Model(&models.MagicLinkToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.MagicLinkToken{
+			UserID:    user.ID,
+			Email:     user.Email,
+			TokenHash: utils.HashToken(rawToken),
+			ExpiresAt: time.Now().Add(magicLinkTTL()),
+			IPAddress: lc.IPAddress,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/magic-link?token=%s", FrontendURL(), url.QueryEscape(rawToken))
+	return mailer.Default().Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Link masuk ke akun Anda",
+		Body: fmt.Sprintf("Halo %s,\n\nBuka link berikut untuk masuk tanpa password:\n%s\n\nLink ini berlaku selama %s dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak memintanya.",
+			user.Name, link, magicLinkTTL()),
+	})
+}
+
+// ConsumeMagicLink memakai token sekali pakai dan mengembalikan user yang login.
+// Karena link dikirim ke email, email user sekaligus dianggap terverifikasi, jadi
+// link ditolak jika user sudah mengganti email sejak link dikirim.
+func ConsumeMagicLink(rawToken string) (*models.User, error) {
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var token models.MagicLinkToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(rawToken)).
+			First(&token).Error; err != nil {
+			return ErrInvalidMagicLink
+		}
+		if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+			return ErrInvalidMagicLink
+		}
+
+		now := time.Now()
+		if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		if err := tx.First(&user, "id = ? AND is_active = ?", token.UserID, true).Error; err != nil {
+			return ErrInvalidMagicLink
+		}
+		if !strings.EqualFold(token.Email, user.Email) {
+			return ErrInvalidMagicLink
+		}
+		if !user.EmailVerified {
+			if err := tx.Model(&user).Updates(map[string]interface{}{
+				"email_verified":    true,
+				"email_verified_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			user.EmailVerified = true
+			user.EmailVerifiedAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Policy bisa berubah setelah link dikirim
+	if !IsMagicLinkEnabledForRole(user.Role) {
+		return nil, ErrMagicLinkDisabled
+	}
+	return &user, nil
+}
+
+func SetRoleMagicLinkEnabled(role string, enabled bool) (*models.RoleAuthPolicy, error) {
+	if role != "admin" && role != "seller" && role != "buyer" {
+		return nil, ErrInvalidRole
+	}
+
+	policy := models.RoleAuthPolicy{Role: role}
+	if err := config.DB.FirstOrCreate(&policy, "role = ?", role).Error; err != nil {
+		return nil, err
+	}
+	if err := config.DB.Model(&policy).Update("magic_link_enabled", enabled).Error; err != nil {
+		return nil, err
+	}
+	policy.MagicLinkEnabled = enabled
+	return &policy, nil
+}
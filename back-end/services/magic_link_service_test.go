@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ecommerce-backend/mailer"
+	"ecommerce-backend/models"
+	"ecommerce-backend/testutil"
+	"ecommerce-backend/utils"
+)
+
+type recordingMailer struct{ sent []mailer.Message }
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// magicLinkStore menyimulasikan satu magic link milik user-1.
+type magicLinkStore struct {
+	tokenEmail string
+	userEmail  string
+	used       bool
+	verified   bool
+}
+
+func (s *magicLinkStore) fakeDB() *testutil.FakeDB {
+	return &testutil.FakeDB{
+		Query: func(query string, args []interface{}) ([]string, [][]interface{}, error) {
+			switch {
+			case testutil.IsStatement(query, "SELECT * FROM `magic_link_tokens`"):
+				if args[0] != utils.HashToken("raw-token") {
+					return []string{"id"}, nil, nil
+				}
+				return []string{"id", "user_id", "email", "expires_at"},
+					[][]interface{}{{"link-1", "user-1", s.tokenEmail, time.Now().Add(time.Minute)}}, nil
+			case testutil.IsStatement(query, "SELECT * FROM `users`"):
+				return []string{"id", "email", "role", "is_active", "email_verified"},
+					[][]interface{}{{"user-1", s.userEmail, "buyer", true, false}}, nil
+			case testutil.IsStatement(query, "SELECT * FROM `role_auth_policies`"):
+				return []string{"role"}, nil, nil
+			}
+			return nil, nil, testutil.ErrUnexpectedQuery
+		},
+		Exec: func(query string, args []interface{}) (int64, error) {
+			switch {
+			case testutil.IsStatement(query, "UPDATE `magic_link_tokens` SET `used_at`=?"):
+				s.used = true
+				return 1, nil
+			case testutil.IsStatement(query, "UPDATE `users` SET `email_verified`=?"):
+				s.verified = true
+				return 1, nil
+			}
+			return 0, testutil.ErrUnexpectedQuery
+		},
+	}
+}
+
+func TestConsumeMagicLinkVerifiesEmail(t *testing.T) {
+	store := &magicLinkStore{tokenEmail: "buyer@example.com", userEmail: "Buyer@example.com"}
+	testutil.UseFakeDB(t, store.fakeDB())
+
+	user, err := ConsumeMagicLink("raw-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.EmailVerified || !store.verified || !store.used {
+		t.Errorf("verified = %v/%v, used = %v; want all true", user.EmailVerified, store.verified, store.used)
+	}
+}
+
+func TestConsumeMagicLinkRejectsChangedEmail(t *testing.T) {
+	// Link dikirim ke alamat lama, lalu user mengganti email sebelum link dipakai
+	store := &magicLinkStore{tokenEmail: "old@example.com", userEmail: "new@example.com"}
+	testutil.UseFakeDB(t, store.fakeDB())
+
+	if _, err := ConsumeMagicLink("raw-token"); !errors.Is(err, ErrInvalidMagicLink) {
+		t.Fatalf("err = %v, want ErrInvalidMagicLink", err)
+	}
+	if store.verified {
+		t.Error("email marked verified from a link sent to the previous address")
+	}
+}
+
+func TestUpdateUserEmailRevokesEmailedTokens(t *testing.T) {
+	prev := mailer.Default()
+	m := &recordingMailer{}
+	mailer.SetDefault(m)
+	t.Cleanup(func() { mailer.SetDefault(prev) })
+
+	email := "old@example.com"
+	var deleted []string
+	testutil.UseFakeDB(t, &testutil.FakeDB{
+		Query: func(query string, args []interface{}) ([]string, [][]interface{}, error) {
+			switch {
+			case testutil.IsStatement(query, "SELECT * FROM `users`"):
+				return []string{"id", "name", "email", "email_verified"}, [][]interface{}{{"user-1", "Budi", email, email == "old@example.com"}}, nil
+			case testutil.IsStatement(query, "SELECT count(*) FROM `users`"):
+				return []string{"count(*)"}, [][]interface{}{{int64(0)}}, nil
+			case testutil.IsStatement(query, "SELECT * FROM `email_verification_tokens`"):
+				return []string{"id"}, nil, nil
+			}
+			return nil, nil, testutil.ErrUnexpectedQuery
+		},
+		Exec: func(query string, args []interface{}) (int64, error) {
+			switch {
+			case testutil.IsStatement(query, "DELETE FROM"):
+				deleted = append(deleted, strings.Fields(query)[2])
+				return 1, nil
+			case testutil.IsStatement(query, "UPDATE `users` SET"):
+				email = "new@example.com"
+				return 1, nil
+			case testutil.IsStatement(query, "INSERT INTO `email_verification_tokens`"):
+				return 1, nil
+			}
+			return 0, testutil.ErrUnexpectedQuery
+		},
+	})
+
+	user, err := UpdateUser("user-1", &models.User{Name: "Budi", Email: "new@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "new@example.com" || user.EmailVerified {
+		t.Errorf("user = %s verified=%v", user.Email, user.EmailVerified)
+	}
+	want := "`email_verification_tokens` `magic_link_tokens` `password_reset_tokens`"
+	if got := strings.Join(deleted, " "); got != want {
+		t.Errorf("deleted from %s, want %s", got, want)
+	}
+	if len(m.sent) != 1 || m.sent[0].To != "new@example.com" {
+		t.Errorf("sent = %+v, want one verification email to the new address", m.sent)
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"crypto/rand"
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrMFANotAllowedForRole = errors.New("two-factor authentication is only available for admin and seller accounts")
+	ErrMFAAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled        = errors.New("two-factor authentication is not enabled")
+	ErrMFANotStarted        = errors.New("two-factor enrollment has not been started")
+	ErrMFARequiredByPolicy  = errors.New("two-factor authentication is required for this role")
+	ErrInvalidMFACode       = errors.New("invalid two-factor code")
+)
+
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFA hanya berlaku untuk akun dengan akses tinggi.
+func IsMFARole(role string) bool {
+	return role == "admin" || role == "seller"
+}
+
+func IsMFARequiredForRole(role string) bool {
+	if !IsMFARole(role) {
+		return false
+	}
+	var policy models.RoleAuthPolicy
+	if err := config.DB.First(&policy, "role = ?", role).Error; err != nil {
+		return false
+	}
+	return policy.MFARequired
+}
+
+// RequiresMFAChallenge menentukan apakah login harus melewati langkah 2FA.
+func RequiresMFAChallenge(user *models.User) bool {
+	return user.MFAEnabled || IsMFARequiredForRole(user.Role)
+}
+
+func BeginMFAEnrollment(userID string) (*MFAEnrollment, error) {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !IsMFARole(user.Role) {
+		return nil, ErrMFANotAllowedForRole
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := config.DB.Model(&user).Update("mfa_pending_secret", secret).Error; err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(mfaIssuer(), user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFAEnrollment mengaktifkan 2FA jika kode dari secret yang tertunda valid,
+// lalu mengembalikan recovery code yang hanya ditampilkan sekali.
+func ConfirmMFAEnrollment(userID, code string) ([]string, error) {
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+		if user.MFAEnabled {
+			return ErrMFAAlreadyEnabled
+		}
+		if user.MFAPendingSecret == "" {
+			return ErrMFANotStarted
+		}
+
+		step, ok := utils.ValidateTOTP(user.MFAPendingSecret, code, time.Now())
+		if !ok {
+			return ErrInvalidMFACode
+		}
+
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"mfa_enabled":        true,
+			"mfa_secret":         user.MFAPendingSecret,
+			"mfa_pending_secret": "",
+			"mfa_last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func DisableMFA(userID, code string) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+		if !user.MFAEnabled {
+			return ErrMFANotEnabled
+		}
+		if IsMFARequiredForRole(user.Role) {
+			return ErrMFARequiredByPolicy
+		}
+		if err := verifyMFACodeTx(tx, &user, code, ""); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"mfa_enabled":        false,
+			"mfa_secret":         "",
+			"mfa_last_used_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.MFARecoveryCode{}).Error
+	})
+}
+
+func RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+		if !user.MFAEnabled {
+			return ErrMFANotEnabled
+		}
+		if err := verifyMFACodeTx(tx, &user, code, ""); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyMFA dipakai pada langkah kedua login. Salah satu dari code (TOTP)
+// atau recoveryCode harus diisi.
+func VerifyMFA(userID, code, recoveryCode string) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+		if !user.MFAEnabled {
+			return ErrMFANotEnabled
+		}
+		return verifyMFACodeTx(tx, &user, code, recoveryCode)
+	})
+}
+
+func GetRoleAuthPolicies() ([]models.RoleAuthPolicy, error) {
+	var policies []models.RoleAuthPolicy
+	if err := config.DB.Order("role").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func SetRoleMFARequirement(role string, required bool) (*models.RoleAuthPolicy, error) {
+	if !IsMFARole(role) {
+		return nil, ErrMFANotAllowedForRole
+	}
+
+	policy := models.RoleAuthPolicy{Role: role}
+	if err := config.DB.FirstOrCreate(&policy, "role = ?", role).Error; err != nil {
+		return nil, err
+	}
+	if err := config.DB.Model(&policy).Update("mfa_required", required).Error; err != nil {
+		return nil, err
+	}
+	policy.MFARequired = required
+	return &policy, nil
+}
+
+func verifyMFACodeTx(tx *gorm.DB, user *models.User, code, recoveryCode string) error {
+	if code != "" {
+		step, ok := utils.ValidateTOTP(user.MFASecret, code, time.Now())
+		// Kode yang sama tidak boleh dipakai dua kali
+		if !ok || step <= user.MFALastUsedStep {
+			return ErrInvalidMFACode
+		}
+		return tx.Model(user).Update("mfa_last_used_step", step).Error
+	}
+
+	if recoveryCode != "" {
+		normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(recoveryCode), "-", ""))
+		result := tx.Model(&models.MFARecoveryCode{}).
+			Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.HashToken(normalized)).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	return ErrInvalidMFACode
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		record := models.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(code),
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+	return codes, nil
+}
+
+func generateRecoveryCode() (string, error) {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b), nil
+}
+
+func mfaIssuer() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "E-Commerce"
+}
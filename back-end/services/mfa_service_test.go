@@ -0,0 +1,142 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"ecommerce-backend/testutil"
+	"ecommerce-backend/utils"
+)
+
+// totpCode menghitung kode TOTP 6 digit untuk secret base32 pada waktu at.
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+// mfaStore menyimulasikan tabel users dan mfa_recovery_codes untuk satu user.
+type mfaStore struct {
+	userID   string
+	secret   string
+	lastStep int64
+	// recovery memetakan hash recovery code ke status sudah dipakai.
+	recovery map[string]bool
+}
+
+func (s *mfaStore) fakeDB() *testutil.FakeDB {
+	return &testutil.FakeDB{
+		Query: func(query string, args []interface{}) ([]string, [][]interface{}, error) {
+			if !testutil.IsStatement(query, "SELECT * FROM `users`") {
+				return nil, nil, testutil.ErrUnexpectedQuery
+			}
+			return []string{"id", "role", "mfa_enabled", "mfa_secret", "mfa_last_used_step"},
+				[][]interface{}{{s.userID, "seller", true, s.secret, s.lastStep}}, nil
+		},
+		Exec: func(query string, args []interface{}) (int64, error) {
+			switch {
+			case testutil.IsStatement(query, "UPDATE `users` SET `mfa_last_used_step`=?"):
+				s.lastStep = args[0].(int64)
+				return 1, nil
+			case testutil.IsStatement(query, "UPDATE `mfa_recovery_codes` SET `used_at`=?"):
+				if !strings.Contains(query, "used_at IS NULL") {
+					return 0, fmt.Errorf("recovery code update is not conditional: %s", query)
+				}
+				hash := args[2].(string)
+				used, ok := s.recovery[hash]
+				if args[1] != s.userID || !ok || used {
+					return 0, nil
+				}
+				s.recovery[hash] = true
+				return 1, nil
+			}
+			return 0, testutil.ErrUnexpectedQuery
+		},
+	}
+}
+
+func TestVerifyMFARejectsReplayedCode(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &mfaStore{userID: "user-1", secret: secret}
+	testutil.UseFakeDB(t, store.fakeDB())
+
+	code := totpCode(t, secret, time.Now())
+	if err := VerifyMFA(store.userID, code, ""); err != nil {
+		t.Fatalf("first use of code: %v", err)
+	}
+	if store.lastStep == 0 {
+		t.Fatal("mfa_last_used_step was not recorded")
+	}
+	if err := VerifyMFA(store.userID, code, ""); err != ErrInvalidMFACode {
+		t.Errorf("replayed code: err = %v, want ErrInvalidMFACode", err)
+	}
+
+	// Kode dari step sebelumnya masih dalam skew tetapi lebih tua dari step terakhir
+	previous := totpCode(t, secret, time.Now().Add(-30*time.Second))
+	if previous != code {
+		if err := VerifyMFA(store.userID, previous, ""); err != ErrInvalidMFACode {
+			t.Errorf("older code after newer one: err = %v, want ErrInvalidMFACode", err)
+		}
+	}
+}
+
+func TestVerifyMFARecoveryCodeIsSingleUse(t *testing.T) {
+	store := &mfaStore{
+		userID:   "user-1",
+		secret:   "JBSWY3DPEHPK3PXP",
+		recovery: map[string]bool{utils.HashToken("ABCDE23456"): false},
+	}
+	testutil.UseFakeDB(t, store.fakeDB())
+
+	tests := []struct {
+		name string
+		code string
+		want error
+	}{
+		// Format yang ditampilkan ke user: huruf besar dengan tanda hubung
+		{"first use", "abcde-23456", nil},
+		{"second use", "ABCDE-23456", ErrInvalidMFACode},
+		{"unknown code", "ZZZZZ-99999", ErrInvalidMFACode},
+		{"empty codes", "", ErrInvalidMFACode},
+	}
+	for _, tt := range tests {
+		if err := VerifyMFA(store.userID, "", tt.code); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code) != 10 || strings.Trim(code, "ABCDEFGHJKLMNPQRSTUVWXYZ23456789") != "" {
+			t.Errorf("recovery code %q has unexpected format", code)
+		}
+		if seen[code] {
+			t.Errorf("recovery code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidOAuthState     = errors.New("invalid or expired oauth state")
+	ErrOAuthEmailNotVerified = errors.New("the provider did not return a verified email address")
+	ErrAccountDisabled       = errors.New("account is disabled")
+	ErrOAuthAccountExists    = errors.New("an account with this email already exists; sign in with your password and link this provider from your profile")
+	ErrOAuthIdentityInUse    = errors.New("this provider account is already linked to another user")
+	ErrInvalidOAuthLoginCode = errors.New("invalid or expired login code")
+)
+
+type OAuthIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+func oauthStateTTL() time.Duration {
+	return utils.GetEnvDuration("OAUTH_STATE_TTL", 10*time.Minute)
+}
+
+func oauthLoginCodeTTL() time.Duration {
+	return utils.GetEnvDuration("OAUTH_LOGIN_CODE_TTL", time.Minute)
+}
+
+// SaveOAuthState menyimpan state flow login. linkUserID dan linkSessionID diisi
+// jika flow dipakai untuk menautkan provider ke akun yang sedang login, kosong
+// untuk login biasa.
+func SaveOAuthState(provider, state, codeVerifier, nonce, linkUserID, linkSessionID string) error {
+	// Bersihkan state lama yang tidak pernah diselesaikan
+	config.DB.Where("expires_at < ?", time.Now()).Delete(&models.OAuthState{})
+
+	saved := models.OAuthState{
+		StateHash:    utils.HashToken(state),
+		Provider:     provider,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oauthStateTTL()),
+	}
+	if linkUserID != "" {
+		saved.LinkUserID = &linkUserID
+		saved.LinkSessionID = linkSessionID
+	}
+	return config.DB.Create(&saved).Error
+}
+
+// ConsumeOAuthState mengambil dan menghapus state sehingga callback tidak bisa diulang.
+func ConsumeOAuthState(provider, state string) (*models.OAuthState, error) {
+	var saved models.OAuthState
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&saved, "state_hash = ?", utils.HashToken(state)).Error; err != nil {
+			return ErrInvalidOAuthState
+		}
+		return tx.Delete(&saved).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if saved.Provider != provider || time.Now().After(saved.ExpiresAt) {
+		return nil, ErrInvalidOAuthState
+	}
+	return &saved, nil
+}
+
+// LinkOrCreateOAuthUser mencari user dari identity yang sudah tertaut, atau
+// membuat akun buyer baru. Identity tidak pernah ditautkan otomatis ke akun yang
+// sudah ada lewat email: pemilik akun harus login dengan password lalu
+// menautkannya sendiri (LinkOAuthIdentity).
+func LinkOrCreateOAuthUser(identity OAuthIdentity) (*models.User, error) {
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&existing).Error
+		if err == nil {
+			return tx.First(&user, "id = ?", existing.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if identity.Email == "" || !identity.EmailVerified {
+			return ErrOAuthEmailNotVerified
+		}
+
+		var count int64
+		if err := tx.Model(&models.User{}).Where("email = ?", identity.Email).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrOAuthAccountExists
+		}
+
+		if user, err = createOAuthUser(tx, identity); err != nil {
+			return err
+		}
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	return &user, nil
+}
+
+// LinkOAuthIdentity menautkan identity provider ke user yang memulai flow link
+// setelah memasukkan password. Sesi dan refresh token lain milik user dicabut
+// supaya sesi yang mungkin dibuat pihak lain sebelum penautan tidak berlaku lagi;
+// sesi yang memulai penautan (keepSessionID) tetap berlaku.
+func LinkOAuthIdentity(userID, keepSessionID string, identity OAuthIdentity) (*models.User, error) {
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+
+		var existing models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&existing).Error
+		if err == nil {
+			if existing.UserID != user.ID {
+				return ErrOAuthIdentityInUse
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if err := RevokeOtherUserSessions(user.ID, keepSessionID); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// IssueOAuthLoginCode membuat code sekali pakai untuk redirect callback ke
+// front-end, sehingga token tidak pernah muncul di URL.
+func IssueOAuthLoginCode(userID string) (string, error) {
+	config.DB.Where("expires_at < ?", time.Now()).Delete(&models.OAuthLoginCode{})
+
+	raw, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	err = config.DB.Create(&models.OAuthLoginCode{
+		CodeHash:  utils.HashToken(raw),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(oauthLoginCodeTTL()),
+	}).Error
+	return raw, err
+}
+
+// ConsumeOAuthLoginCode menukar code dari callback dengan user-nya. Code
+// langsung dihapus sehingga tidak bisa dipakai ulang.
+func ConsumeOAuthLoginCode(raw string) (*models.User, error) {
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var code models.OAuthLoginCode
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&code, "code_hash = ?", utils.HashToken(raw)).Error; err != nil {
+			return ErrInvalidOAuthLoginCode
+		}
+		if err := tx.Delete(&code).Error; err != nil {
+			return err
+		}
+		if time.Now().After(code.ExpiresAt) {
+			return ErrInvalidOAuthLoginCode
+		}
+		return tx.First(&user, "id = ?", code.UserID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	return &user, nil
+}
+
+func createOAuthUser(tx *gorm.DB, identity OAuthIdentity) (models.User, error) {
+	// Akun dari login sosial tidak punya password yang bisa ditebak;
+	// user tetap bisa membuat password lewat forgot-password.
+	randomPassword, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return models.User{}, err
+	}
+	hashed, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = strings.Split(identity.Email, "@")[0]
+	}
+
+	now := time.Now()
+	user := models.User{
+		ID:              uuid.NewString(),
+		Name:            name,
+		Email:           identity.Email,
+		Password:        hashed,
+		Role:            "buyer",
+		IsActive:        true,
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
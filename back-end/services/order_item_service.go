@@ -7,17 +7,16 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func GetSellerOrderItems(sellerID string) ([]models.OrderItem, error) {
 	var orderItems []models.OrderItem
 
 	err := config.DB.
-		Preload("Product").
 		Preload("Order").
 		Preload("Order.User").
-		Joins("JOIN products ON products.id = order_items.product_id").
-		Where("products.seller_id = ?", sellerID).
+		Where("seller_id = ?", sellerID).
 		Find(&orderItems).Error
 
 	return orderItems, err
@@ -25,9 +24,8 @@ func GetSellerOrderItems(sellerID string) ([]models.OrderItem, error) {
 
 func GetSellerOrderItemByID(orderItemID string, sellerID string) (models.OrderItem, error) {
 	var orderItem models.OrderItem
-	err := config.DB.Preload("Product").Preload("Order").Preload("Order.User").
-		Joins("JOIN products ON products.id = order_items.product_id").
-		Where("order_items.id = ? AND products.seller_id = ?", orderItemID, sellerID).
+	err := config.DB.Preload("Order").Preload("Order.User").
+		Where("id = ? AND seller_id = ?", orderItemID, sellerID).
 		First(&orderItem).Error
 	if err != nil {
 		return orderItem, errors.New("order item not found or you don't have permission to view it")
@@ -44,18 +42,12 @@ func UpdateOrderItemStatus(orderItemID string, sellerID string, newStatus string
 	}()
 
 	var orderItem models.OrderItem
-	if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", orderItemID).First(&orderItem).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderItemID).First(&orderItem).Error; err != nil {
 		tx.Rollback()
 		return errors.New("order item not found")
 	}
 
-	var product models.Product
-	if err := tx.Where("id = ?", orderItem.ProductID).First(&product).Error; err != nil {
-		tx.Rollback()
-		return errors.New("product not found")
-	}
-
-	if product.SellerID != sellerID {
+	if orderItem.SellerID == nil || *orderItem.SellerID != sellerID {
 		tx.Rollback()
 		return errors.New("unauthorized: you are not the seller of this product")
 	}
@@ -84,6 +76,15 @@ func UpdateOrderItemStatus(orderItemID string, sellerID string, newStatus string
 		return err
 	}
 
+	// Item yang sudah dibayar sudah masuk sold_count, jadi pembatalannya dikurangi lagi
+	if newStatus == models.OrderItemStatusCancelled &&
+		(orderItem.Status == models.OrderItemStatusPaid || orderItem.Status == models.OrderItemStatusProcessing) {
+		if err := adjustProductSoldCount(tx, orderItem.ProductID, -orderItem.Quantity); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	orderID := orderItem.OrderID
 
 	if err := updateOrderStatusWithinTransaction(tx, orderID); err != nil {
@@ -96,7 +97,7 @@ func UpdateOrderItemStatus(orderItemID string, sellerID string, newStatus string
 
 func updateOrderStatusWithinTransaction(tx *gorm.DB, orderID string) error {
 	var order models.Order
-	if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", orderID).First(&order).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderID).First(&order).Error; err != nil {
 		return err
 	}
 
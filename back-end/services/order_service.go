@@ -14,12 +14,14 @@ func CreateOrder(order *models.Order) error {
 	tx := config.DB.Begin()
 	order.ID = uuid.New().String()
 	var totalPrice float64
+	sellerNames := map[string]string{}
 	correctedOrderItems := make([]models.OrderItem, len(order.OrderItems))
 	for i, item := range order.OrderItems {
 		orderItem := models.OrderItem{
 			ID:        uuid.New().String(),
 			OrderID:   order.ID,
 			ProductID: item.ProductID,
+			VariantID: item.VariantID,
 			Quantity:  item.Quantity,
 			Status:    models.OrderItemStatusPending,
 		}
@@ -29,12 +31,38 @@ func CreateOrder(order *models.Order) error {
 			tx.Rollback()
 			return errors.New("product not found")
 		}
-		if product.Stock < orderItem.Quantity {
+		if err := requirePublished(&product); err != nil {
 			tx.Rollback()
+			return err
+		}
+		// Produk bervarian: stok dan harga diambil dari varian (SKU) yang dipilih
+		variant, err := resolveVariant(tx, product.ID, item.VariantID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		stock, price := product.Stock, product.Price
+		if variant != nil {
+			orderItem.VariantID = &variant.ID
+			stock, price = variant.Stock, variant.Price
+		} else {
+			orderItem.VariantID = nil
+		}
+		if stock < orderItem.Quantity {
+			tx.Rollback()
+			if variant != nil {
+				return errors.New("insufficient stock for product: " + product.Name + " (" + variant.SKU + ")")
+			}
 			return errors.New("insufficient stock for product: " + product.Name)
 		}
-		orderItem.Price = float64(orderItem.Quantity) * product.Price
+		orderItem.UnitPrice = price
+		orderItem.Price = float64(orderItem.Quantity) * price
 		totalPrice += orderItem.Price
+
+		if _, ok := sellerNames[product.SellerID]; !ok {
+			sellerNames[product.SellerID] = sellerDisplayName(tx, product.SellerID)
+		}
+		snapshotOrderItem(&orderItem, &product, variant, sellerNames[product.SellerID])
 		correctedOrderItems[i] = orderItem
 	}
 	order.TotalPrice = totalPrice
@@ -48,6 +76,39 @@ func CreateOrder(order *models.Order) error {
 	return nil
 }
 
+// snapshotOrderItem menyalin data produk yang ditampilkan di riwayat order dan
+// invoice, supaya tidak berubah saat produk diedit atau diarsipkan.
+func snapshotOrderItem(item *models.OrderItem, product *models.Product, variant *models.ProductVariant, sellerName string) {
+	sellerID := product.SellerID
+	item.ProductName = product.Name
+	item.ImageURL = product.ImageURL
+	item.SellerID = &sellerID
+	item.SellerName = sellerName
+	if product.SKU != nil {
+		item.SKU = *product.SKU
+	}
+	if variant != nil {
+		item.SKU = variant.SKU
+		item.VariantOptions = variant.Options
+		if variant.ImageURL != "" {
+			item.ImageURL = variant.ImageURL
+		}
+	}
+}
+
+// sellerDisplayName memakai nama toko jika seller sudah punya profil toko.
+func sellerDisplayName(tx *gorm.DB, sellerID string) string {
+	var shop models.ShopProfile
+	if err := tx.Select("shop_name").First(&shop, "user_id = ?", sellerID).Error; err == nil {
+		return shop.ShopName
+	}
+	var seller models.User
+	if err := tx.Select("name").First(&seller, "id = ?", sellerID).Error; err == nil {
+		return seller.Name
+	}
+	return ""
+}
+
 func UpdateProductStock(productID string, quantity int) error {
 	return config.DB.Model(&models.Product{}).
 		Where("id = ?", productID).
@@ -58,7 +119,7 @@ func GetOrderByID(id string) (*models.Order, error) {
 	var order models.Order
 	err := config.DB.
 		Preload("User").
-		Preload("OrderItems.Product").
+		Preload("OrderItems").
 		First(&order, "id = ?", id).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -71,7 +132,7 @@ func GetOrdersByUserID(userID string) ([]models.Order, error) {
 	var orders []models.Order
 	err := config.DB.
 		Preload("User").
-		Preload("OrderItems.Product").
+		Preload("OrderItems").
 		Where("user_id = ?", userID).
 		Find(&orders).Error
 	return orders, err
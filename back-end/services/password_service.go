@@ -0,0 +1,126 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/mailer"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+	ErrPasswordUnchanged = errors.New("new password must be different from the current password")
+)
+
+func passwordResetTTL() time.Duration {
+	return utils.GetEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute)
+}
+
+// RequestPasswordReset mengirim link reset ke email user. Email yang tidak
+// terdaftar tetap dianggap sukses supaya endpoint tidak bisa dipakai untuk enumerasi akun.
+func RequestPasswordReset(email string) error {
+	var user models.User
+	if err := config.DB.Where("email = ? AND is_active = ?", email, true).First(&user).Error; err != nil {
+		return nil
+	}
+
+	rawToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Hanya token terbaru yang berlaku
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(rawToken),
+			ExpiresAt: time.Now().Add(passwordResetTTL()),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", FrontendURL(), url.QueryEscape(rawToken))
+	return mailer.Default().Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun Anda",
+		Body: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan reset password. Buka link berikut untuk membuat password baru:\n%s\n\nLink ini berlaku selama %s dan hanya bisa dipakai sekali. Abaikan email ini jika Anda tidak memintanya.",
+			user.Name, link, passwordResetTTL()),
+	})
+}
+
+// ResetPassword mengganti password memakai token reset, lalu mencabut semua sesi user.
+func ResetPassword(rawToken, newPassword string) error {
+	hashed, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	var userID string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var token models.PasswordResetToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(rawToken)).
+			First(&token).Error; err != nil {
+			return ErrInvalidResetToken
+		}
+
+		if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+			return ErrInvalidResetToken
+		}
+
+		if err := tx.Model(&token).Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		userID = token.UserID
+		return tx.Model(&models.User{}).Where("id = ?", token.UserID).Update("password", hashed).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return RevokeAllUserSessions(userID)
+}
+
+// ChangePassword dipakai user yang sedang login. Sesi lain milik user dicabut,
+// sedangkan sesi yang sedang dipakai dibiarkan tetap aktif.
+func ChangePassword(userID, currentSessionID, currentPassword, newPassword string) error {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	if !utils.CheckPassword(user.Password, currentPassword) {
+		return ErrIncorrectPassword
+	}
+	if utils.CheckPassword(user.Password, newPassword) {
+		return ErrPasswordUnchanged
+	}
+
+	hashed, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.Model(&user).Update("password", hashed).Error; err != nil {
+		return err
+	}
+
+	return RevokeOtherUserSessions(userID, currentSessionID)
+}
@@ -3,18 +3,20 @@ package services
 import (
 	"ecommerce-backend/config"
 	"ecommerce-backend/models"
+	"errors"
 	"log"
 	"os"
 
 	"github.com/google/uuid"
 	"github.com/veritrans/go-midtrans"
+	"gorm.io/gorm"
 )
 
 func CreateSnapToken(orderID string) (string, error) {
 	var order models.Order
 
 	if err := config.DB.
-		Preload("OrderItems.Product").
+		Preload("OrderItems").
 		Where("id = ?", orderID).
 		First(&order).Error; err != nil {
 		return "", err
@@ -22,7 +24,7 @@ func CreateSnapToken(orderID string) (string, error) {
 
 	var amount float64
 	for _, item := range order.OrderItems {
-		amount += float64(item.Quantity) * float64(item.Product.Price)
+		amount += item.Price
 	}
 
 	midtransClient := midtrans.NewClient()
@@ -59,6 +61,10 @@ func CreateSnapToken(orderID string) (string, error) {
 	return snapResp.Token, nil
 }
 
+// errPaymentStatusChanged berarti webhook lain mengubah status payment di tengah
+// proses; Midtrans akan mengirim ulang notifikasi yang gagal.
+var errPaymentStatusChanged = errors.New("payment status changed concurrently")
+
 func UpdatePaymentStatus(orderID, transactionID, midtransStatus string) error {
 	log.Printf("Starting UpdatePaymentStatus for orderID=%s, status=%s", orderID, midtransStatus)
 
@@ -82,6 +88,9 @@ func UpdatePaymentStatus(orderID, transactionID, midtransStatus string) error {
 	case "expire":
 		paymentStatus = models.PaymentStatusExpired
 		orderStatus = models.OrderStatusCancelled
+	case "refund", "chargeback":
+		paymentStatus = models.PaymentStatusRefund
+		orderStatus = models.OrderStatusCancelled
 	case "pending":
 		paymentStatus = models.PaymentStatusPending
 		orderStatus = models.OrderStatusPending
@@ -97,14 +106,43 @@ func UpdatePaymentStatus(orderID, transactionID, midtransStatus string) error {
 		return err
 	}
 
-	log.Printf("Found payment for orderID=%s, updating status to %s", orderID, paymentStatus)
-	if err := tx.Model(&payment).Updates(map[string]interface{}{
-		"status":         paymentStatus,
-		"transaction_id": transactionID,
-	}).Error; err != nil {
-		log.Printf("Error updating payment: %v", err)
+	// Notifikasi pending yang datang terlambat tidak boleh membatalkan status lunas,
+	// karena settlement berikutnya akan menghitung sold_count sekali lagi.
+	if payment.Status == models.PaymentStatusSuccess && paymentStatus == models.PaymentStatusPending {
+		log.Printf("Ignoring pending notification for paid orderID=%s", orderID)
 		tx.Rollback()
-		return err
+		return nil
+	}
+
+	// sold_count hanya berubah saat payment benar-benar berpindah masuk/keluar status
+	// success. Update bersyarat pada status lama memastikan hanya satu webhook yang
+	// melakukan perpindahan itu meskipun notifikasi terkirim berkali-kali secara paralel.
+	becamePaid, wasPaid := false, false
+	log.Printf("Found payment for orderID=%s, updating status to %s", orderID, paymentStatus)
+	if payment.Status == paymentStatus {
+		if err := tx.Model(&payment).Update("transaction_id", transactionID).Error; err != nil {
+			log.Printf("Error updating payment: %v", err)
+			tx.Rollback()
+			return err
+		}
+	} else {
+		result := tx.Model(&models.Payment{}).
+			Where("id = ? AND status = ?", payment.ID, payment.Status).
+			Updates(map[string]interface{}{
+				"status":         paymentStatus,
+				"transaction_id": transactionID,
+			})
+		if result.Error != nil {
+			log.Printf("Error updating payment: %v", result.Error)
+			tx.Rollback()
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return errPaymentStatusChanged
+		}
+		becamePaid = paymentStatus == models.PaymentStatusSuccess
+		wasPaid = payment.Status == models.PaymentStatusSuccess
 	}
 
 	var order models.Order
@@ -136,6 +174,14 @@ func UpdatePaymentStatus(orderID, transactionID, midtransStatus string) error {
 
 		log.Printf("Updated %d order items to processing", result.RowsAffected)
 
+		if becamePaid {
+			if err := adjustSoldCounts(tx, orderID, 1); err != nil {
+				log.Printf("Error updating sold counts: %v", err)
+				tx.Rollback()
+				return err
+			}
+		}
+
 		if err := tx.Commit().Error; err != nil {
 			log.Printf("Error committing transaction: %v", err)
 			return err
@@ -148,9 +194,19 @@ func UpdatePaymentStatus(orderID, transactionID, midtransStatus string) error {
 		}
 	} else if paymentStatus == models.PaymentStatusCancel ||
 		paymentStatus == models.PaymentStatusExpired ||
-		paymentStatus == models.PaymentStatusFailed {
+		paymentStatus == models.PaymentStatusFailed ||
+		paymentStatus == models.PaymentStatusRefund {
 		log.Printf("Payment failed, updating order items to cancelled for orderID=%s", orderID)
 
+		// Item yang sudah dibatalkan seller lebih dulu sudah dikurangi dari sold_count
+		if wasPaid {
+			if err := adjustSoldCounts(tx, orderID, -1); err != nil {
+				log.Printf("Error updating sold counts: %v", err)
+				tx.Rollback()
+				return err
+			}
+		}
+
 		if err := tx.Model(&models.OrderItem{}).
 			Where("order_id = ?", orderID).
 			Update("status", models.OrderItemStatusCancelled).Error; err != nil {
@@ -182,3 +238,23 @@ func GetPaymentByOrderID(orderID string) (*models.Payment, error) {
 	}
 	return &payment, nil
 }
+
+// adjustSoldCounts menambah (sign 1) atau mengurangi (sign -1) sold_count produk
+// untuk setiap item order yang belum dibatalkan.
+func adjustSoldCounts(tx *gorm.DB, orderID string, sign int) error {
+	var items []models.OrderItem
+	if err := tx.Where("order_id = ? AND status <> ?", orderID, models.OrderItemStatusCancelled).Find(&items).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := adjustProductSoldCount(tx, item.ProductID, sign*item.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func adjustProductSoldCount(tx *gorm.DB, productID string, delta int) error {
+	return tx.Model(&models.Product{}).Where("id = ?", productID).
+		UpdateColumn("sold_count", gorm.Expr("GREATEST(sold_count + ?, 0)", delta)).Error
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxCategoryAttributes   = 30
+	maxAttributeOptions     = 100
+	maxAttributeValueLength = 255
+	// Setiap filter atribut menambah satu subquery ke listing.
+	maxAttributeFilters = 10
+)
+
+var (
+	attributeKeyPattern  = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+	ErrCategoryNotFound  = errors.New("category not found")
+	ErrInvalidAttributes = errors.New("attributes must be a JSON object")
+)
+
+// AttributeValidationError menandai skema atau nilai atribut yang tidak valid
+// (400), berbeda dengan error database.
+type AttributeValidationError struct {
+	msg string
+}
+
+func (e *AttributeValidationError) Error() string { return e.msg }
+
+func invalidAttributes(format string, args ...interface{}) error {
+	return &AttributeValidationError{msg: fmt.Sprintf(format, args...)}
+}
+
+type CategoryAttributeInput struct {
+	Key      string   `json:"key"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options"`
+	Unit     string   `json:"unit"`
+	Required bool     `json:"required"`
+	// Filterable default true jika tidak dikirim
+	Filterable *bool `json:"filterable"`
+}
+
+func GetCategoryAttributes(categoryID string) ([]models.CategoryAttribute, error) {
+	var attributes []models.CategoryAttribute
+	err := config.DB.Where("category_id = ?", categoryID).Order("position").Find(&attributes).Error
+	return attributes, err
+}
+
+// SetCategoryAttributes mengganti skema atribut kategori. Atribut dicocokkan
+// berdasarkan key sehingga nilai produk tetap tersimpan; atribut yang tidak ada
+// lagi di input dihapus beserta nilainya. Jika tipe atribut berubah atau opsi
+// enum dihapus, nilai lama yang tidak lagi valid ikut dihapus.
+func SetCategoryAttributes(categoryID string, inputs []CategoryAttributeInput) ([]models.CategoryAttribute, error) {
+	attributes, err := normalizeCategoryAttributes(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, "id = ?", categoryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCategoryNotFound
+			}
+			return err
+		}
+
+		var existing []models.CategoryAttribute
+		if err := tx.Where("category_id = ?", categoryID).Find(&existing).Error; err != nil {
+			return err
+		}
+		byKey := make(map[string]models.CategoryAttribute, len(existing))
+		for _, attr := range existing {
+			byKey[attr.Key] = attr
+		}
+
+		keep := make([]string, 0, len(attributes))
+		for i := range attributes {
+			attr := &attributes[i]
+			attr.CategoryID = categoryID
+			old, ok := byKey[attr.Key]
+			if !ok {
+				if err := tx.Create(attr).Error; err != nil {
+					return err
+				}
+				keep = append(keep, attr.ID)
+				continue
+			}
+
+			attr.ID = old.ID
+			keep = append(keep, attr.ID)
+			if err := tx.Model(attr).
+				Select("name", "type", "options", "unit", "required", "filterable", "position").
+				Updates(attr).Error; err != nil {
+				return err
+			}
+
+			stale := tx.Where("attribute_id = ?", attr.ID)
+			switch {
+			case old.Type != attr.Type:
+			case attr.Type == models.AttributeTypeEnum:
+				stale = stale.Where("text_value NOT IN ?", attr.Options)
+			default:
+				continue
+			}
+			if err := stale.Delete(&models.ProductAttributeValue{}).Error; err != nil {
+				return err
+			}
+		}
+
+		removed := tx.Where("category_id = ?", categoryID)
+		if len(keep) > 0 {
+			removed = removed.Where("id NOT IN ?", keep)
+		}
+		var removedIDs []string
+		if err := removed.Model(&models.CategoryAttribute{}).Pluck("id", &removedIDs).Error; err != nil {
+			return err
+		}
+		if len(removedIDs) > 0 {
+			if err := tx.Where("attribute_id IN ?", removedIDs).Delete(&models.ProductAttributeValue{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", removedIDs).Delete(&models.CategoryAttribute{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+func normalizeCategoryAttributes(inputs []CategoryAttributeInput) ([]models.CategoryAttribute, error) {
+	if len(inputs) > maxCategoryAttributes {
+		return nil, invalidAttributes("a category can have at most %d attributes", maxCategoryAttributes)
+	}
+
+	seen := make(map[string]bool, len(inputs))
+	attributes := make([]models.CategoryAttribute, 0, len(inputs))
+	for i, in := range inputs {
+		key := strings.ToLower(strings.TrimSpace(in.Key))
+		if !attributeKeyPattern.MatchString(key) {
+			return nil, invalidAttributes("attribute key %q must be 1-64 characters of a-z, 0-9 or _", in.Key)
+		}
+		if seen[key] {
+			return nil, invalidAttributes("duplicate attribute key %q", key)
+		}
+		seen[key] = true
+
+		name := strings.TrimSpace(in.Name)
+		if name == "" {
+			return nil, invalidAttributes("attribute %q requires a name", key)
+		}
+
+		attr := models.CategoryAttribute{
+			Key:        key,
+			Name:       name,
+			Type:       strings.ToLower(strings.TrimSpace(in.Type)),
+			Unit:       strings.TrimSpace(in.Unit),
+			Required:   in.Required,
+			Filterable: in.Filterable == nil || *in.Filterable,
+			Position:   i,
+		}
+
+		switch attr.Type {
+		case models.AttributeTypeText, models.AttributeTypeNumber:
+			if len(in.Options) > 0 {
+				return nil, invalidAttributes("attribute %q: options are only allowed for enum attributes", key)
+			}
+		case models.AttributeTypeEnum:
+			if len(in.Options) == 0 {
+				return nil, invalidAttributes("attribute %q: enum attributes require options", key)
+			}
+			if len(in.Options) > maxAttributeOptions {
+				return nil, invalidAttributes("attribute %q: at most %d options are allowed", key, maxAttributeOptions)
+			}
+			seenOption := make(map[string]bool, len(in.Options))
+			for _, option := range in.Options {
+				option = strings.TrimSpace(option)
+				if option == "" || len(option) > maxAttributeValueLength {
+					return nil, invalidAttributes("attribute %q: options must be 1-%d characters", key, maxAttributeValueLength)
+				}
+				if seenOption[strings.ToLower(option)] {
+					return nil, invalidAttributes("attribute %q: duplicate option %q", key, option)
+				}
+				seenOption[strings.ToLower(option)] = true
+				attr.Options = append(attr.Options, option)
+			}
+		default:
+			return nil, invalidAttributes("attribute %q: type must be text, number or enum", key)
+		}
+		attributes = append(attributes, attr)
+	}
+	return attributes, nil
+}
+
+// ParseAttributeInput membaca field form attributes berupa objek JSON
+// {"brand": "Nike", "weight": 1.5}. Nilai number dan bool diubah ke string;
+// null atau string kosong berarti atribut tidak diisi.
+func ParseAttributeInput(raw string) (map[string]string, error) {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil || decoded == nil {
+		return nil, ErrInvalidAttributes
+	}
+	values := make(map[string]string, len(decoded))
+	for key, value := range decoded {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			values[key] = v
+		case float64:
+			values[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			values[key] = strconv.FormatBool(v)
+		default:
+			return nil, invalidAttributes("attribute %q must be a string or number", key)
+		}
+	}
+	return values, nil
+}
+
+// CurrentProductAttributes mengembalikan nilai atribut produk yang tersimpan
+// dalam bentuk input, dipakai saat produk diubah tanpa mengirim attributes.
+// Jika produk pindah kategori, hanya key yang juga ada di kategori baru yang dibawa.
+func CurrentProductAttributes(product *models.Product, categoryID string) (map[string]string, error) {
+	values := make(map[string]string, len(product.Attributes))
+	for _, value := range product.Attributes {
+		if value.Attribute != nil {
+			values[value.Attribute.Key] = value.TextValue
+		}
+	}
+	if categoryID == product.CategoryID || len(values) == 0 {
+		return values, nil
+	}
+
+	schema, err := GetCategoryAttributes(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(schema))
+	for _, attr := range schema {
+		known[attr.Key] = true
+	}
+	for key := range values {
+		if !known[key] {
+			delete(values, key)
+		}
+	}
+	return values, nil
+}
+
+// ValidateProductAttributes memeriksa nilai atribut terhadap skema kategori:
+// key harus terdaftar, atribut required wajib diisi, number harus angka dan enum
+// harus salah satu opsi.
+func ValidateProductAttributes(categoryID string, input map[string]string) ([]models.ProductAttributeValue, error) {
+	schema, err := GetCategoryAttributes(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	return validateAttributeValues(schema, input)
+}
+
+func validateAttributeValues(schema []models.CategoryAttribute, input map[string]string) ([]models.ProductAttributeValue, error) {
+	byKey := make(map[string]*models.CategoryAttribute, len(schema))
+	for i := range schema {
+		byKey[schema[i].Key] = &schema[i]
+	}
+
+	unknown := make([]string, 0)
+	for key := range input {
+		if byKey[key] == nil {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, invalidAttributes("unknown attribute: %s", strings.Join(unknown, ", "))
+	}
+
+	values := make([]models.ProductAttributeValue, 0, len(input))
+	for i := range schema {
+		attr := &schema[i]
+		raw := strings.TrimSpace(input[attr.Key])
+		if raw == "" {
+			if attr.Required {
+				return nil, invalidAttributes("attribute %q is required", attr.Key)
+			}
+			continue
+		}
+		if len(raw) > maxAttributeValueLength {
+			return nil, invalidAttributes("attribute %q must be at most %d characters", attr.Key, maxAttributeValueLength)
+		}
+
+		value := models.ProductAttributeValue{AttributeID: attr.ID, TextValue: raw}
+		switch attr.Type {
+		case models.AttributeTypeNumber:
+			n, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, invalidAttributes("attribute %q must be a number", attr.Key)
+			}
+			value.NumberValue = &n
+			value.TextValue = strconv.FormatFloat(n, 'f', -1, 64)
+		case models.AttributeTypeEnum:
+			option, ok := matchAttributeOption(attr.Options, raw)
+			if !ok {
+				return nil, invalidAttributes("attribute %q must be one of: %s", attr.Key, strings.Join(attr.Options, ", "))
+			}
+			value.TextValue = option
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Opsi enum dicocokkan tanpa membedakan huruf besar/kecil lalu disimpan dalam
+// bentuk aslinya supaya facet tidak terpecah.
+func matchAttributeOption(options []string, value string) (string, bool) {
+	for _, option := range options {
+		if strings.EqualFold(option, value) {
+			return option, true
+		}
+	}
+	return "", false
+}
+
+// replaceProductAttributes mengganti seluruh nilai atribut produk di dalam tx.
+func replaceProductAttributes(tx *gorm.DB, productID string, values []models.ProductAttributeValue) error {
+	if err := tx.Where("product_id = ?", productID).Delete(&models.ProductAttributeValue{}).Error; err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	for i := range values {
+		values[i].ProductID = productID
+		values[i].Attribute = nil
+	}
+	return tx.Create(&values).Error
+}
+
+// AttributeFilter adalah filter ?attr.<key>=a,b (salah satu nilai) dan
+// ?attr.<key>.min= / ?attr.<key>.max= (rentang untuk atribut number).
+type AttributeFilter struct {
+	Key    string
+	Values []string
+	Min    *float64
+	Max    *float64
+}
+
+// ValidateAttributeFilters membatasi jumlah filter dan menolak key yang bukan
+// atribut filterable di kategori mana pun, supaya filter salah ketik tidak diam-diam
+// mengosongkan hasil.
+func ValidateAttributeFilters(filters []AttributeFilter) error {
+	if len(filters) > maxAttributeFilters {
+		return invalidAttributes("at most %d attribute filters are allowed", maxAttributeFilters)
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+	keys := make([]string, len(filters))
+	for i, f := range filters {
+		keys[i] = f.Key
+	}
+	var known []string
+	if err := config.DB.Model(&models.CategoryAttribute{}).
+		Distinct("attr_key").
+		Where("attr_key IN ? AND filterable = ?", keys, true).
+		Pluck("attr_key", &known).Error; err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if !slices.Contains(known, key) {
+			return invalidAttributes("unknown attribute filter %q", key)
+		}
+	}
+	return nil
+}
+
+func applyAttributeFilters(query *gorm.DB, filters []AttributeFilter) *gorm.DB {
+	for _, f := range filters {
+		sub := config.DB.Table("product_attribute_values pav").
+			Select("pav.product_id").
+			Joins("JOIN category_attributes ca ON ca.id = pav.attribute_id").
+			Where("ca.attr_key = ?", f.Key)
+		if len(f.Values) > 0 {
+			sub = sub.Where("pav.text_value IN ?", f.Values)
+		}
+		if f.Min != nil {
+			sub = sub.Where("pav.number_value >= ?", *f.Min)
+		}
+		if f.Max != nil {
+			sub = sub.Where("pav.number_value <= ?", *f.Max)
+		}
+		query = query.Where("id IN (?)", sub)
+	}
+	return query
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// Facet yang nilainya sangat beragam (mis. text bebas) dipotong ke nilai
+// dengan jumlah produk terbanyak.
+const maxFacetValues = 50
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// Facet merangkum satu atribut di hasil listing/pencarian. Atribut enum dan text
+// berisi Values; atribut number berisi Min dan Max untuk filter rentang.
+type Facet struct {
+	Key    string       `json:"key"`
+	Name   string       `json:"name"`
+	Type   string       `json:"type"`
+	Unit   string       `json:"unit,omitempty"`
+	Values []FacetValue `json:"values,omitempty"`
+	Min    *float64     `json:"min,omitempty"`
+	Max    *float64     `json:"max,omitempty"`
+}
+
+type facetRow struct {
+	AttrKey   string
+	Type      string
+	TextValue string
+	Count     int64
+	MinValue  *float64
+	MaxValue  *float64
+}
+
+// productFacets menghitung facet untuk produk yang lolos filter params dan scope.
+// Facet atribut yang sedang difilter dihitung tanpa filternya sendiri supaya
+// pilihan lain di atribut itu tetap terlihat beserta jumlahnya.
+func productFacets(params ProductListParams, scope func(*gorm.DB) *gorm.DB) ([]Facet, error) {
+	rows, err := facetRows(params, scope)
+	if err != nil {
+		return nil, err
+	}
+	return buildFacets(params, rows)
+}
+
+// searchFacets menghitung facet untuk ID hasil pencarian per batch supaya daftar
+// IN tetap kecil. Batch tidak saling beririsan, jadi jumlah per nilai bisa dijumlahkan.
+func searchFacets(params ProductListParams, ids []string) ([]Facet, error) {
+	var rows []facetRow
+	for start := 0; start < len(ids); start += searchFilterBatchSize {
+		batch := ids[start:min(start+searchFilterBatchSize, len(ids))]
+		batchRows, err := facetRows(params, func(db *gorm.DB) *gorm.DB { return db.Where("id IN ?", batch) })
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, batchRows...)
+	}
+	return buildFacets(params, mergeFacetRows(rows))
+}
+
+func mergeFacetRows(rows []facetRow) []facetRow {
+	merged := make([]facetRow, 0, len(rows))
+	index := make(map[[3]string]int, len(rows))
+	for _, row := range rows {
+		key := [3]string{row.AttrKey, row.Type, row.TextValue}
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, row)
+			continue
+		}
+		m := &merged[i]
+		m.Count += row.Count
+		if row.MinValue != nil && (m.MinValue == nil || *row.MinValue < *m.MinValue) {
+			m.MinValue = row.MinValue
+		}
+		if row.MaxValue != nil && (m.MaxValue == nil || *row.MaxValue > *m.MaxValue) {
+			m.MaxValue = row.MaxValue
+		}
+	}
+	return merged
+}
+
+func facetRows(params ProductListParams, scope func(*gorm.DB) *gorm.DB) ([]facetRow, error) {
+	active := make([]string, 0, len(params.Attributes))
+	for _, f := range params.Attributes {
+		active = append(active, f.Key)
+	}
+
+	var rows []facetRow
+	for _, exclude := range append([]string{""}, active...) {
+		p := params
+		p.Attributes = make([]AttributeFilter, 0, len(params.Attributes))
+		for _, f := range params.Attributes {
+			if f.Key != exclude {
+				p.Attributes = append(p.Attributes, f)
+			}
+		}
+		products := applyProductFilters(config.DB.Model(&models.Product{}), p).Select("id")
+		if scope != nil {
+			products = scope(products)
+		}
+
+		query := config.DB.Table("product_attribute_values pav").
+			Select("ca.attr_key, ca.type, pav.text_value, COUNT(DISTINCT pav.product_id) AS count, "+
+				"MIN(pav.number_value) AS min_value, MAX(pav.number_value) AS max_value").
+			Joins("JOIN category_attributes ca ON ca.id = pav.attribute_id").
+			Where("ca.filterable = ?", true).
+			Where("pav.product_id IN (?)", products).
+			Group("ca.attr_key, ca.type, pav.text_value")
+		if exclude != "" {
+			query = query.Where("ca.attr_key = ?", exclude)
+		} else if len(active) > 0 {
+			query = query.Where("ca.attr_key NOT IN ?", active)
+		}
+
+		var batch []facetRow
+		if err := query.Scan(&batch).Error; err != nil {
+			return nil, err
+		}
+		rows = append(rows, batch...)
+	}
+	return rows, nil
+}
+
+func buildFacets(params ProductListParams, rows []facetRow) ([]Facet, error) {
+	if len(rows) == 0 {
+		return []Facet{}, nil
+	}
+
+	byKey := map[string]*Facet{}
+	keys := make([]string, 0)
+	for _, row := range rows {
+		facet, ok := byKey[row.AttrKey]
+		if !ok {
+			facet = &Facet{Key: row.AttrKey, Type: row.Type}
+			byKey[row.AttrKey] = facet
+			keys = append(keys, row.AttrKey)
+		}
+		if row.Type == models.AttributeTypeNumber {
+			if row.MinValue != nil && (facet.Min == nil || *row.MinValue < *facet.Min) {
+				facet.Min = row.MinValue
+			}
+			if row.MaxValue != nil && (facet.Max == nil || *row.MaxValue > *facet.Max) {
+				facet.Max = row.MaxValue
+			}
+			continue
+		}
+		facet.Values = append(facet.Values, FacetValue{Value: row.TextValue, Count: row.Count})
+	}
+
+	// Nama dan unit diambil dari skema kategori yang difilter jika ada, karena key
+	// yang sama bisa dipakai di beberapa kategori.
+	var attributes []models.CategoryAttribute
+	if err := config.DB.Where("attr_key IN ?", keys).Order("position").Find(&attributes).Error; err != nil {
+		return nil, err
+	}
+	position := map[string]int{}
+	for _, attr := range attributes {
+		facet := byKey[attr.Key]
+		if _, seen := position[attr.Key]; seen && attr.CategoryID != params.CategoryID {
+			continue
+		}
+		facet.Name, facet.Unit = attr.Name, attr.Unit
+		position[attr.Key] = attr.Position
+	}
+
+	facets := make([]Facet, 0, len(keys))
+	for _, key := range keys {
+		facet := byKey[key]
+		sort.Slice(facet.Values, func(i, j int) bool {
+			if facet.Values[i].Count != facet.Values[j].Count {
+				return facet.Values[i].Count > facet.Values[j].Count
+			}
+			return facet.Values[i].Value < facet.Values[j].Value
+		})
+		if len(facet.Values) > maxFacetValues {
+			facet.Values = facet.Values[:maxFacetValues]
+		}
+		facets = append(facets, *facet)
+	}
+	sort.SliceStable(facets, func(i, j int) bool {
+		if position[facets[i].Key] != position[facets[j].Key] {
+			return position[facets[i].Key] < position[facets[j].Key]
+		}
+		return facets[i].Key < facets[j].Key
+	})
+	return facets, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"ecommerce-backend/models"
+	"ecommerce-backend/testutil"
+)
+
+func TestSearchFacetsBatchesIDs(t *testing.T) {
+	ids := make([]string, 2*searchFilterBatchSize+500)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("p%04d", i)
+	}
+
+	var batchSizes []int
+	testutil.UseFakeDB(t, &testutil.FakeDB{
+		Query: func(query string, args []interface{}) ([]string, [][]interface{}, error) {
+			switch {
+			case testutil.IsStatement(query, "SELECT ca.attr_key"):
+				n := 0
+				for _, arg := range args {
+					if s, ok := arg.(string); ok && len(s) == 5 && s[0] == 'p' {
+						n++
+					}
+				}
+				batchSizes = append(batchSizes, n)
+				// Setiap batch: separuh produk hitam, berat bervariasi per batch
+				batch := float64(len(batchSizes))
+				return []string{"attr_key", "type", "text_value", "count", "min_value", "max_value"}, [][]interface{}{
+					{"color", models.AttributeTypeEnum, "Hitam", int64(n / 2), nil, nil},
+					{"weight", models.AttributeTypeNumber, "", int64(n), batch, batch * 10},
+				}, nil
+			case testutil.IsStatement(query, "SELECT * FROM `category_attributes`"):
+				return []string{"attr_key", "name"}, [][]interface{}{{"color", "Warna"}, {"weight", "Berat"}}, nil
+			}
+			return nil, nil, testutil.ErrUnexpectedQuery
+		},
+	})
+
+	facets, err := searchFacets(ProductListParams{}, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(batchSizes) != fmt.Sprint([]int{searchFilterBatchSize, searchFilterBatchSize, 500}) {
+		t.Errorf("batch sizes = %v", batchSizes)
+	}
+	if len(facets) != 2 {
+		t.Fatalf("facets = %+v", facets)
+	}
+	byKey := map[string]Facet{facets[0].Key: facets[0], facets[1].Key: facets[1]}
+	if values := byKey["color"].Values; len(values) != 1 || values[0].Count != int64(len(ids)/2) || byKey["color"].Name != "Warna" {
+		t.Errorf("color facet = %+v, want Hitam counted across batches", byKey["color"])
+	}
+	if w := byKey["weight"]; w.Min == nil || w.Max == nil || *w.Min != 1 || *w.Max != 30 {
+		t.Errorf("weight facet = %+v, want min 1 and max 30", w)
+	}
+}
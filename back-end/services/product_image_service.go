@@ -0,0 +1,232 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/storage"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const MaxProductImages = 10
+
+var (
+	ErrProductImageNotFound = errors.New("product image not found")
+	ErrTooManyImages        = errors.New("a product can have at most 10 images")
+	ErrInvalidImageOrder    = errors.New("image_ids must list every image of the product exactly once")
+)
+
+type ProductImageInput struct {
+	URL        string
+	AltText    string
+	Renditions models.ImageRenditions
+}
+
+func GetProductImages(productID string) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+	err := config.DB.Where("product_id = ?", productID).Order("position").Find(&images).Error
+	return images, err
+}
+
+// AddProductImages menambahkan gambar di akhir galeri. Jika produk belum punya
+// gambar primary, gambar pertama yang ditambahkan menjadi primary.
+func AddProductImages(productID string, inputs []ProductImageInput, makePrimary bool) ([]models.ProductImage, error) {
+	var created []models.ProductImage
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, "id = ?", productID).Error; err != nil {
+			return errors.New("product not found")
+		}
+
+		var existing []models.ProductImage
+		if err := tx.Where("product_id = ?", productID).Order("position").Find(&existing).Error; err != nil {
+			return err
+		}
+		if len(existing)+len(inputs) > MaxProductImages {
+			return ErrTooManyImages
+		}
+
+		hasPrimary := false
+		for _, img := range existing {
+			hasPrimary = hasPrimary || img.IsPrimary
+		}
+		position := 0
+		if len(existing) > 0 {
+			position = existing[len(existing)-1].Position + 1
+		}
+
+		for i, in := range inputs {
+			img := models.ProductImage{
+				ProductID:  productID,
+				URL:        in.URL,
+				AltText:    in.AltText,
+				Position:   position + i,
+				Renditions: in.Renditions,
+			}
+			if err := tx.Create(&img).Error; err != nil {
+				return err
+			}
+			created = append(created, img)
+		}
+
+		if len(created) > 0 && (makePrimary || !hasPrimary) {
+			if err := setPrimaryImage(tx, productID, &created[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return created, err
+}
+
+// ReorderProductImages menyimpan urutan galeri sesuai imageIDs.
+func ReorderProductImages(productID string, imageIDs []string) ([]models.ProductImage, error) {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var images []models.ProductImage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("product_id = ?", productID).Find(&images).Error; err != nil {
+			return err
+		}
+		if len(imageIDs) != len(images) {
+			return ErrInvalidImageOrder
+		}
+		known := make(map[string]bool, len(images))
+		for _, img := range images {
+			known[img.ID] = true
+		}
+		for position, id := range imageIDs {
+			if !known[id] {
+				return ErrInvalidImageOrder
+			}
+			delete(known, id)
+			if err := tx.Model(&models.ProductImage{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetProductImages(productID)
+}
+
+// UpdateProductImage mengubah alt text dan/atau menjadikan gambar sebagai primary.
+func UpdateProductImage(productID, imageID string, altText *string, primary bool) (*models.ProductImage, error) {
+	var image models.ProductImage
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&image, "id = ? AND product_id = ?", imageID, productID).Error; err != nil {
+			return ErrProductImageNotFound
+		}
+		if altText != nil {
+			if err := tx.Model(&image).Update("alt_text", *altText).Error; err != nil {
+				return err
+			}
+		}
+		if primary {
+			return setPrimaryImage(tx, productID, &image)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// DeleteProductImage menghapus gambar dari galeri lalu dari storage. Jika gambar
+// primary dihapus, gambar berikutnya dalam urutan menjadi primary. File yang masih
+// dipakai snapshot order atau varian tidak dihapus dari storage.
+func DeleteProductImage(productID, imageID string) error {
+	var image models.ProductImage
+	var orphaned []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&image, "id = ? AND product_id = ?", imageID, productID).Error; err != nil {
+			return ErrProductImageNotFound
+		}
+		if err := tx.Delete(&image).Error; err != nil {
+			return err
+		}
+		if image.IsPrimary {
+			var next models.ProductImage
+			err := tx.Where("product_id = ?", productID).Order("position").First(&next).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = tx.Model(&models.Product{}).Where("id = ?", productID).
+					Select("image_url", "image_renditions").
+					Updates(models.Product{}).Error
+			} else if err == nil {
+				err = setPrimaryImage(tx, productID, &next)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		orphaned, err = unreferencedImageURLs(tx, image.StorageURLs())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	deleteStoredImages(orphaned...)
+	return nil
+}
+
+// unreferencedImageURLs membuang URL yang masih dipakai order_items.image_url atau
+// product_variants.image_url. Snapshot order menyimpan URL storage yang sama dengan
+// galeri, jadi menghapus filenya membuat gambar di riwayat order dan invoice rusak.
+func unreferencedImageURLs(tx *gorm.DB, urls []string) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	referenced := make(map[string]bool)
+	for _, model := range []interface{}{&models.OrderItem{}, &models.ProductVariant{}} {
+		var used []string
+		if err := tx.Model(model).Distinct().Where("image_url IN ?", urls).Pluck("image_url", &used).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range used {
+			referenced[u] = true
+		}
+	}
+
+	var orphaned []string
+	for _, u := range urls {
+		if !referenced[u] {
+			orphaned = append(orphaned, u)
+		}
+	}
+	return orphaned, nil
+}
+
+func setPrimaryImage(tx *gorm.DB, productID string, image *models.ProductImage) error {
+	if err := tx.Model(&models.ProductImage{}).
+		Where("product_id = ? AND id <> ?", productID, image.ID).
+		Update("is_primary", false).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(image).Update("is_primary", true).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Product{}).Where("id = ?", productID).
+		Select("image_url", "image_renditions").
+		Updates(models.Product{ImageURL: image.URL, ImageRenditions: image.Renditions}).Error
+}
+
+// deleteStoredImages dipanggil setelah transaksi commit. Kegagalan hanya dicatat
+// karena baris database sudah terhapus; file yatim bisa dibersihkan manual.
+func deleteStoredImages(urls ...string) {
+	store := storage.Default()
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		if err := storage.DeleteURL(store, url); err != nil {
+			log.Printf("Failed to delete image %s from storage: %v", url, err)
+		}
+	}
+}
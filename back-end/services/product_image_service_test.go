@@ -0,0 +1,69 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"ecommerce-backend/storage"
+	"ecommerce-backend/testutil"
+)
+
+// recordingStorage mencatat key yang dihapus tanpa menyentuh disk.
+type recordingStorage struct {
+	*storage.LocalStorage
+	deleted []string
+}
+
+func (s *recordingStorage) Delete(key string) error {
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+func TestDeleteProductImageKeepsFilesReferencedByOrders(t *testing.T) {
+	const base = "https://cdn.example.com/uploads/products/p1/"
+	store := &recordingStorage{LocalStorage: &storage.LocalStorage{BaseURL: "https://cdn.example.com/uploads"}}
+	prev := storage.Default()
+	storage.SetDefault(store)
+	t.Cleanup(func() { storage.SetDefault(prev) })
+
+	// Order lama menyimpan URL gambar asli, varian memakai rendition medium
+	referenced := map[string]string{
+		base + "large.jpg":  "order_items",
+		base + "medium.jpg": "product_variants",
+	}
+	testutil.UseFakeDB(t, &testutil.FakeDB{
+		Query: func(query string, args []interface{}) ([]string, [][]interface{}, error) {
+			switch {
+			case testutil.IsStatement(query, "SELECT * FROM `product_images`"):
+				renditions := `{"large":{"url":"` + base + `large.jpg"},"medium":{"url":"` + base + `medium.jpg","webp_url":"` + base + `medium.webp"},"thumbnail":{"url":"` + base + `thumb.jpg"}}`
+				return []string{"id", "product_id", "url", "is_primary", "renditions"},
+					[][]interface{}{{"img-1", "p1", base + "large.jpg", false, renditions}}, nil
+			case testutil.IsStatement(query, "SELECT DISTINCT `image_url` FROM `order_items`"),
+				testutil.IsStatement(query, "SELECT DISTINCT `image_url` FROM `product_variants`"):
+				var rows [][]interface{}
+				for _, arg := range args {
+					if table, ok := referenced[arg.(string)]; ok && testutil.IsStatement(query, "SELECT DISTINCT `image_url` FROM `"+table+"`") {
+						rows = append(rows, []interface{}{arg})
+					}
+				}
+				return []string{"image_url"}, rows, nil
+			}
+			return nil, nil, testutil.ErrUnexpectedQuery
+		},
+		Exec: func(query string, args []interface{}) (int64, error) {
+			if testutil.IsStatement(query, "DELETE FROM `product_images`") {
+				return 1, nil
+			}
+			return 0, testutil.ErrUnexpectedQuery
+		},
+	})
+
+	if err := DeleteProductImage("p1", "img-1"); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(store.deleted)
+	want := []string{"products/p1/medium.webp", "products/p1/thumb.jpg"}
+	if !slices.Equal(store.deleted, want) {
+		t.Errorf("deleted %v, want %v", store.deleted, want)
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"bytes"
+	"ecommerce-backend/imageproc"
+	"ecommerce-backend/models"
+	"ecommerce-backend/storage"
+	"mime/multipart"
+
+	"github.com/google/uuid"
+)
+
+// UploadedImage adalah hasil pipeline gambar yang sudah tersimpan di storage.
+// URL menunjuk ke rendition "large" dan dipakai sebagai URL utama gambar.
+type UploadedImage struct {
+	URL        string
+	Renditions models.ImageRenditions
+}
+
+// StorageURLs mengembalikan semua file yang diunggah untuk gambar ini.
+func (u *UploadedImage) StorageURLs() []string {
+	return u.Renditions.URLs()
+}
+
+// UploadProductImage memvalidasi isi file, membuat rendition lalu mengunggahnya.
+// Error validasi bisa dikenali dengan imageproc.IsValidationError.
+func UploadProductImage(file *multipart.FileHeader) (*UploadedImage, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	outputs, err := imageproc.Process(f, imageproc.ConfigFromEnv())
+	if err != nil {
+		return nil, err
+	}
+
+	store := storage.Default()
+	uploaded := &UploadedImage{Renditions: models.ImageRenditions{}}
+	prefix := "products/" + uuid.NewString() + "/"
+	for _, out := range outputs {
+		url, err := store.Put(prefix+out.Rendition+out.Extension(), bytes.NewReader(out.Data), int64(len(out.Data)), out.ContentType)
+		if err != nil {
+			deleteStoredImages(uploaded.StorageURLs()...)
+			return nil, err
+		}
+
+		rendition := uploaded.Renditions[out.Rendition]
+		rendition.Width, rendition.Height = out.Width, out.Height
+		if out.Format == "webp" {
+			rendition.WebPURL = url
+		} else {
+			rendition.URL = url
+		}
+		uploaded.Renditions[out.Rendition] = rendition
+	}
+
+	uploaded.URL = uploaded.Renditions[imageproc.Renditions[0].Name].URL
+	return uploaded, nil
+}
+
+// DiscardUploadedImage menghapus file yang sudah diunggah jika langkah berikutnya gagal.
+func DiscardUploadedImage(u *UploadedImage) {
+	if u != nil {
+		deleteStoredImages(u.StorageURLs()...)
+	}
+}
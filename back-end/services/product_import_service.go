@@ -0,0 +1,637 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/spreadsheet"
+	"ecommerce-backend/storage"
+	"ecommerce-backend/utils"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxImportErrors      = 500
+	importProgressEvery  = 25
+	exportBatchSize      = 500
+	maxImportSKULength   = 64
+	maxImportNameLength  = 255
+	defaultImportMaxRows = 5000
+	defaultImportMaxSize = 10 << 20
+)
+
+var (
+	ErrImportInProgress  = errors.New("another import is still running")
+	ErrImportJobNotFound = errors.New("import job not found")
+	ErrImportEmpty       = errors.New("file has no product rows")
+	ErrImportTooLarge    = errors.New("import file is too large")
+	errImportImageURL    = errors.New("image_url must be one of this product's uploaded images; upload new images from the product page")
+)
+
+// Kolom file import/export katalog. id dan sku dipakai untuk mencocokkan produk
+// yang sudah ada; tanpa keduanya baris selalu membuat produk baru. Atribut
+// produk memakai kolom tambahan attr.<key>; sel kosong menghapus nilainya dan
+// atribut tanpa kolom tidak diubah. image_url tidak mengunggah gambar: gambar
+// baru harus lewat pipeline upload, jadi kolom ini hanya bisa memilih gambar
+// galeri produk sebagai primary.
+var productCatalogColumns = []string{"id", "sku", "name", "description", "price", "stock", "category", "image_url"}
+
+var requiredImportColumns = []string{"name", "price", "stock", "category"}
+
+// ImportHeaderError dikembalikan jika header file tidak memuat kolom wajib.
+type ImportHeaderError struct {
+	Missing []string
+}
+
+func (e *ImportHeaderError) Error() string {
+	return "missing required columns: " + strings.Join(e.Missing, ", ")
+}
+
+type productImportRow struct {
+	line        int
+	id          string
+	sku         string
+	name        string
+	description string
+	price       float64
+	stock       int
+	categoryID  string
+	imageURL    string
+	attributes  map[string]string
+}
+
+type productImportRun struct {
+	job        *models.ProductImportJob
+	columns    map[string]int
+	rows       []spreadsheet.Row
+	categories map[string]string
+	seenSKUs   map[string]int
+	// attributeColumns memetakan key atribut ke index kolom attr.<key>
+	attributeColumns map[string]int
+	schemas          map[string][]models.CategoryAttribute
+}
+
+// StartProductImport membaca file lalu menjalankan import di background dan
+// langsung mengembalikan job-nya. Setiap seller hanya boleh punya satu import aktif.
+func StartProductImport(sellerID string, file *multipart.FileHeader, dryRun bool) (*models.ProductImportJob, error) {
+	format, err := spreadsheet.FormatFromName(file.Filename)
+	if err != nil {
+		return nil, err
+	}
+	if file.Size > int64(utils.GetEnvInt("PRODUCT_IMPORT_MAX_BYTES", defaultImportMaxSize)) {
+		return nil, ErrImportTooLarge
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	maxRows := utils.GetEnvInt("PRODUCT_IMPORT_MAX_ROWS", defaultImportMaxRows)
+	rows, err := spreadsheet.Read(format, f, maxRows+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) < 2 {
+		return nil, ErrImportEmpty
+	}
+	columns, err := importColumns(rows[0].Cells)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ProductImportJob{
+		SellerID:  sellerID,
+		FileName:  file.Filename,
+		Format:    format,
+		DryRun:    dryRun,
+		Status:    models.ProductImportPending,
+		TotalRows: len(rows) - 1,
+		Errors:    []models.ProductImportRowError{},
+	}
+	// Baris user seller dikunci supaya dua upload bersamaan tidak sama-sama
+	// lolos pengecekan import aktif.
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var seller models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&seller, "id = ?", sellerID).Error; err != nil {
+			return err
+		}
+		var active int64
+		if err := tx.Model(&models.ProductImportJob{}).
+			Where("seller_id = ? AND status IN ?", sellerID, []string{models.ProductImportPending, models.ProductImportRunning}).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return ErrImportInProgress
+		}
+		return tx.Create(job).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	run := &productImportRun{
+		job:              job,
+		columns:          columns,
+		rows:             rows[1:],
+		seenSKUs:         map[string]int{},
+		attributeColumns: map[string]int{},
+		schemas:          map[string][]models.CategoryAttribute{},
+	}
+	for name, i := range columns {
+		if key, ok := strings.CutPrefix(name, "attr."); ok && key != "" {
+			run.attributeColumns[key] = i
+		}
+	}
+	go run.execute()
+	return job, nil
+}
+
+func GetProductImportJob(sellerID, jobID string) (*models.ProductImportJob, error) {
+	var job models.ProductImportJob
+	err := config.DB.First(&job, "id = ? AND seller_id = ?", jobID, sellerID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrImportJobNotFound
+	}
+	return &job, err
+}
+
+func GetProductImportJobs(sellerID string) ([]models.ProductImportJob, error) {
+	var jobs []models.ProductImportJob
+	err := config.DB.Omit("errors").
+		Where("seller_id = ?", sellerID).
+		Order("created_at DESC").
+		Limit(20).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FailInterruptedProductImports menandai job yang masih berjalan saat server
+// berhenti sebagai gagal, karena goroutine-nya sudah tidak ada.
+func FailInterruptedProductImports() {
+	now := time.Now()
+	err := config.DB.Model(&models.ProductImportJob{}).
+		Where("status IN ?", []string{models.ProductImportPending, models.ProductImportRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.ProductImportFailed,
+			"error":       "import interrupted by server restart",
+			"finished_at": now,
+		}).Error
+	if err != nil {
+		log.Printf("Failed to mark interrupted product imports: %v", err)
+	}
+}
+
+// ExportSellerProducts mengembalikan katalog seller dalam format yang sama
+// dengan file import, termasuk baris header.
+func ExportSellerProducts(sellerID string) ([][]string, error) {
+	var attributeKeys []string
+	if err := config.DB.Table("product_attribute_values pav").
+		Joins("JOIN category_attributes ca ON ca.id = pav.attribute_id").
+		Joins("JOIN products p ON p.id = pav.product_id").
+		Where("p.seller_id = ?", sellerID).
+		Distinct().
+		Order("ca.attr_key").
+		Pluck("ca.attr_key", &attributeKeys).Error; err != nil {
+		return nil, err
+	}
+
+	header := append([]string{}, productCatalogColumns...)
+	for _, key := range attributeKeys {
+		header = append(header, "attr."+key)
+	}
+	rows := [][]string{header}
+
+	var products []models.Product
+	err := config.DB.Preload("Category").
+		Preload("Attributes.Attribute").
+		Where("seller_id = ?", sellerID).
+		Order("created_at ASC").
+		FindInBatches(&products, exportBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, p := range products {
+				sku, category := "", ""
+				if p.SKU != nil {
+					sku = *p.SKU
+				}
+				if p.Category != nil {
+					category = p.Category.Name
+				}
+				row := []string{
+					p.ID,
+					sku,
+					p.Name,
+					p.Description,
+					strconv.FormatFloat(p.Price, 'f', -1, 64),
+					strconv.Itoa(p.Stock),
+					category,
+					p.ImageURL,
+				}
+				values := make(map[string]string, len(p.Attributes))
+				for _, v := range p.Attributes {
+					if v.Attribute != nil {
+						values[v.Attribute.Key] = v.TextValue
+					}
+				}
+				for _, key := range attributeKeys {
+					row = append(row, values[key])
+				}
+				rows = append(rows, row)
+			}
+			return nil
+		}).Error
+	return rows, err
+}
+
+func importColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, dup := columns[name]; !dup && name != "" {
+			columns[name] = i
+		}
+	}
+
+	var missing []string
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &ImportHeaderError{Missing: missing}
+	}
+	return columns, nil
+}
+
+func (r *productImportRun) execute() {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Printf("Product import %s panicked: %v", r.job.ID, rec)
+			r.finish(fmt.Errorf("internal error"))
+		}
+	}()
+
+	now := time.Now()
+	r.job.Status = models.ProductImportRunning
+	r.job.StartedAt = &now
+	if err := config.DB.Model(r.job).Select("status", "started_at").Updates(r.job).Error; err != nil {
+		r.finish(err)
+		return
+	}
+
+	if err := r.loadCategories(); err != nil {
+		r.finish(err)
+		return
+	}
+
+	for _, fileRow := range r.rows {
+		line := fileRow.Line
+		row, rowErrors := r.parseRow(line, fileRow.Cells)
+		if len(rowErrors) == 0 {
+			if err := r.applyRow(row); err != nil {
+				rowErrors = append(rowErrors, models.ProductImportRowError{Row: line, Message: err.Error()})
+			}
+		}
+		if len(rowErrors) > 0 {
+			r.job.FailedCount++
+			for _, e := range rowErrors {
+				if len(r.job.Errors) < maxImportErrors {
+					r.job.Errors = append(r.job.Errors, e)
+				}
+			}
+		}
+
+		r.job.ProcessedRows++
+		if r.job.ProcessedRows%importProgressEvery == 0 {
+			r.saveProgress()
+		}
+	}
+
+	r.finish(nil)
+}
+
+func (r *productImportRun) loadCategories() error {
+	var categories []models.Category
+	if err := config.DB.Find(&categories).Error; err != nil {
+		return err
+	}
+	r.categories = make(map[string]string, len(categories)*2)
+	for _, c := range categories {
+		r.categories[strings.ToLower(c.Name)] = c.ID
+		r.categories[c.ID] = c.ID
+	}
+	return nil
+}
+
+func (r *productImportRun) cell(cells []string, column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(cells) {
+		return ""
+	}
+	return strings.TrimSpace(cells[i])
+}
+
+// parseRow memvalidasi satu baris dan mengembalikan semua error sekaligus,
+// supaya seller bisa memperbaiki file dalam satu kali jalan.
+func (r *productImportRun) parseRow(line int, cells []string) (productImportRow, []models.ProductImportRowError) {
+	row := productImportRow{
+		line:        line,
+		id:          r.cell(cells, "id"),
+		sku:         r.cell(cells, "sku"),
+		name:        r.cell(cells, "name"),
+		description: r.cell(cells, "description"),
+		imageURL:    r.cell(cells, "image_url"),
+		attributes:  make(map[string]string, len(r.attributeColumns)),
+	}
+	for key := range r.attributeColumns {
+		row.attributes[key] = r.cell(cells, "attr."+key)
+	}
+	var errs []models.ProductImportRowError
+	fail := func(column, message string) {
+		errs = append(errs, models.ProductImportRowError{Row: line, Column: column, Message: message})
+	}
+
+	if row.sku != "" {
+		if len(row.sku) > maxImportSKULength {
+			fail("sku", fmt.Sprintf("must be at most %d characters", maxImportSKULength))
+		} else if first, dup := r.seenSKUs[row.sku]; dup {
+			fail("sku", fmt.Sprintf("duplicate of row %d", first))
+		} else {
+			r.seenSKUs[row.sku] = line
+		}
+	}
+
+	if row.name == "" {
+		fail("name", "is required")
+	} else if len(row.name) > maxImportNameLength {
+		fail("name", fmt.Sprintf("must be at most %d characters", maxImportNameLength))
+	}
+
+	price, err := strconv.ParseFloat(r.cell(cells, "price"), 64)
+	if err != nil || price <= 0 {
+		fail("price", "must be a number greater than 0")
+	}
+	row.price = price
+
+	stock, err := strconv.Atoi(r.cell(cells, "stock"))
+	if err != nil || stock < 0 {
+		fail("stock", "must be a whole number of at least 0")
+	}
+	row.stock = stock
+
+	category := r.cell(cells, "category")
+	if id, ok := r.categories[strings.ToLower(category)]; ok {
+		row.categoryID = id
+	} else if category == "" {
+		fail("category", "is required")
+	} else {
+		fail("category", fmt.Sprintf("unknown category %q", category))
+	}
+
+	if row.imageURL != "" {
+		if _, ok := storage.Default().KeyFromURL(row.imageURL); !ok {
+			fail("image_url", "external image URLs are not supported; upload images from the product page")
+		}
+	}
+
+	return row, errs
+}
+
+// applyRow mencocokkan produk lewat id lalu sku milik seller. Produk yang cocok
+// diperbarui, selain itu dibuat produk baru. Saat dry run hanya penghitungnya
+// yang diperbarui.
+func (r *productImportRun) applyRow(row productImportRow) error {
+	var product *models.Product
+	created := false
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := findImportTarget(tx, r.job.SellerID, row)
+		if err != nil {
+			return err
+		}
+		attributes, err := r.attributeValues(tx, row, existing)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			created = true
+			if row.imageURL != "" {
+				return errImportImageURL
+			}
+			if r.job.DryRun {
+				return nil
+			}
+			status, err := InitialProductStatus("", false)
+			if err != nil {
+				return err
+			}
+			product = &models.Product{
+				Status:      status,
+				Name:        row.name,
+				Description: row.description,
+				Price:       row.price,
+				Stock:       row.stock,
+				SellerID:    r.job.SellerID,
+				CategoryID:  row.categoryID,
+				Attributes:  attributes,
+			}
+			if row.sku != "" {
+				product.SKU = &row.sku
+			}
+			return tx.Create(product).Error
+		}
+
+		var primary *models.ProductImage
+		if row.imageURL != "" && row.imageURL != existing.ImageURL {
+			var image models.ProductImage
+			err := tx.First(&image, "product_id = ? AND url = ?", existing.ID, row.imageURL).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errImportImageURL
+			}
+			if err != nil {
+				return err
+			}
+			primary = &image
+		}
+
+		if r.job.DryRun {
+			return nil
+		}
+		product = existing
+		updates := map[string]interface{}{
+			"name":        row.name,
+			"description": row.description,
+			"price":       row.price,
+			"stock":       row.stock,
+			"category_id": row.categoryID,
+		}
+		if row.sku != "" {
+			updates["sku"] = row.sku
+		}
+		// Harga dan stok produk bervarian mengikuti variannya
+		hasVariants, err := hasActiveVariants(tx, product.ID)
+		if err != nil {
+			return err
+		}
+		if hasVariants {
+			delete(updates, "price")
+			delete(updates, "stock")
+		}
+		// Import dijalankan seller, jadi edit konten produk published perlu direview ulang
+		before := *existing
+		if err := tx.Where("product_id = ?", existing.ID).Find(&before.Attributes).Error; err != nil {
+			return err
+		}
+		after := before
+		after.Name, after.Description, after.CategoryID, after.Attributes = row.name, row.description, row.categoryID, attributes
+		if primary != nil {
+			after.ImageURL = primary.URL
+		}
+		requireReview := NeedsReviewAfterEdit(&before, &after, false)
+
+		if err := tx.Model(product).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := replaceProductAttributes(tx, product.ID, attributes); err != nil {
+			return err
+		}
+		if requireReview {
+			if err := sendBackToReview(tx, product); err != nil {
+				return err
+			}
+		}
+
+		if primary != nil {
+			if err := setPrimaryImage(tx, product.ID, primary); err != nil {
+				return err
+			}
+			product.ImageURL, product.ImageRenditions = primary.URL, primary.Renditions
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if created {
+		r.job.CreatedCount++
+	} else {
+		r.job.UpdatedCount++
+	}
+	if product == nil {
+		return nil
+	}
+
+	indexProduct(product)
+	return nil
+}
+
+// attributeValues menggabungkan nilai atribut produk yang ada dengan kolom
+// attr.<key> di baris lalu memvalidasinya terhadap skema kategori baris.
+func (r *productImportRun) attributeValues(tx *gorm.DB, row productImportRow, existing *models.Product) ([]models.ProductAttributeValue, error) {
+	schema, ok := r.schemas[row.categoryID]
+	if !ok {
+		if err := tx.Where("category_id = ?", row.categoryID).Order("position").Find(&schema).Error; err != nil {
+			return nil, err
+		}
+		r.schemas[row.categoryID] = schema
+	}
+
+	input := map[string]string{}
+	if existing != nil {
+		known := make(map[string]bool, len(schema))
+		for _, attr := range schema {
+			known[attr.Key] = true
+		}
+		var current []models.ProductAttributeValue
+		if err := tx.Preload("Attribute").Where("product_id = ?", existing.ID).Find(&current).Error; err != nil {
+			return nil, err
+		}
+		for _, v := range current {
+			if v.Attribute != nil && known[v.Attribute.Key] {
+				input[v.Attribute.Key] = v.TextValue
+			}
+		}
+	}
+	for key, value := range row.attributes {
+		if value == "" {
+			delete(input, key)
+		} else {
+			input[key] = value
+		}
+	}
+	return validateAttributeValues(schema, input)
+}
+
+func findImportTarget(tx *gorm.DB, sellerID string, row productImportRow) (*models.Product, error) {
+	var product models.Product
+	locked := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+
+	if row.id != "" {
+		err := locked.First(&product, "id = ? AND seller_id = ?", row.id, sellerID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product id not found in your catalog")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row.sku != "" && (product.SKU == nil || *product.SKU != row.sku) {
+			var count int64
+			if err := tx.Model(&models.Product{}).
+				Where("seller_id = ? AND sku = ? AND id <> ?", sellerID, row.sku, product.ID).
+				Count(&count).Error; err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				return nil, ErrSKUTaken
+			}
+		}
+		return &product, nil
+	}
+
+	if row.sku == "" {
+		return nil, nil
+	}
+	err := locked.First(&product, "seller_id = ? AND sku = ?", sellerID, row.sku).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *productImportRun) saveProgress() {
+	err := config.DB.Model(r.job).
+		Select("processed_rows", "created_count", "updated_count", "failed_count", "errors").
+		Updates(r.job).Error
+	if err != nil {
+		log.Printf("Failed to save progress of product import %s: %v", r.job.ID, err)
+	}
+}
+
+func (r *productImportRun) finish(err error) {
+	now := time.Now()
+	r.job.FinishedAt = &now
+	r.job.Status = models.ProductImportCompleted
+	if err != nil {
+		r.job.Status = models.ProductImportFailed
+		r.job.Error = err.Error()
+	}
+	if err := config.DB.Model(r.job).
+		Select("status", "error", "finished_at", "processed_rows", "created_count", "updated_count", "failed_count", "errors").
+		Updates(r.job).Error; err != nil {
+		log.Printf("Failed to finish product import %s: %v", r.job.ID, err)
+	}
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultProductPageSize = 20
+	maxProductPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// productSort mendefinisikan kolom urutan. Setiap kolom punya index komposit
+// (status, kolom, id) di models.Product, jadi filter status, urutan, dan
+// tie-breaker id untuk keyset pagination dilayani satu index tanpa bergantung
+// pada primary key yang ditambahkan InnoDB secara implisit ke secondary index.
+type productSort struct {
+	column string
+	desc   bool
+}
+
+var productSorts = map[string]productSort{
+	"newest":       {column: "created_at", desc: true},
+	"price_asc":    {column: "price", desc: false},
+	"price_desc":   {column: "price", desc: true},
+	"rating":       {column: "rating", desc: true},
+	"best_selling": {column: "sold_count", desc: true},
+}
+
+type ProductListParams struct {
+	CategoryID string
+	Category   string // nama kategori, untuk kompatibilitas dengan ?category=
+	SellerID   string
+	MinPrice   *float64
+	MaxPrice   *float64
+	MinRating  *float64
+	InStock    bool
+	Sort       string
+	// Statuses kosong berarti hanya produk published (listing publik).
+	Statuses   []string
+	Attributes []AttributeFilter
+	// Facets meminta jumlah produk per nilai atribut ikut dihitung.
+	Facets bool
+
+	Page  int
+	Limit int
+	// Cursor dipakai jika UseCursor true; string kosong berarti halaman pertama.
+	Cursor    string
+	UseCursor bool
+}
+
+type PageMeta struct {
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      *int64 `json:"total,omitempty"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type ProductPage struct {
+	Data       []models.Product `json:"data"`
+	Pagination PageMeta         `json:"pagination"`
+	Facets     []Facet          `json:"facets,omitempty"`
+}
+
+type productCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// GetProducts mengembalikan satu halaman produk. Mode offset (page) menghitung total,
+// sedangkan mode cursor tidak melakukan COUNT sehingga tetap cepat di katalog besar.
+func GetProducts(params ProductListParams) (*ProductPage, error) {
+	sort, ok := productSorts[params.Sort]
+	if !ok {
+		sort = productSorts["newest"]
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultProductPageSize
+	}
+	if params.Limit > maxProductPageSize {
+		params.Limit = maxProductPageSize
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+
+	query := applyProductFilters(config.DB.Model(&models.Product{}), params)
+	meta := PageMeta{Limit: params.Limit}
+
+	if params.UseCursor {
+		if params.Cursor != "" {
+			cursor, err := decodeProductCursor(params.Cursor)
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseCursorValue(sort.column, cursor.Value)
+			if err != nil {
+				return nil, err
+			}
+			op := ">"
+			if sort.desc {
+				op = "<"
+			}
+			// Row comparison supaya MySQL bisa memakai range scan pada index (status, kolom, id)
+			query = query.Where("("+sort.column+", id) "+op+" (?, ?)", value, cursor.ID)
+		}
+	} else {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		meta.Page = params.Page
+		meta.Total = &total
+		meta.TotalPages = int((total + int64(params.Limit) - 1) / int64(params.Limit))
+		query = query.Offset((params.Page - 1) * params.Limit)
+	}
+
+	direction := " ASC"
+	if sort.desc {
+		direction = " DESC"
+	}
+
+	var products []models.Product
+	err := query.
+		Preload("Seller", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Preload("Category").
+		Order(sort.column + direction).
+		Order("id" + direction).
+		Limit(params.Limit + 1).
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(products) > params.Limit {
+		products = products[:params.Limit]
+		meta.HasMore = true
+		meta.NextCursor = encodeProductCursor(sort.column, products[len(products)-1])
+	}
+
+	for i := range products {
+		if products[i].Seller != nil {
+			products[i].SellerName = products[i].Seller.Name
+		}
+		if products[i].Category != nil {
+			products[i].CategoryName = products[i].Category.Name
+		}
+	}
+
+	page := &ProductPage{Data: products, Pagination: meta}
+	if params.Facets {
+		if page.Facets, err = productFacets(params, nil); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+func applyProductFilters(query *gorm.DB, params ProductListParams) *gorm.DB {
+	if len(params.Statuses) == 0 {
+		query = query.Where("status = ?", models.ProductStatusPublished)
+	} else {
+		query = query.Where("status IN ?", params.Statuses)
+	}
+	if params.CategoryID != "" {
+		query = query.Where("category_id = ?", params.CategoryID)
+	}
+	if params.Category != "" {
+		query = query.Where("category_id IN (?)", config.DB.Model(&models.Category{}).Select("id").Where("name = ?", params.Category))
+	}
+	if params.SellerID != "" {
+		query = query.Where("seller_id = ?", params.SellerID)
+	}
+	if params.MinPrice != nil {
+		query = query.Where("price >= ?", *params.MinPrice)
+	}
+	if params.MaxPrice != nil {
+		query = query.Where("price <= ?", *params.MaxPrice)
+	}
+	if params.MinRating != nil {
+		query = query.Where("rating >= ?", *params.MinRating)
+	}
+	if params.InStock {
+		query = query.Where("stock > 0")
+	}
+	return applyAttributeFilters(query, params.Attributes)
+}
+
+func encodeProductCursor(column string, p models.Product) string {
+	var value string
+	switch column {
+	case "price":
+		value = strconv.FormatFloat(p.Price, 'f', -1, 64)
+	case "rating":
+		value = strconv.FormatFloat(p.Rating, 'f', -1, 64)
+	case "sold_count":
+		value = strconv.Itoa(p.SoldCount)
+	default:
+		value = p.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	raw, _ := json.Marshal(productCursor{Value: value, ID: p.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeProductCursor(encoded string) (*productCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor productCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+func parseCursorValue(column, value string) (interface{}, error) {
+	switch column {
+	case "price", "rating":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return v, nil
+	case "sold_count":
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return v, nil
+	default:
+		v, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return v, nil
+	}
+}
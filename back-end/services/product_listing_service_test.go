@@ -0,0 +1,52 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"ecommerce-backend/models"
+	"ecommerce-backend/testutil"
+)
+
+func TestGetProductsCursorUsesRowComparison(t *testing.T) {
+	tests := []struct {
+		sort      string
+		predicate string
+		order     string
+	}{
+		{"price_asc", "(price, id) > (?, ?)", "ORDER BY price ASC,id ASC"},
+		{"price_desc", "(price, id) < (?, ?)", "ORDER BY price DESC,id DESC"},
+		{"rating", "(rating, id) < (?, ?)", "ORDER BY rating DESC,id DESC"},
+		{"best_selling", "(sold_count, id) < (?, ?)", "ORDER BY sold_count DESC,id DESC"},
+		{"newest", "(created_at, id) < (?, ?)", "ORDER BY created_at DESC,id DESC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			column := productSorts[tt.sort].column
+			cursor := encodeProductCursor(column, models.Product{ID: "p-9", Price: 12.5, Rating: 4.5, SoldCount: 3})
+
+			var query string
+			var args []interface{}
+			testutil.UseFakeDB(t, &testutil.FakeDB{
+				Query: func(q string, a []interface{}) ([]string, [][]interface{}, error) {
+					if !testutil.IsStatement(q, "SELECT * FROM `products`") {
+						return nil, nil, testutil.ErrUnexpectedQuery
+					}
+					query, args = q, a
+					return []string{"id"}, nil, nil
+				},
+			})
+
+			if _, err := GetProducts(ProductListParams{Sort: tt.sort, UseCursor: true, Cursor: cursor, Limit: 10}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(query, tt.predicate) || !strings.Contains(query, tt.order) {
+				t.Errorf("query = %s, want %s and %s", query, tt.predicate, tt.order)
+			}
+			// status, nilai cursor, id cursor, lalu LIMIT
+			if len(args) != 4 || args[2] != "p-9" {
+				t.Errorf("args = %v, want the cursor value and id bound once each", args)
+			}
+		})
+	}
+}
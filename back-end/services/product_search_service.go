@@ -0,0 +1,274 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/search"
+	"ecommerce-backend/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	snippetLength         = 160
+	searchFilterBatchSize = 1000
+)
+
+type ProductSearchHit struct {
+	models.Product
+	Score float64 `json:"score"`
+	// Highlights berisi HTML yang sudah di-escape dengan kata yang cocok dibungkus <mark>.
+	Highlights map[string]string `json:"highlights"`
+}
+
+type ProductSearchPage struct {
+	Data       []ProductSearchHit `json:"data"`
+	Pagination PageMeta           `json:"pagination"`
+	// DidYouMean berisi query hasil koreksi ejaan jika pencarian tidak menemukan apa pun.
+	DidYouMean string  `json:"did_you_mean,omitempty"`
+	Facets     []Facet `json:"facets"`
+}
+
+// SearchProducts mencari produk berdasarkan relevansi lalu menerapkan filter
+// listing yang sama dengan GetProducts (kategori, harga, rating, stok, atribut).
+// Facet atribut dihitung dari kandidat yang lolos filter, kecuali facet atribut
+// yang sedang difilter (lihat productFacets).
+// Urutan selalu berdasarkan skor; params.Sort dan cursor diabaikan.
+// searcher mengidentifikasi client (IP) untuk statistik query populer.
+func SearchProducts(query string, params ProductListParams, searcher string) (*ProductSearchPage, error) {
+	if params.Limit <= 0 {
+		params.Limit = defaultProductPageSize
+	}
+	if params.Limit > maxProductPageSize {
+		params.Limit = maxProductPageSize
+	}
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+
+	// Semua dokumen yang cocok diambil (hanya ID dan skor) supaya filter, total
+	// dan paginasi dihitung dari hasil sebenarnya, bukan dari N kandidat teratas.
+	hits, err := search.Default().Search(search.Query{Text: query})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(hits))
+	scores := make(map[string]float64, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+		scores[h.ID] = h.Score
+	}
+
+	// Filter dijalankan per batch supaya daftar IN tetap kecil untuk query yang sangat umum
+	var matching []string
+	for start := 0; start < len(ids); start += searchFilterBatchSize {
+		end := min(start+searchFilterBatchSize, len(ids))
+		var batch []string
+		if err := applyProductFilters(config.DB.Model(&models.Product{}), params).
+			Where("id IN ?", ids[start:end]).
+			Pluck("id", &batch).Error; err != nil {
+			return nil, err
+		}
+		matching = append(matching, batch...)
+	}
+	allowed := make(map[string]bool, len(matching))
+	for _, id := range matching {
+		allowed[id] = true
+	}
+	ranked := make([]string, 0, len(matching))
+	for _, id := range ids {
+		if allowed[id] {
+			ranked = append(ranked, id)
+		}
+	}
+
+	total := int64(len(ranked))
+	meta := PageMeta{
+		Page:       params.Page,
+		Limit:      params.Limit,
+		Total:      &total,
+		TotalPages: int((total + int64(params.Limit) - 1) / int64(params.Limit)),
+	}
+
+	start := (params.Page - 1) * params.Limit
+	if start > len(ranked) {
+		start = len(ranked)
+	}
+	end := start + params.Limit
+	if end > len(ranked) {
+		end = len(ranked)
+	}
+	pageIDs := ranked[start:end]
+	meta.HasMore = end < len(ranked)
+
+	data := make([]ProductSearchHit, 0, len(pageIDs))
+	if len(pageIDs) > 0 {
+		var products []models.Product
+		if err := config.DB.
+			Preload("Seller", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+			Preload("Category").
+			Where("id IN ?", pageIDs).
+			Find(&products).Error; err != nil {
+			return nil, err
+		}
+		byID := make(map[string]models.Product, len(products))
+		for _, p := range products {
+			if p.Seller != nil {
+				p.SellerName = p.Seller.Name
+			}
+			if p.Category != nil {
+				p.CategoryName = p.Category.Name
+			}
+			byID[p.ID] = p
+		}
+		for _, id := range pageIDs {
+			p, ok := byID[id]
+			if !ok {
+				continue
+			}
+			data = append(data, ProductSearchHit{
+				Product: p,
+				Score:   scores[id],
+				Highlights: map[string]string{
+					search.FieldName:        search.Highlight(p.Name, query, 0),
+					search.FieldDescription: search.Highlight(p.Description, query, snippetLength),
+				},
+			})
+		}
+	}
+
+	result := &ProductSearchPage{Data: data, Pagination: meta, Facets: []Facet{}}
+	// Tanpa filter atribut, ranked sudah berisi tepat produk yang cocok. Dengan filter
+	// atribut, facet atribut itu butuh kandidat yang tidak lolos filternya sendiri,
+	// jadi semua hit dipakai dan filter lain diterapkan ulang per batch.
+	candidates := ranked
+	if len(params.Attributes) > 0 {
+		candidates = ids
+	}
+	if len(candidates) > 0 {
+		facets, err := searchFacets(params, candidates)
+		if err != nil {
+			return nil, err
+		}
+		result.Facets = facets
+	}
+	if params.Page == 1 {
+		recordSearchQuery(query, total, searcher)
+	}
+	if total == 0 {
+		if suggestion, err := search.DidYouMean(search.Default(), query); err != nil {
+			log.Printf("Failed to build search suggestion: %v", err)
+		} else {
+			result.DidYouMean = suggestion
+		}
+	}
+	return result, nil
+}
+
+func productDocument(p *models.Product) search.Document {
+	return search.Document{ID: p.ID, Name: p.Name, Description: p.Description}
+}
+
+// Kegagalan sinkronisasi index tidak menggagalkan perubahan produk; index bisa
+// dibangun ulang lewat ReindexProducts. Hanya produk published yang diindeks.
+func indexProduct(p *models.Product) {
+	if p.Status != "" && p.Status != models.ProductStatusPublished {
+		removeProductFromIndex(p.ID)
+		return
+	}
+	if err := search.Default().Index(productDocument(p)); err != nil {
+		log.Printf("Failed to index product %s: %v", p.ID, err)
+	}
+}
+
+func removeProductFromIndex(id string) {
+	if err := search.Default().Delete(id); err != nil {
+		log.Printf("Failed to remove product %s from search index: %v", id, err)
+	}
+}
+
+// ReindexProducts membangun ulang index untuk semua produk published dan
+// mengembalikan jumlah yang diindeks. Dokumen produk yang tidak lagi published
+// dihapus dari index, begitu juga kosakata yang tidak dipakai produk mana pun.
+func ReindexProducts() (int, error) {
+	idx := search.Default()
+	started := time.Now()
+	var hidden []string
+	if err := config.DB.Model(&models.Product{}).
+		Where("status <> ?", models.ProductStatusPublished).
+		Pluck("id", &hidden).Error; err != nil {
+		return 0, err
+	}
+	for _, id := range hidden {
+		if err := idx.Delete(id); err != nil {
+			return 0, err
+		}
+	}
+
+	indexed := 0
+	var products []models.Product
+	err := config.DB.Select("id", "name", "description").
+		Where("status = ?", models.ProductStatusPublished).
+		FindInBatches(&products, 200, func(tx *gorm.DB, batch int) error {
+			for i := range products {
+				if err := idx.Index(productDocument(&products[i])); err != nil {
+					return err
+				}
+				indexed++
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return indexed, err
+	}
+	if _, err := idx.PruneVocabulary(started); err != nil {
+		return indexed, err
+	}
+	return indexed, nil
+}
+
+// StartSearchMaintenance menjalankan pemeliharaan search secara berkala
+// (SEARCH_MAINTENANCE_INTERVAL, default 24 jam): statistik query lama dihapus dan
+// index dibangun ulang, yang sekaligus memperbaiki dokumen yang gagal disinkronkan
+// dan membuang kosakata yang tidak terpakai.
+func StartSearchMaintenance() {
+	ticker := time.NewTicker(utils.GetEnvDuration("SEARCH_MAINTENANCE_INTERVAL", 24*time.Hour))
+	defer ticker.Stop()
+	for range ticker.C {
+		if pruned, err := PruneSearchQueries(); err != nil {
+			log.Printf("Failed to prune search queries: %v", err)
+		} else if pruned > 0 {
+			log.Printf("Pruned %d search query stats", pruned)
+		}
+		if _, err := ReindexProducts(); err != nil {
+			log.Printf("Failed to rebuild search index: %v", err)
+		}
+	}
+}
+
+// EnsureSearchIndex mengisi index saat pertama kali dijalankan di database yang sudah berisi produk.
+func EnsureSearchIndex() {
+	idx := search.Default()
+	count, err := idx.Count()
+	if err != nil {
+		log.Printf("Failed to read search index: %v", err)
+		return
+	}
+	if count > 0 {
+		// Index dari versi sebelum ada kosakata autocomplete juga perlu dibangun ulang
+		if words, err := idx.Vocabulary("", 1); err != nil || len(words) > 0 {
+			return
+		}
+	}
+	indexed, err := ReindexProducts()
+	if err != nil {
+		log.Printf("Failed to build search index: %v", err)
+		return
+	}
+	if indexed > 0 {
+		log.Printf("Search index built for %d products", indexed)
+	}
+}
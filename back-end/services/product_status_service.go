@@ -0,0 +1,158 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/utils"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidProductStatus    = errors.New("invalid product status")
+	ErrInvalidStatusTransition = errors.New("product status cannot be changed that way")
+	ErrProductUnavailable      = errors.New("product is not available for purchase")
+)
+
+// Perpindahan status yang boleh dilakukan seller. Admin (policy.ProductsReview)
+// boleh memindahkan ke status apa pun, termasuk menyetujui pending_review.
+var sellerStatusTransitions = map[string][]string{
+	models.ProductStatusDraft:         {models.ProductStatusPublished, models.ProductStatusArchived},
+	models.ProductStatusPendingReview: {models.ProductStatusDraft, models.ProductStatusArchived},
+	models.ProductStatusPublished:     {models.ProductStatusDraft, models.ProductStatusArchived},
+	models.ProductStatusArchived:      {models.ProductStatusDraft},
+}
+
+// productReviewRequired: jika PRODUCT_REVIEW_REQUIRED=true, produk yang
+// dipublikasikan seller masuk pending_review dulu sampai disetujui admin.
+func productReviewRequired() bool {
+	return utils.GetEnvBool("PRODUCT_REVIEW_REQUIRED", false)
+}
+
+// InitialProductStatus menentukan status produk baru. requested kosong berarti
+// published, seperti perilaku sebelum ada status.
+func InitialProductStatus(requested string, canReview bool) (string, error) {
+	switch requested {
+	case "", models.ProductStatusPublished:
+		if productReviewRequired() && !canReview {
+			return models.ProductStatusPendingReview, nil
+		}
+		return models.ProductStatusPublished, nil
+	case models.ProductStatusDraft:
+		return models.ProductStatusDraft, nil
+	default:
+		return "", ErrInvalidProductStatus
+	}
+}
+
+// ChangeProductStatus memindahkan produk ke status target. Produk yang tidak lagi
+// published dihapus dari index pencarian dan keranjang; order dan review lama
+// tidak disentuh.
+func ChangeProductStatus(product *models.Product, target string, canReview bool) error {
+	if !slices.Contains(models.ProductStatuses, target) {
+		return ErrInvalidProductStatus
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(product, "id = ?", product.ID).Error; err != nil {
+			return errors.New("product not found")
+		}
+		if product.Status == target {
+			return nil
+		}
+
+		if !canReview {
+			if !slices.Contains(sellerStatusTransitions[product.Status], target) {
+				return ErrInvalidStatusTransition
+			}
+			if target == models.ProductStatusPublished && productReviewRequired() {
+				target = models.ProductStatusPendingReview
+			}
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{"status": target}
+		switch target {
+		case models.ProductStatusPublished:
+			updates["published_at"] = now
+			updates["archived_at"] = nil
+		case models.ProductStatusArchived:
+			updates["archived_at"] = now
+		default:
+			updates["archived_at"] = nil
+		}
+		if err := tx.Model(product).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if target != models.ProductStatusPublished {
+			return tx.Where("product_id = ?", product.ID).Delete(&models.CartItem{}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	indexProduct(product)
+	return nil
+}
+
+// NeedsReviewAfterEdit bernilai true jika PRODUCT_REVIEW_REQUIRED aktif, produk
+// sedang published, dan pihak yang tidak boleh mereview mengubah konten yang
+// tampil ke pembeli: nama, deskripsi, kategori, gambar utama, atau atribut.
+// Harga dan stok tidak dihitung supaya sinkronisasi stok tidak menarik produk
+// dari katalog.
+func NeedsReviewAfterEdit(before, after *models.Product, canReview bool) bool {
+	if canReview || !productReviewRequired() || before.Status != models.ProductStatusPublished {
+		return false
+	}
+	return before.Name != after.Name ||
+		before.Description != after.Description ||
+		before.CategoryID != after.CategoryID ||
+		before.ImageURL != after.ImageURL ||
+		!maps.Equal(attributeTextValues(before.Attributes), attributeTextValues(after.Attributes))
+}
+
+func attributeTextValues(values []models.ProductAttributeValue) map[string]string {
+	m := make(map[string]string, len(values))
+	for _, v := range values {
+		m[v.AttributeID] = v.TextValue
+	}
+	return m
+}
+
+// sendBackToReview memindahkan produk published ke pending_review dalam transaksi
+// edit, sama seperti saat seller mempublikasikan produk baru. Pemanggil perlu
+// memanggil indexProduct setelah commit supaya produk keluar dari index pencarian.
+func sendBackToReview(tx *gorm.DB, product *models.Product) error {
+	result := tx.Model(&models.Product{}).
+		Where("id = ? AND status = ?", product.ID, models.ProductStatusPublished).
+		Update("status", models.ProductStatusPendingReview)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	product.Status = models.ProductStatusPendingReview
+	return tx.Where("product_id = ?", product.ID).Delete(&models.CartItem{}).Error
+}
+
+// ArchiveProduct menggantikan hapus permanen: produk disembunyikan dari katalog,
+// sedangkan riwayat penjualan, review dan total order tetap utuh.
+func ArchiveProduct(product *models.Product) error {
+	return ChangeProductStatus(product, models.ProductStatusArchived, true)
+}
+
+func requirePublished(product *models.Product) error {
+	if product.Status != models.ProductStatusPublished {
+		return ErrProductUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"testing"
+
+	"ecommerce-backend/models"
+	"ecommerce-backend/search"
+	"ecommerce-backend/testutil"
+)
+
+func publishedProduct() *models.Product {
+	return &models.Product{
+		ID:          "p1",
+		Status:      models.ProductStatusPublished,
+		Name:        "Kaos Polos",
+		Description: "Katun 30s",
+		Price:       50000,
+		Stock:       10,
+		CategoryID:  "c1",
+		ImageURL:    "https://cdn.example.com/uploads/products/p1/a.jpg",
+		Attributes:  []models.ProductAttributeValue{{AttributeID: "color", TextValue: "Hitam"}},
+	}
+}
+
+func TestNeedsReviewAfterEdit(t *testing.T) {
+	tests := []struct {
+		name      string
+		required  string
+		canReview bool
+		status    string
+		edit      func(p *models.Product)
+		want      bool
+	}{
+		{name: "name", required: "true", edit: func(p *models.Product) { p.Name = "Kaos Premium" }, want: true},
+		{name: "description", required: "true", edit: func(p *models.Product) { p.Description = "Klik link ini" }, want: true},
+		{name: "category", required: "true", edit: func(p *models.Product) { p.CategoryID = "c2" }, want: true},
+		{name: "primary image", required: "true", edit: func(p *models.Product) { p.ImageURL = "https://cdn.example.com/uploads/products/p1/b.jpg" }, want: true},
+		{name: "attribute value", required: "true", edit: func(p *models.Product) {
+			p.Attributes = []models.ProductAttributeValue{{AttributeID: "color", TextValue: "Putih"}}
+		}, want: true},
+		{name: "attribute removed", required: "true", edit: func(p *models.Product) { p.Attributes = nil }, want: true},
+		{name: "price and stock only", required: "true", edit: func(p *models.Product) { p.Price, p.Stock = 45000, 3 }},
+		{name: "reviewer edits", required: "true", canReview: true, edit: func(p *models.Product) { p.Name = "Kaos Premium" }},
+		{name: "review not required", required: "false", edit: func(p *models.Product) { p.Name = "Kaos Premium" }},
+		{name: "draft product", required: "true", status: models.ProductStatusDraft, edit: func(p *models.Product) { p.Name = "Kaos Premium" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PRODUCT_REVIEW_REQUIRED", tt.required)
+			before := publishedProduct()
+			if tt.status != "" {
+				before.Status = tt.status
+			}
+			after := *before
+			tt.edit(&after)
+			if got := NeedsReviewAfterEdit(before, &after, tt.canReview); got != tt.want {
+				t.Errorf("NeedsReviewAfterEdit = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateProductSendsBackToReview(t *testing.T) {
+	prev := search.Default()
+	idx := search.NewMemoryIndex()
+	search.SetDefault(idx)
+	t.Cleanup(func() { search.SetDefault(prev) })
+
+	product := publishedProduct()
+	if err := idx.Index(productDocument(product)); err != nil {
+		t.Fatal(err)
+	}
+
+	status := models.ProductStatusPublished
+	cartCleared := false
+	testutil.UseFakeDB(t, &testutil.FakeDB{
+		Query: func(query string, args []interface{}) ([]string, [][]interface{}, error) {
+			if testutil.IsStatement(query, "SELECT count(*) FROM `product_variants`") {
+				return []string{"count(*)"}, [][]interface{}{{int64(0)}}, nil
+			}
+			return nil, nil, testutil.ErrUnexpectedQuery
+		},
+		Exec: func(query string, args []interface{}) (int64, error) {
+			switch {
+			case testutil.IsStatement(query, "UPDATE `products` SET `status`=?"):
+				if status != models.ProductStatusPublished {
+					return 0, nil
+				}
+				status = args[0].(string)
+				return 1, nil
+			case testutil.IsStatement(query, "UPDATE `products` SET"),
+				testutil.IsStatement(query, "DELETE FROM `product_attribute_values`"),
+				testutil.IsStatement(query, "INSERT INTO `product_attribute_values`"):
+				return 1, nil
+			case testutil.IsStatement(query, "DELETE FROM `cart_items`"):
+				cartCleared = true
+				return 1, nil
+			}
+			return 0, testutil.ErrUnexpectedQuery
+		},
+	})
+
+	product.Name = "Kaos Premium"
+	if err := UpdateProduct(product, true); err != nil {
+		t.Fatal(err)
+	}
+	if status != models.ProductStatusPendingReview || product.Status != models.ProductStatusPendingReview {
+		t.Errorf("status = %s (db %s), want pending_review", product.Status, status)
+	}
+	if !cartCleared {
+		t.Error("cart items were not removed")
+	}
+	if n, _ := idx.Count(); n != 0 {
+		t.Errorf("product still in search index (%d documents)", n)
+	}
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/storage"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxProductOptions = 3
+	maxSKULength      = 64
+	maxImageURLLength = 2048
+)
+
+var (
+	ErrVariantRequired = errors.New("variant_id is required for this product")
+	ErrVariantNotFound = errors.New("variant not found")
+	ErrSKUTaken        = errors.New("sku is already used by another of your products")
+)
+
+// VariantValidationError menandai input varian yang tidak valid (400), berbeda
+// dengan error database.
+type VariantValidationError struct {
+	msg string
+}
+
+func (e *VariantValidationError) Error() string { return e.msg }
+
+func invalidVariants(format string, args ...interface{}) error {
+	return &VariantValidationError{msg: fmt.Sprintf(format, args...)}
+}
+
+type ProductOptionInput struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type ProductVariantInput struct {
+	SKU      string            `json:"sku"`
+	Price    float64           `json:"price"`
+	Stock    int               `json:"stock"`
+	ImageURL string            `json:"image_url"`
+	Options  map[string]string `json:"options"`
+}
+
+// ProductVariantsInput menggantikan seluruh sumbu dan varian sebuah produk.
+// Varian dicocokkan berdasarkan SKU sehingga ID varian yang sudah ada (dan
+// referensinya dari keranjang atau order) tetap dipertahankan.
+type ProductVariantsInput struct {
+	Options  []ProductOptionInput  `json:"options"`
+	Variants []ProductVariantInput `json:"variants"`
+}
+
+type ProductVariantUpdate struct {
+	Price    *float64 `json:"price"`
+	Stock    *int     `json:"stock"`
+	ImageURL *string  `json:"image_url"`
+}
+
+// validateVariantImageURL hanya menerima gambar yang diunggah ke storage kita,
+// sama seperti import katalog. String kosong berarti varian memakai gambar produk.
+func validateVariantImageURL(imageURL string) error {
+	if imageURL == "" {
+		return nil
+	}
+	if len(imageURL) > maxImageURLLength {
+		return invalidVariants("image_url must be at most %d characters", maxImageURLLength)
+	}
+	if _, ok := storage.Default().KeyFromURL(imageURL); !ok {
+		return invalidVariants("external image URLs are not supported; upload images from the product page")
+	}
+	return nil
+}
+
+func validateProductVariants(input *ProductVariantsInput) error {
+	if len(input.Options) > maxProductOptions {
+		return invalidVariants("a product can have at most %d options", maxProductOptions)
+	}
+	if len(input.Options) == 0 && len(input.Variants) > 0 {
+		return invalidVariants("variants require at least one option")
+	}
+
+	allowed := make(map[string]map[string]bool, len(input.Options))
+	for i := range input.Options {
+		opt := &input.Options[i]
+		opt.Name = strings.TrimSpace(opt.Name)
+		if opt.Name == "" {
+			return invalidVariants("option name is required")
+		}
+		if _, dup := allowed[opt.Name]; dup {
+			return invalidVariants("duplicate option %q", opt.Name)
+		}
+		if len(opt.Values) == 0 {
+			return invalidVariants("option %q needs at least one value", opt.Name)
+		}
+		values := make(map[string]bool, len(opt.Values))
+		for j, v := range opt.Values {
+			v = strings.TrimSpace(v)
+			if v == "" || values[v] {
+				return invalidVariants("option %q has an empty or duplicate value", opt.Name)
+			}
+			values[v] = true
+			opt.Values[j] = v
+		}
+		allowed[opt.Name] = values
+	}
+
+	skus := make(map[string]bool, len(input.Variants))
+	combinations := make(map[string]bool, len(input.Variants))
+	for i := range input.Variants {
+		v := &input.Variants[i]
+		v.SKU = strings.TrimSpace(v.SKU)
+		if v.SKU == "" || len(v.SKU) > maxSKULength {
+			return invalidVariants("sku is required and must be at most %d characters", maxSKULength)
+		}
+		if skus[v.SKU] {
+			return invalidVariants("duplicate sku %q", v.SKU)
+		}
+		skus[v.SKU] = true
+		if v.Price <= 0 {
+			return invalidVariants("variant %s: price must be greater than 0", v.SKU)
+		}
+		if v.Stock < 0 {
+			return invalidVariants("variant %s: stock cannot be negative", v.SKU)
+		}
+		v.ImageURL = strings.TrimSpace(v.ImageURL)
+		if err := validateVariantImageURL(v.ImageURL); err != nil {
+			return invalidVariants("variant %s: %v", v.SKU, err)
+		}
+		if len(v.Options) != len(allowed) {
+			return invalidVariants("variant %s must set exactly one value for every option", v.SKU)
+		}
+		parts := make([]string, 0, len(v.Options))
+		for name, value := range v.Options {
+			if !allowed[name][value] {
+				return invalidVariants("variant %s: %q is not a valid value for option %q", v.SKU, value, name)
+			}
+			parts = append(parts, name+"="+value)
+		}
+		sort.Strings(parts)
+		key := strings.Join(parts, "|")
+		if combinations[key] {
+			return invalidVariants("variant %s duplicates the options of another variant", v.SKU)
+		}
+		combinations[key] = true
+	}
+	return nil
+}
+
+// SetProductVariants mengganti sumbu dan varian produk. Varian yang tidak ada lagi
+// di input dihapus, atau dinonaktifkan jika sudah pernah dipesan.
+func SetProductVariants(productID string, input ProductVariantsInput) (*models.Product, error) {
+	if err := validateProductVariants(&input); err != nil {
+		return nil, err
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, "id = ?", productID).Error; err != nil {
+			return errors.New("product not found")
+		}
+
+		if len(input.Variants) > 0 {
+			skus := make([]string, len(input.Variants))
+			for i, v := range input.Variants {
+				skus[i] = v.SKU
+			}
+			var taken int64
+			if err := tx.Model(&models.ProductVariant{}).
+				Where("seller_id = ? AND sku IN ? AND product_id <> ?", product.SellerID, skus, productID).
+				Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken > 0 {
+				return ErrSKUTaken
+			}
+		}
+
+		if err := tx.Where("product_id = ?", productID).Delete(&models.ProductOption{}).Error; err != nil {
+			return err
+		}
+		for i, opt := range input.Options {
+			if err := tx.Create(&models.ProductOption{
+				ProductID: productID,
+				Name:      opt.Name,
+				Values:    opt.Values,
+				Position:  i,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		var existing []models.ProductVariant
+		if err := tx.Where("product_id = ?", productID).Find(&existing).Error; err != nil {
+			return err
+		}
+		bySKU := make(map[string]models.ProductVariant, len(existing))
+		for _, v := range existing {
+			bySKU[v.SKU] = v
+		}
+
+		for _, in := range input.Variants {
+			if current, ok := bySKU[in.SKU]; ok {
+				delete(bySKU, in.SKU)
+				// Select supaya nilai nol (stok 0, image_url kosong) tetap ditulis
+				if err := tx.Model(&current).
+					Select("options", "price", "stock", "image_url", "is_active").
+					Updates(models.ProductVariant{
+						Options:  in.Options,
+						Price:    in.Price,
+						Stock:    in.Stock,
+						ImageURL: in.ImageURL,
+						IsActive: true,
+					}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Create(&models.ProductVariant{
+				ProductID: productID,
+				SellerID:  product.SellerID,
+				SKU:       in.SKU,
+				Options:   in.Options,
+				Price:     in.Price,
+				Stock:     in.Stock,
+				ImageURL:  in.ImageURL,
+				IsActive:  true,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, removed := range bySKU {
+			if err := removeProductVariant(tx, removed); err != nil {
+				return err
+			}
+		}
+
+		return syncProductFromVariants(tx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetProductByID(productID)
+}
+
+func removeProductVariant(tx *gorm.DB, variant models.ProductVariant) error {
+	if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.CartItem{}).Error; err != nil {
+		return err
+	}
+	var ordered int64
+	if err := tx.Model(&models.OrderItem{}).Where("variant_id = ?", variant.ID).Count(&ordered).Error; err != nil {
+		return err
+	}
+	if ordered > 0 {
+		return tx.Model(&models.ProductVariant{}).Where("id = ?", variant.ID).
+			Updates(map[string]interface{}{"is_active": false, "stock": 0}).Error
+	}
+	return tx.Delete(&models.ProductVariant{}, "id = ?", variant.ID).Error
+}
+
+// UpdateProductVariant mengubah harga, stok atau gambar satu varian aktif.
+func UpdateProductVariant(productID, variantID string, input ProductVariantUpdate) (*models.ProductVariant, error) {
+	if input.Price != nil && *input.Price <= 0 {
+		return nil, invalidVariants("price must be greater than 0")
+	}
+	if input.Stock != nil && *input.Stock < 0 {
+		return nil, invalidVariants("stock cannot be negative")
+	}
+	if input.ImageURL != nil {
+		imageURL := strings.TrimSpace(*input.ImageURL)
+		if err := validateVariantImageURL(imageURL); err != nil {
+			return nil, err
+		}
+		input.ImageURL = &imageURL
+	}
+
+	var variant models.ProductVariant
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&variant, "id = ? AND product_id = ? AND is_active = ?", variantID, productID, true).Error; err != nil {
+			return ErrVariantNotFound
+		}
+
+		updates := map[string]interface{}{}
+		if input.Price != nil {
+			updates["price"] = *input.Price
+		}
+		if input.Stock != nil {
+			updates["stock"] = *input.Stock
+		}
+		if input.ImageURL != nil {
+			updates["image_url"] = *input.ImageURL
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := tx.Model(&variant).Updates(updates).Error; err != nil {
+			return err
+		}
+		return syncProductFromVariants(tx, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// syncProductFromVariants menyalin harga termurah dan total stok varian aktif ke
+// produk supaya listing, filter harga dan filter stok tetap bekerja tanpa join.
+func syncProductFromVariants(tx *gorm.DB, productID string) error {
+	var agg struct {
+		Count      int64
+		MinPrice   float64
+		TotalStock int64
+	}
+	if err := tx.Model(&models.ProductVariant{}).
+		Select("COUNT(*) AS count, COALESCE(MIN(price), 0) AS min_price, COALESCE(SUM(stock), 0) AS total_stock").
+		Where("product_id = ? AND is_active = ?", productID, true).
+		Scan(&agg).Error; err != nil {
+		return err
+	}
+	if agg.Count == 0 {
+		return nil
+	}
+	return tx.Model(&models.Product{}).Where("id = ?", productID).
+		Updates(map[string]interface{}{"price": agg.MinPrice, "stock": agg.TotalStock}).Error
+}
+
+func hasActiveVariants(db *gorm.DB, productID string) (bool, error) {
+	var count int64
+	err := db.Model(&models.ProductVariant{}).
+		Where("product_id = ? AND is_active = ?", productID, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// resolveVariant memvalidasi pilihan varian untuk keranjang atau order. Produk
+// bervarian wajib memilih varian aktif; produk tanpa varian mengembalikan nil.
+func resolveVariant(db *gorm.DB, productID string, variantID *string) (*models.ProductVariant, error) {
+	if variantID == nil || *variantID == "" {
+		hasVariants, err := hasActiveVariants(db, productID)
+		if err != nil {
+			return nil, err
+		}
+		if hasVariants {
+			return nil, ErrVariantRequired
+		}
+		return nil, nil
+	}
+
+	var variant models.ProductVariant
+	if err := db.First(&variant, "id = ? AND product_id = ? AND is_active = ?", *variantID, productID, true).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVariantNotFound
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
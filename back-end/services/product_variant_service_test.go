@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"ecommerce-backend/storage"
+)
+
+func useTestStorage(t *testing.T) {
+	prev := storage.Default()
+	storage.SetDefault(&storage.LocalStorage{BaseURL: "https://cdn.example.com/uploads"})
+	t.Cleanup(func() { storage.SetDefault(prev) })
+}
+
+func TestValidateProductVariantsImageURL(t *testing.T) {
+	useTestStorage(t)
+
+	tests := []struct {
+		imageURL string
+		wantErr  bool
+	}{
+		{"", false},
+		{"https://cdn.example.com/uploads/products/p1/red.webp", false},
+		{"  https://cdn.example.com/uploads/products/p1/red.webp  ", false},
+		{"https://tracker.example.net/pixel.gif", true},
+		{"javascript:alert(1)", true},
+		{"https://cdn.example.com/uploads/../secrets.txt", true},
+		{"https://cdn.example.com/uploads/" + strings.Repeat("a", maxImageURLLength), true},
+	}
+	for _, tt := range tests {
+		input := ProductVariantsInput{
+			Options:  []ProductOptionInput{{Name: "Color", Values: []string{"Red"}}},
+			Variants: []ProductVariantInput{{SKU: "RED", Price: 10, Options: map[string]string{"Color": "Red"}, ImageURL: tt.imageURL}},
+		}
+		err := validateProductVariants(&input)
+		var verr *VariantValidationError
+		if tt.wantErr != (err != nil) || (err != nil && !errors.As(err, &verr)) {
+			t.Errorf("image_url %.60q: err = %v, wantErr %v", tt.imageURL, err, tt.wantErr)
+		}
+		if err == nil && input.Variants[0].ImageURL != strings.TrimSpace(tt.imageURL) {
+			t.Errorf("image_url was not trimmed: %q", input.Variants[0].ImageURL)
+		}
+	}
+}
+
+func TestUpdateProductVariantRejectsExternalImage(t *testing.T) {
+	useTestStorage(t)
+
+	external := "https://tracker.example.net/pixel.gif"
+	// Validasi terjadi sebelum query apa pun, jadi config.DB tidak disentuh
+	_, err := UpdateProductVariant("p1", "v1", ProductVariantUpdate{ImageURL: &external})
+	var verr *VariantValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("err = %v, want VariantValidationError", err)
+	}
+}
@@ -9,7 +9,13 @@ import (
 )
 
 func CreateReview(review *models.Review) (*models.Review, error) {
-	if err := config.DB.Create(review).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		return refreshProductRating(tx, review.ProductID)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return review, nil
@@ -43,8 +49,40 @@ func DeleteReview(reviewID string) error {
 		return err
 	}
 
-	if err := config.DB.Delete(&review).Error; err != nil {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&review).Error; err != nil {
+			return err
+		}
+		return refreshProductRating(tx, review.ProductID)
+	})
+}
+
+// refreshProductRating menghitung ulang kolom rating dan review_count di products.
+func refreshProductRating(tx *gorm.DB, productID string) error {
+	var stats struct {
+		Avg   float64
+		Count int
+	}
+	if err := tx.Model(&models.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS avg, COUNT(*) AS count").
+		Where("product_id = ?", productID).
+		Scan(&stats).Error; err != nil {
 		return err
 	}
-	return nil
+	return tx.Model(&models.Product{}).Where("id = ?", productID).
+		UpdateColumns(map[string]interface{}{
+			"rating":       stats.Avg,
+			"review_count": stats.Count,
+		}).Error
+}
+
+func GetReviewByID(reviewID string) (*models.Review, error) {
+	var review models.Review
+	if err := config.DB.First(&review, "id = ?", reviewID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("review not found")
+		}
+		return nil, err
+	}
+	return &review, nil
 }
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"ecommerce-backend/search"
+	"ecommerce-backend/utils"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultSuggestionLimit = 5
+	maxSuggestionLimit     = 10
+	maxLoggedQueryLength   = 100
+	// Jumlah kata dari kosakata yang dipakai untuk melengkapi kata terakhir, dan
+	// jumlah kandidat produk yang diambil dari index untuk saran.
+	suggestionCompletions = 10
+	suggestionCandidates  = 50
+)
+
+// Query baru disarankan sebagai "pencarian populer" setelah dicari oleh sejumlah
+// pencari berbeda, supaya satu client tidak bisa mengisi daftar itu sendirian.
+func popularQueryMinSearchers() int {
+	return utils.GetEnvInt("SEARCH_POPULAR_MIN_SEARCHERS", 5)
+}
+
+func searchQueryRetention() time.Duration {
+	return utils.GetEnvDuration("SEARCH_QUERY_RETENTION", 90*24*time.Hour)
+}
+
+type ProductSuggestion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type CategorySuggestion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type SearchSuggestions struct {
+	Query string `json:"query"`
+	// Corrected diisi jika kata terakhir tidak dikenal dan saran diambil dari hasil koreksinya.
+	Corrected  string               `json:"corrected,omitempty"`
+	Products   []ProductSuggestion  `json:"products"`
+	Categories []CategorySuggestion `json:"categories"`
+	Queries    []string             `json:"queries"`
+}
+
+// normalizeQuery menyeragamkan huruf dan spasi supaya "Sepatu  Lari" dan
+// "sepatu lari" dihitung sebagai query yang sama.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// recordSearchQuery mencatat query beserta pencarinya. searcher (IP client)
+// hanya disimpan sebagai hash, dan hanya sampai query mencapai batas populer.
+func recordSearchQuery(query string, resultCount int64, searcher string) {
+	query = normalizeQuery(query)
+	if query == "" || len(query) > maxLoggedQueryLength {
+		return
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"search_count":     gorm.Expr("search_count + 1"),
+				"result_count":     resultCount,
+				"last_searched_at": time.Now(),
+			}),
+		}).Create(&models.SearchQueryStat{
+			Query:          query,
+			SearchCount:    1,
+			ResultCount:    resultCount,
+			LastSearchedAt: time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		var stat models.SearchQueryStat
+		if err := tx.Select("searcher_count").First(&stat, "query = ?", query).Error; err != nil {
+			return err
+		}
+		if searcher == "" || stat.SearcherCount >= popularQueryMinSearchers() {
+			return nil
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.SearchQuerySearcher{
+			Query:       query,
+			SearcherKey: utils.HashToken(searcher),
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.SearchQueryStat{}).Where("query = ?", query).
+			UpdateColumn("searcher_count", gorm.Expr("searcher_count + 1")).Error
+	})
+	if err != nil {
+		log.Printf("Failed to record search query: %v", err)
+	}
+}
+
+// SuggestProducts mengembalikan autocomplete untuk teks yang sedang diketik.
+// Jika kata terakhir tidak cocok dengan kosakata mana pun, kata itu dikoreksi
+// secara fuzzy ("sepato" -> "sepatu") lalu saran dicari ulang.
+func SuggestProducts(query string, limit int) (*SearchSuggestions, error) {
+	if limit <= 0 {
+		limit = defaultSuggestionLimit
+	}
+	if limit > maxSuggestionLimit {
+		limit = maxSuggestionLimit
+	}
+
+	normalized := normalizeQuery(query)
+	result := &SearchSuggestions{
+		Query:      query,
+		Products:   []ProductSuggestion{},
+		Categories: []CategorySuggestion{},
+		Queries:    []string{},
+	}
+	if normalized == "" {
+		return result, nil
+	}
+
+	if corrected, err := correctLastWord(normalized); err != nil {
+		return nil, err
+	} else if corrected != "" {
+		result.Corrected = corrected
+		normalized = corrected
+	}
+
+	products, err := suggestProductsFromIndex(normalized, limit)
+	if err != nil {
+		return nil, err
+	}
+	result.Products = products
+
+	prefix := search.LikePrefix(normalized)
+	// Tabel kategori kecil dan tidak bertambah seiring katalog, jadi pencocokan
+	// awal kata di tengah nama ("% "+prefix) masih murah di sini.
+	wordPrefix := "% " + prefix
+	if err := config.DB.Model(&models.Category{}).
+		Select("id", "name").
+		Where("name LIKE ? OR name LIKE ?", prefix, wordPrefix).
+		Order("name").
+		Limit(limit).
+		Scan(&result.Categories).Error; err != nil {
+		return nil, err
+	}
+
+	if err := config.DB.Model(&models.SearchQueryStat{}).
+		Where("query LIKE ? AND result_count > 0 AND searcher_count >= ?", prefix, popularQueryMinSearchers()).
+		Order("search_count DESC").
+		Limit(limit).
+		Pluck("query", &result.Queries).Error; err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// suggestProductsFromIndex mencari produk lewat search index alih-alih LIKE
+// dengan wildcard di depan. Kata terakhir masih diketik, jadi dilengkapi dengan
+// kata dari kosakata yang diawali kata itu ("sepatu l" -> "sepatu lari ...").
+func suggestProductsFromIndex(normalized string, limit int) ([]ProductSuggestion, error) {
+	idx := search.Default()
+	words := strings.Fields(normalized)
+	completions, err := idx.Vocabulary(words[len(words)-1], suggestionCompletions)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := idx.Search(search.Query{
+		Text:  strings.Join(append(words, completions...), " "),
+		Limit: suggestionCandidates,
+	})
+	if err != nil || len(hits) == 0 {
+		return []ProductSuggestion{}, err
+	}
+
+	ids := make([]string, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	var products []ProductSuggestion
+	if err := config.DB.Model(&models.Product{}).
+		Select("id", "name").
+		Where("id IN ? AND status = ?", ids, models.ProductStatusPublished).
+		Scan(&products).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]ProductSuggestion, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	suggestions := make([]ProductSuggestion, 0, limit)
+	for _, id := range ids {
+		if p, ok := byID[id]; ok && len(suggestions) < limit {
+			suggestions = append(suggestions, p)
+		}
+	}
+	return suggestions, nil
+}
+
+// PruneSearchQueries menghapus statistik query yang tidak dicari lagi selama
+// SEARCH_QUERY_RETENTION beserta hash pencarinya.
+func PruneSearchQueries() (int64, error) {
+	result := config.DB.
+		Where("last_searched_at < ?", time.Now().Add(-searchQueryRetention())).
+		Delete(&models.SearchQueryStat{})
+	return result.RowsAffected, result.Error
+}
+
+// correctLastWord mengembalikan query dengan kata terakhir yang dikoreksi, atau
+// "" jika kata itu sudah menjadi awalan kata yang dikenal.
+func correctLastWord(normalized string) (string, error) {
+	words := strings.Fields(normalized)
+	last := words[len(words)-1]
+
+	idx := search.Default()
+	known, err := idx.Vocabulary(last, 1)
+	if err != nil || len(known) > 0 {
+		return "", err
+	}
+	corrected, ok, err := search.CorrectPrefix(idx, last)
+	if err != nil || !ok {
+		return "", err
+	}
+	words[len(words)-1] = corrected
+	return strings.Join(words, " "), nil
+}
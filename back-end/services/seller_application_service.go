@@ -0,0 +1,198 @@
+package services
+
+import (
+	"ecommerce-backend/config"
+	"ecommerce-backend/models"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSellerApplicationNotFound = errors.New("seller application not found")
+	ErrSellerApplicationPending  = errors.New("you already have a pending seller application")
+	ErrSellerApplicationReviewed = errors.New("seller application has already been reviewed")
+	ErrAlreadySeller             = errors.New("only buyers can apply to become a seller")
+	ErrShopNameTaken             = errors.New("shop name is already taken")
+	ErrRejectionReasonRequired   = errors.New("a reason is required when rejecting an application")
+)
+
+type SellerApplicationInput struct {
+	ShopName  string
+	LegalName string
+	TaxID     string
+	Documents []models.SellerApplicationDocument
+}
+
+func SubmitSellerApplication(userID string, input SellerApplicationInput) (*models.SellerApplication, error) {
+	application := models.SellerApplication{
+		UserID:    userID,
+		ShopName:  strings.TrimSpace(input.ShopName),
+		LegalName: strings.TrimSpace(input.LegalName),
+		TaxID:     strings.TrimSpace(input.TaxID),
+		Status:    models.SellerApplicationPending,
+		Documents: input.Documents,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+		if user.Role != "buyer" {
+			return ErrAlreadySeller
+		}
+
+		var pending int64
+		tx.Model(&models.SellerApplication{}).
+			Where("user_id = ? AND status = ?", userID, models.SellerApplicationPending).
+			Count(&pending)
+		if pending > 0 {
+			return ErrSellerApplicationPending
+		}
+
+		if shopNameTaken(tx, application.ShopName) {
+			return ErrShopNameTaken
+		}
+
+		return tx.Create(&application).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func GetMySellerApplications(userID string) ([]models.SellerApplication, error) {
+	var applications []models.SellerApplication
+	if err := config.DB.Preload("Documents").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&applications).Error; err != nil {
+		return nil, err
+	}
+	return applications, nil
+}
+
+// GetSellerApplications dipakai untuk antrian review admin; status kosong berarti semua.
+func GetSellerApplications(status string) ([]models.SellerApplication, error) {
+	query := config.DB.Preload("User").Preload("Documents").Order("created_at ASC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var applications []models.SellerApplication
+	if err := query.Find(&applications).Error; err != nil {
+		return nil, err
+	}
+	return applications, nil
+}
+
+func GetSellerApplicationByID(id string) (*models.SellerApplication, error) {
+	var application models.SellerApplication
+	if err := config.DB.Preload("User").Preload("Documents").First(&application, "id = ?", id).Error; err != nil {
+		return nil, ErrSellerApplicationNotFound
+	}
+	return &application, nil
+}
+
+// ApproveSellerApplication menaikkan role user menjadi seller dan membuat shop profile.
+// Role baru ikut masuk ke access token berikutnya saat token di-refresh.
+func ApproveSellerApplication(id, adminID, note string) (*models.SellerApplication, error) {
+	var application models.SellerApplication
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockPendingApplication(tx, id, &application); err != nil {
+			return err
+		}
+
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", application.UserID).Error; err != nil {
+			return errors.New("user not found")
+		}
+		if user.Role != "buyer" {
+			return ErrAlreadySeller
+		}
+		if shopNameTaken(tx, application.ShopName) {
+			return ErrShopNameTaken
+		}
+
+		shop := models.ShopProfile{
+			UserID:    user.ID,
+			ShopName:  application.ShopName,
+			LegalName: application.LegalName,
+			TaxID:     application.TaxID,
+		}
+		if err := tx.Create(&shop).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("role", "seller").Error; err != nil {
+			return err
+		}
+
+		return markReviewed(tx, &application, models.SellerApplicationApproved, adminID, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func RejectSellerApplication(id, adminID, reason string) (*models.SellerApplication, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRejectionReasonRequired
+	}
+
+	var application models.SellerApplication
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockPendingApplication(tx, id, &application); err != nil {
+			return err
+		}
+		return markReviewed(tx, &application, models.SellerApplicationRejected, adminID, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func GetShopProfile(userID string) (*models.ShopProfile, error) {
+	var shop models.ShopProfile
+	if err := config.DB.First(&shop, "user_id = ?", userID).Error; err != nil {
+		return nil, errors.New("shop profile not found")
+	}
+	return &shop, nil
+}
+
+func lockPendingApplication(tx *gorm.DB, id string, application *models.SellerApplication) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(application, "id = ?", id).Error; err != nil {
+		return ErrSellerApplicationNotFound
+	}
+	if application.Status != models.SellerApplicationPending {
+		return ErrSellerApplicationReviewed
+	}
+	return nil
+}
+
+func markReviewed(tx *gorm.DB, application *models.SellerApplication, status, adminID, note string) error {
+	now := time.Now()
+	application.Status = status
+	application.ReviewNote = note
+	application.ReviewedBy = adminID
+	application.ReviewedAt = &now
+	return tx.Model(application).Updates(map[string]interface{}{
+		"status":      status,
+		"review_note": note,
+		"reviewed_by": adminID,
+		"reviewed_at": now,
+	}).Error
+}
+
+func shopNameTaken(tx *gorm.DB, shopName string) bool {
+	var count int64
+	tx.Model(&models.ShopProfile{}).Where("shop_name = ?", shopName).Count(&count)
+	return count > 0
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"ecommerce-backend/models"
+	"ecommerce-backend/storage"
+	"log"
+	"mime/multipart"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Dokumen identitas seller tidak boleh diakses lewat URL permanen; URL unduhan
+// dibuat ulang setiap kali aplikasi ditampilkan.
+const sellerDocumentURLTTL = 15 * time.Minute
+
+// UploadSellerDocument men-stream file dokumen ke storage privat tanpa membacanya
+// ke memori. Yang disimpan hanya key-nya, bukan URL.
+func UploadSellerDocument(file *multipart.FileHeader) (*models.SellerApplicationDocument, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	contentType := file.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	key := "seller-applications/" + uuid.NewString() + "/" + storage.SafeName(file.Filename)
+	if _, err := storage.Private().Put(key, f, file.Size, contentType); err != nil {
+		return nil, err
+	}
+	return &models.SellerApplicationDocument{FileName: file.Filename, StorageKey: key}, nil
+}
+
+func DiscardSellerDocuments(documents []models.SellerApplicationDocument) {
+	store := storage.Private()
+	for _, doc := range documents {
+		if err := store.Delete(doc.StorageKey); err != nil {
+			log.Printf("Failed to delete seller document %s from storage: %v", doc.StorageKey, err)
+		}
+	}
+}
+
+// SignSellerDocuments mengisi DownloadURL setiap dokumen dengan signed URL.
+// Dokumen lama di bucket publik ditandatangani lewat storage utama; jika URL-nya
+// tidak dikenali, DownloadURL dibiarkan kosong.
+func SignSellerDocuments(applications ...*models.SellerApplication) {
+	for _, application := range applications {
+		for i := range application.Documents {
+			doc := &application.Documents[i]
+			store, key := storage.Private(), doc.StorageKey
+			if key == "" {
+				var ok bool
+				store = storage.Default()
+				if key, ok = store.KeyFromURL(doc.FileURL); !ok {
+					continue
+				}
+			}
+			if url, err := store.SignedURL(key, sellerDocumentURLTTL); err == nil {
+				doc.DownloadURL = url
+			} else {
+				log.Printf("Failed to sign seller document %s: %v", doc.ID, err)
+			}
+		}
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"ecommerce-backend/config"
 	"ecommerce-backend/models"
 	"errors"
-	"fmt"
+	"log"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -20,18 +21,55 @@ func GetUserByID(id string) (*models.User, error) {
 	}
 	return &user, nil
 }
+
+var ErrEmailTaken = errors.New("email is already registered")
+
+// UpdateUser mengubah nama dan email user. Email baru wajib diverifikasi ulang:
+// status verifikasi direset, token verifikasi, magic link, dan reset password
+// yang dikirim ke alamat lama dibatalkan, lalu link verifikasi dikirim ke alamat baru.
 func UpdateUser(id string, user *models.User) (*models.User, error) {
 	var existingUser models.User
+	if err := config.DB.First(&existingUser, "id = ?", id).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
 
-	rawSQL := fmt.Sprintf(
-		`UPDATE users SET name = '%s', email = '%s' WHERE id = '%s' LIMIT 1`,
-		user.Name, user.Email, id,
-	)
+	updates := map[string]interface{}{"name": user.Name}
+	email := strings.TrimSpace(user.Email)
+	emailChanged := email != "" && !strings.EqualFold(email, existingUser.Email)
+	if emailChanged {
+		updates["email"] = email
+		updates["email_verified"] = false
+		updates["email_verified_at"] = nil
+	}
 
-	if err := config.DB.Exec(rawSQL).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if emailChanged {
+			var count int64
+			if err := tx.Model(&models.User{}).Where("email = ? AND id <> ?", email, id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrEmailTaken
+			}
+			for _, token := range []interface{}{&models.EmailVerificationToken{}, &models.MagicLinkToken{}, &models.PasswordResetToken{}} {
+				if err := tx.Where("user_id = ?", id).Delete(token).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Model(&models.User{}).Where("id = ?", id).Updates(updates).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	config.DB.First(&existingUser, "id = ?", id)
+	if err := config.DB.First(&existingUser, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	if emailChanged {
+		if err := SendVerificationEmail(&existingUser); err != nil {
+			log.Printf("Failed to send verification email to %s: %v", existingUser.Email, err)
+		}
+	}
 	return &existingUser, nil
 }
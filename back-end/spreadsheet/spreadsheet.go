@@ -0,0 +1,128 @@
+// Package spreadsheet membaca dan menulis tabel sederhana (baris berisi string)
+// dalam format CSV dan XLSX. Hanya sheet pertama XLSX yang dipakai; style,
+// formula dan tipe tanggal tidak didukung karena tidak dibutuhkan untuk
+// import/export katalog.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported spreadsheet format")
+	ErrTooManyRows       = errors.New("spreadsheet has too many rows")
+	ErrInvalidFile       = errors.New("invalid spreadsheet file")
+)
+
+// FormatFromName menentukan format dari ekstensi nama file.
+func FormatFromName(name string) (string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// Row adalah satu baris berisi data. Line adalah nomor baris di file (mulai 1),
+// supaya error validasi bisa menunjuk baris yang sama dengan yang dilihat user.
+type Row struct {
+	Line  int
+	Cells []string
+}
+
+// Read membaca semua baris, maksimal maxRows (termasuk header). Baris yang
+// seluruh selnya kosong dilewati.
+func Read(format string, r io.Reader, maxRows int) ([]Row, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r, maxRows)
+	case FormatXLSX:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return readXLSX(bytes.NewReader(data), int64(len(data)), maxRows)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// Write menulis baris ke w. Sel yang seluruhnya angka ditulis sebagai angka di XLSX.
+func Write(format string, w io.Writer, rows [][]string) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	case FormatXLSX:
+		return writeXLSX(w, rows)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func readCSV(r io.Reader, maxRows int) ([]Row, error) {
+	cr := csv.NewReader(stripBOM(r))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var rows []Row
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if blank(record) {
+			continue
+		}
+		if len(rows) >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, Row{Line: line, Cells: record})
+	}
+}
+
+// Excel menyimpan CSV UTF-8 dengan BOM; tanpa dibuang, nama kolom pertama tidak cocok.
+func stripBOM(r io.Reader) io.Reader {
+	buf := make([]byte, 3)
+	n, _ := io.ReadFull(r, buf)
+	if n == 3 && bytes.Equal(buf, []byte{0xEF, 0xBB, 0xBF}) {
+		return r
+	}
+	return io.MultiReader(bytes.NewReader(buf[:n]), r)
+}
+
+func blank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
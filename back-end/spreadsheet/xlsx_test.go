@@ -0,0 +1,84 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func xlsxFile(t *testing.T, rows [][]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadXLSXRoundTrip(t *testing.T) {
+	data := xlsxFile(t, [][]string{
+		{"name", "sku", "price"},
+		{"Sepatu <lari>", "00123", "150000"},
+		{"", "", ""},
+		{"Kemeja", "", "99.5"},
+	})
+	rows, err := readXLSX(bytes.NewReader(data), int64(len(data)), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{Line: 1, Cells: []string{"name", "sku", "price"}},
+		{Line: 2, Cells: []string{"Sepatu <lari>", "00123", "150000"}},
+		{Line: 4, Cells: []string{"Kemeja", "", "99.5"}},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if rows[i].Line != want[i].Line || strings.Join(rows[i].Cells, "|") != strings.Join(want[i].Cells, "|") {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestReadXLSXColumnLimits(t *testing.T) {
+	wide := make([]string, 100)
+	wide[0] = "Sepatu"
+	wide[len(wide)-1] = "ignored"
+
+	tests := []struct {
+		name      string
+		rows      [][]string
+		wantErr   error
+		wantCells int
+	}{
+		{
+			name:      "data row capped at header width plus margin",
+			rows:      [][]string{{"name", "sku"}, wide},
+			wantCells: 2 + xlsxColumnMargin,
+		},
+		{
+			name:    "header wider than limit",
+			rows:    [][]string{append(make([]string, maxXLSXColumns), "name")},
+			wantErr: ErrInvalidFile,
+		},
+	}
+	for _, tt := range tests {
+		data := xlsxFile(t, tt.rows)
+		rows, err := readXLSX(bytes.NewReader(data), int64(len(data)), 10)
+		if err != tt.wantErr {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && len(rows[1].Cells) != tt.wantCells {
+			t.Errorf("%s: data row has %d cells, want %d", tt.name, len(rows[1].Cells), tt.wantCells)
+		}
+	}
+}
+
+func TestReadXLSXTooManyRows(t *testing.T) {
+	data := xlsxFile(t, [][]string{{"name"}, {"a"}, {"b"}})
+	if _, err := readXLSX(bytes.NewReader(data), int64(len(data)), 2); err != ErrTooManyRows {
+		t.Errorf("err = %v, want ErrTooManyRows", err)
+	}
+}
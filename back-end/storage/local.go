@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ecommerce-backend/utils"
+)
+
+// LocalStorage menyimpan file di disk dan menyajikannya lewat ServeHTTP, yang
+// dipasang di RoutePrefix oleh main.go.
+type LocalStorage struct {
+	Dir         string
+	BaseURL     string
+	RoutePrefix string
+	// PublicPrefixes adalah prefix key yang boleh diambil tanpa signature; file
+	// lain hanya bisa diambil lewat SignedURL.
+	PublicPrefixes []string
+	signingKey     []byte
+}
+
+// Hanya gambar produk yang memang ditampilkan ke publik.
+var defaultPublicPrefixes = []string{"products/"}
+
+// NewLocalFromEnv membaca STORAGE_LOCAL_DIR, STORAGE_PUBLIC_URL,
+// STORAGE_LOCAL_PUBLIC_PREFIXES (dipisah koma, default "products/") dan
+// STORAGE_SIGNING_KEY. Tanpa signing key, kunci acak dibuat sehingga signed URL
+// tidak berlaku lagi setelah restart.
+func NewLocalFromEnv() *LocalStorage {
+	prefixes := utils.GetEnvList("STORAGE_LOCAL_PUBLIC_PREFIXES")
+	if len(prefixes) == 0 {
+		prefixes = defaultPublicPrefixes
+	}
+	return newLocal(
+		envOr("STORAGE_LOCAL_DIR", "./uploads"),
+		envOr("STORAGE_PUBLIC_URL", "http://localhost:8080/uploads"),
+		"/uploads",
+		prefixes,
+	)
+}
+
+// NewLocalPrivateFromEnv membuat storage untuk dokumen privat di direktori
+// terpisah (STORAGE_LOCAL_PRIVATE_DIR, STORAGE_PRIVATE_URL). File di sini hanya
+// bisa diambil dengan signed URL.
+func NewLocalPrivateFromEnv() *LocalStorage {
+	return newLocal(
+		envOr("STORAGE_LOCAL_PRIVATE_DIR", "./uploads-private"),
+		envOr("STORAGE_PRIVATE_URL", "http://localhost:8080/private-uploads"),
+		"/private-uploads",
+		nil,
+	)
+}
+
+func newLocal(dir, baseURL, routePrefix string, publicPrefixes []string) *LocalStorage {
+	key := []byte(os.Getenv("STORAGE_SIGNING_KEY"))
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &LocalStorage{
+		Dir:            dir,
+		BaseURL:        strings.TrimRight(baseURL, "/"),
+		RoutePrefix:    routePrefix,
+		PublicPrefixes: publicPrefixes,
+		signingKey:     key,
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (s *LocalStorage) isPublic(key string) bool {
+	for _, prefix := range s.PublicPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *LocalStorage) path(key string) string {
+	return filepath.Join(s.Dir, filepath.FromSlash(key))
+}
+
+func (s *LocalStorage) Put(key string, r io.Reader, size int64, contentType string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	dst := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return "", err
+	}
+
+	// Tulis ke file sementara lalu rename supaya pembaca tidak melihat file setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return s.BaseURL + "/" + key, nil
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *LocalStorage) SignedURL(key string, ttl time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	q := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return s.BaseURL + "/" + key + "?" + q.Encode(), nil
+}
+
+func (s *LocalStorage) KeyFromURL(u string) (string, bool) {
+	key, ok := strings.CutPrefix(u, s.BaseURL+"/")
+	if !ok || !ValidKey(key) {
+		return "", false
+	}
+	return key, true
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) validSignature(key string, q url.Values) bool {
+	expires, signature := q.Get("expires"), q.Get("signature")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(signature), []byte(s.sign(key, expires))) == 1
+}
+
+// ServeHTTP menyajikan file di bawah RoutePrefix. Request dengan signature
+// selalu divalidasi; tanpa signature hanya diizinkan untuk key dengan prefix publik.
+func (s *LocalStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, s.RoutePrefix+"/")
+	if !ValidKey(key) {
+		http.NotFound(w, r)
+		return
+	}
+	q := r.URL.Query()
+	if q.Has("signature") || !s.isPublic(key) {
+		if !s.validSignature(key, q) {
+			http.Error(w, "invalid or expired signature", http.StatusForbidden)
+			return
+		}
+	}
+
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
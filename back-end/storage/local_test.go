@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalServeHTTPPublicPrefixes(t *testing.T) {
+	s := newLocal(t.TempDir(), "http://localhost/uploads", "/uploads", defaultPublicPrefixes)
+	for _, key := range []string{"products/1/large.jpg", "seller-documents/1/ktp.jpg"} {
+		if _, err := s.Put(key, strings.NewReader("data"), 4, "image/jpeg"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signed, err := s.SignedURL("seller-documents/1/ktp.jpg", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedURL, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"public prefix without signature", "/uploads/products/1/large.jpg", http.StatusOK},
+		{"other prefix without signature", "/uploads/seller-documents/1/ktp.jpg", http.StatusForbidden},
+		{"other prefix with signature", signedURL.RequestURI(), http.StatusOK},
+		{"invalid signature on public prefix", "/uploads/products/1/large.jpg?expires=1&signature=x", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
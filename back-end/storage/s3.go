@@ -0,0 +1,316 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ecommerce-backend/utils"
+)
+
+const (
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	maxPresignTTL   = 7 * 24 * time.Hour
+)
+
+// S3Storage berbicara dengan API S3 (AWS, MinIO, R2, dll.) menggunakan
+// Signature V4 yang ditulis sendiri, sehingga tidak perlu AWS SDK.
+type S3Storage struct {
+	Endpoint  *url.URL
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PublicURL adalah base URL untuk objek publik, misalnya CDN. Kosong berarti
+	// memakai URL endpoint bucket.
+	PublicURL string
+	// PathStyle memakai endpoint/bucket/key alih-alih bucket.endpoint/key; MinIO
+	// membutuhkan ini.
+	PathStyle bool
+	Client    *http.Client
+
+	now func() time.Time
+}
+
+// NewS3FromEnv membaca S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY,
+// S3_SECRET_KEY, S3_PUBLIC_URL dan S3_FORCE_PATH_STYLE.
+func NewS3FromEnv() (*S3Storage, error) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "https://s3.amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3_ENDPOINT %q", endpoint)
+	}
+	region := os.Getenv("S3_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Storage{
+		Endpoint:  u,
+		Region:    region,
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		PublicURL: strings.TrimRight(os.Getenv("S3_PUBLIC_URL"), "/"),
+		PathStyle: utils.GetEnvBool("S3_FORCE_PATH_STYLE", true),
+		Client:    &http.Client{Timeout: 5 * time.Minute},
+		now:       time.Now,
+	}, nil
+}
+
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.Endpoint
+	if s.PathStyle {
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + s.Bucket + "/" + key
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + key
+	}
+	u.RawPath = escapeKey(u.Path)
+	return &u
+}
+
+func (s *S3Storage) publicPrefix() string {
+	if s.PublicURL != "" {
+		return s.PublicURL + "/"
+	}
+	return strings.TrimSuffix(s.objectURL("").String(), "/") + "/"
+}
+
+func (s *S3Storage) Put(key string, r io.Reader, size int64, contentType string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	// S3 menolak PUT tanpa Content-Length, jadi isi yang panjangnya tidak
+	// diketahui harus dibaca dulu.
+	if size < 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(key).String(), r)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Cache-Control", "max-age=31536000")
+	if err := s.send(req); err != nil {
+		return "", err
+	}
+	return s.publicPrefix() + key, nil
+}
+
+func (s *S3Storage) Delete(key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	req, err := http.NewRequest(http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	return s.send(req)
+}
+
+func (s *S3Storage) SignedURL(key string, ttl time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	if ttl > maxPresignTTL {
+		ttl = maxPresignTTL
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return s.presign(http.MethodGet, s.objectURL(key), ttl), nil
+}
+
+func (s *S3Storage) KeyFromURL(u string) (string, bool) {
+	key, ok := strings.CutPrefix(u, s.publicPrefix())
+	if !ok || !ValidKey(key) {
+		return "", false
+	}
+	return key, true
+}
+
+func (s *S3Storage) send(req *http.Request) error {
+	s.sign(req)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		var s3err struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if xml.Unmarshal(body, &s3err) == nil && s3err.Code != "" {
+			return fmt.Errorf("s3 %s failed: %s: %s", req.Method, s3err.Code, s3err.Message)
+		}
+		return fmt.Errorf("s3 %s failed with status %d", req.Method, resp.StatusCode)
+	}
+	return nil
+}
+
+// sign menambahkan header Authorization Signature V4. Payload tidak di-hash
+// (UNSIGNED-PAYLOAD) supaya body bisa di-stream; integritas dijamin TLS.
+func (s *S3Storage) sign(req *http.Request) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req.Header)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.signature(now, stringToSign(amzDate, scope, canonicalRequest))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+	req.Header.Del("Host")
+}
+
+// presign membuat URL dengan query-string authentication yang berlaku selama ttl.
+func (s *S3Storage) presign(method string, u *url.URL, ttl time.Duration) string {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := s.scope(now)
+
+	q := u.Query()
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", s.AccessKey+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", strconv.FormatInt(int64(ttl.Seconds()), 10))
+	q.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalPath(u),
+		canonicalQuery(q),
+		"host:" + u.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+	q.Set("X-Amz-Signature", s.signature(now, stringToSign(amzDate, scope, canonicalRequest)))
+
+	signed := *u
+	signed.RawQuery = canonicalQuery(q)
+	return signed.String()
+}
+
+func (s *S3Storage) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.Region + "/s3/aws4_request"
+}
+
+func (s *S3Storage) signature(t time.Time, toSign string) string {
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func stringToSign(amzDate, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	return "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func canonicalHeaders(h http.Header) (signed, canonical string) {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		lower := strings.ToLower(name)
+		if lower == "host" || lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + strings.TrimSpace(h.Get(name)) + "\n")
+	}
+	return strings.Join(names, ";"), b.String()
+}
+
+func canonicalPath(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return escapeKey(u.Path)
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapeKey meng-encode setiap segmen path sesuai aturan URI encoding S3
+// (RFC 3986 unreserved tidak di-encode, "/" dipertahankan).
+func escapeKey(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		segments[i] = uriEncode(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,194 @@
+// Package storage menyimpan file upload (gambar produk, dokumen seller) di object
+// storage. Driver dipilih lewat STORAGE_DRIVER: "supabase", "s3" (AWS S3, MinIO,
+// atau layanan S3-compatible lain) dan "local" untuk development dan CI.
+package storage
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidKey = errors.New("invalid storage key")
+	ErrForeignURL = errors.New("url does not belong to the configured storage")
+	ErrNotFound   = errors.New("object not found")
+	// ErrPrivateNotConfigured dikembalikan Private() jika driver butuh bucket
+	// terpisah untuk dokumen privat tetapi belum diatur.
+	ErrPrivateNotConfigured = errors.New("private storage is not configured")
+)
+
+// Storage adalah abstraksi object storage. Key adalah path relatif di bucket,
+// misalnya "products/<uuid>/large.jpg".
+type Storage interface {
+	// Put mengunggah isi r secara streaming dan mengembalikan URL publik objek.
+	// size boleh -1 jika panjang isi tidak diketahui.
+	Put(key string, r io.Reader, size int64, contentType string) (string, error)
+	Delete(key string) error
+	// SignedURL mengembalikan URL sementara yang berlaku selama ttl, juga untuk
+	// objek yang tidak bisa diakses publik.
+	SignedURL(key string, ttl time.Duration) (string, error)
+	// KeyFromURL mengembalikan key dari URL publik yang dibuat oleh driver ini.
+	KeyFromURL(url string) (string, bool)
+}
+
+var (
+	defaultStorage Storage
+	once           sync.Once
+)
+
+// Default mengembalikan storage global yang dibuat dari environment saat pertama dipanggil.
+func Default() Storage {
+	once.Do(func() {
+		defaultStorage = newFromEnv()
+	})
+	return defaultStorage
+}
+
+// SetDefault mengganti storage global, berguna untuk test atau wiring manual.
+func SetDefault(s Storage) {
+	once.Do(func() {})
+	defaultStorage = s
+}
+
+var (
+	privateStorage Storage
+	privateOnce    sync.Once
+)
+
+// Private mengembalikan storage untuk dokumen sensitif (KTP, NPWP). Objek di
+// sini tidak pernah diberikan lewat URL publik; yang disimpan di database
+// adalah key-nya, dan aksesnya hanya lewat SignedURL.
+func Private() Storage {
+	privateOnce.Do(func() {
+		privateStorage = newPrivateFromEnv()
+	})
+	return privateStorage
+}
+
+// SetPrivate mengganti storage privat global, berguna untuk test atau wiring manual.
+func SetPrivate(s Storage) {
+	privateOnce.Do(func() {})
+	privateStorage = s
+}
+
+// Tanpa STORAGE_DRIVER, Supabase dipakai jika SUPABASE_URL diisi (perilaku lama),
+// selain itu filesystem lokal supaya upload tetap jalan tanpa akun cloud.
+func driverFromEnv() string {
+	driver := strings.ToLower(os.Getenv("STORAGE_DRIVER"))
+	if driver == "" {
+		driver = "local"
+		if os.Getenv("SUPABASE_URL") != "" {
+			driver = "supabase"
+		}
+	}
+	return driver
+}
+
+func newFromEnv() Storage {
+	driver := driverFromEnv()
+	switch driver {
+	case "supabase":
+		return NewSupabaseFromEnv()
+	case "s3":
+		s, err := NewS3FromEnv()
+		if err != nil {
+			log.Printf("S3 storage is misconfigured, uploads are disabled: %v", err)
+			return unavailableStorage{err}
+		}
+		return s
+	case "local":
+		return NewLocalFromEnv()
+	default:
+		log.Printf("Unknown STORAGE_DRIVER %q, falling back to local", driver)
+		return NewLocalFromEnv()
+	}
+}
+
+// Bucket Supabase publik menyajikan semua isinya, sehingga dokumen privat wajib
+// memakai SUPABASE_PRIVATE_BUCKET. Objek S3 privat secara default, jadi tanpa
+// S3_PRIVATE_BUCKET bucket utama dipakai; bucket policy-nya tidak boleh membuka
+// prefix dokumen. Driver local memakai direktori terpisah yang selalu butuh signature.
+func newPrivateFromEnv() Storage {
+	switch driverFromEnv() {
+	case "supabase":
+		s := NewSupabaseFromEnv()
+		s.Bucket = os.Getenv("SUPABASE_PRIVATE_BUCKET")
+		if s.Bucket == "" {
+			log.Println("SUPABASE_PRIVATE_BUCKET is not set, private uploads are disabled")
+			return unavailableStorage{ErrPrivateNotConfigured}
+		}
+		return s
+	case "s3":
+		s, err := NewS3FromEnv()
+		if err != nil {
+			log.Printf("S3 storage is misconfigured, private uploads are disabled: %v", err)
+			return unavailableStorage{err}
+		}
+		if bucket := os.Getenv("S3_PRIVATE_BUCKET"); bucket != "" {
+			s.Bucket = bucket
+		}
+		s.PublicURL = ""
+		return s
+	default:
+		return NewLocalPrivateFromEnv()
+	}
+}
+
+// unavailableStorage menolak semua operasi dengan err, dipakai saat storage
+// belum atau salah dikonfigurasi supaya server tetap jalan dan dokumen privat
+// tidak jatuh ke bucket publik.
+type unavailableStorage struct{ err error }
+
+func (s unavailableStorage) Put(string, io.Reader, int64, string) (string, error) {
+	return "", s.err
+}
+func (s unavailableStorage) Delete(string) error { return s.err }
+func (s unavailableStorage) SignedURL(string, time.Duration) (string, error) {
+	return "", s.err
+}
+func (unavailableStorage) KeyFromURL(string) (string, bool) { return "", false }
+
+// ValidKey menolak key kosong, absolut, atau yang keluar dari root bucket.
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return false
+	}
+	return path.Clean(key) == key && key != "." && key != ".." && !strings.HasPrefix(key, "../")
+}
+
+// DeleteURL menghapus objek berdasarkan URL publik yang tersimpan di database.
+func DeleteURL(s Storage, url string) error {
+	key, ok := s.KeyFromURL(url)
+	if !ok {
+		return ErrForeignURL
+	}
+	return s.Delete(key)
+}
+
+// SafeName membuat nama file aman untuk dipakai sebagai bagian dari key.
+func SafeName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	s := strings.TrimLeft(b.String(), ".")
+	if s == "" {
+		return "file"
+	}
+	if len(s) > 100 {
+		s = s[len(s)-100:]
+	}
+	return s
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// SupabaseStorage memakai REST API Supabase Storage secara langsung supaya
+// upload bisa di-stream tanpa membaca seluruh file ke memori.
+type SupabaseStorage struct {
+	ProjectURL string
+	Token      string
+	Bucket     string
+	Client     *http.Client
+}
+
+func NewSupabaseFromEnv() *SupabaseStorage {
+	return &SupabaseStorage{
+		ProjectURL: strings.TrimRight(os.Getenv("SUPABASE_URL"), "/"),
+		Token:      os.Getenv("SUPABASE_TOKEN"),
+		Bucket:     os.Getenv("SUPABASE_BUCKET"),
+		Client:     &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+func (s *SupabaseStorage) objectURL(key string) string {
+	return s.ProjectURL + "/storage/v1/object/" + s.Bucket + "/" + escapeKey(key)
+}
+
+func (s *SupabaseStorage) publicPrefix() string {
+	return s.ProjectURL + "/storage/v1/object/public/" + s.Bucket + "/"
+}
+
+func (s *SupabaseStorage) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+s.Token)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("supabase %s failed with status %d: %s", req.Method, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return resp, nil
+}
+
+func (s *SupabaseStorage) Put(key string, r io.Reader, size int64, contentType string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	req, err := http.NewRequest(http.MethodPost, s.objectURL(key), r)
+	if err != nil {
+		return "", err
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Cache-Control", "max-age=31536000")
+	req.Header.Set("x-upsert", "true")
+
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return s.publicPrefix() + key, nil
+}
+
+func (s *SupabaseStorage) Delete(key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	req, err := http.NewRequest(http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *SupabaseStorage) SignedURL(key string, ttl time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	payload, _ := json.Marshal(map[string]int64{"expiresIn": int64(ttl.Seconds())})
+	url := s.ProjectURL + "/storage/v1/object/sign/" + s.Bucket + "/" + escapeKey(key)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		SignedURL string `json:"signedURL"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.SignedURL == "" {
+		return "", fmt.Errorf("supabase sign returned no url")
+	}
+	return s.ProjectURL + "/storage/v1" + body.SignedURL, nil
+}
+
+func (s *SupabaseStorage) KeyFromURL(u string) (string, bool) {
+	key, ok := strings.CutPrefix(u, s.publicPrefix())
+	if !ok || !ValidKey(key) {
+		return "", false
+	}
+	return key, true
+}
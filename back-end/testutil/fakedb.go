@@ -0,0 +1,143 @@
+// Package testutil berisi helper untuk test service dan middleware tanpa server
+// MySQL. Hanya diimpor dari file _test.go.
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"ecommerce-backend/config"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// FakeDB adalah driver database/sql minimal untuk test service tanpa server
+// MySQL. Setiap statement diteruskan ke handler test, yang menyimulasikan
+// perilaku tabel yang relevan berdasarkan SQL dan argumennya.
+type FakeDB struct {
+	mu sync.Mutex
+	// Exec menangani INSERT/UPDATE/DELETE dan mengembalikan RowsAffected.
+	Exec func(query string, args []interface{}) (int64, error)
+	// Query menangani SELECT dan mengembalikan kolom beserta barisnya.
+	Query func(query string, args []interface{}) ([]string, [][]interface{}, error)
+}
+
+var ErrUnexpectedQuery = errors.New("unexpected query")
+
+// UseFakeDB memasang FakeDB sebagai config.DB selama test berjalan.
+func UseFakeDB(t *testing.T, f *FakeDB) *gorm.DB {
+	t.Helper()
+	sqlDB := sql.OpenDB(fakeConnector{f})
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger:                 logger.Discard,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = previous
+		sqlDB.Close()
+	})
+	return db
+}
+
+func namedValues(args []driver.NamedValue) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	return values
+}
+
+type fakeConnector struct{ db *FakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{c.db}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use fakeConnector") }
+
+type fakeConn struct{ db *FakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if c.db.Exec == nil {
+		return nil, ErrUnexpectedQuery
+	}
+	n, err := c.db.Exec(query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	return fakeResult(n), nil
+}
+
+// fakeResult seperti driver.RowsAffected, tetapi juga menjawab LastInsertId
+// karena gorm menanyakannya setelah INSERT.
+type fakeResult int64
+
+func (r fakeResult) LastInsertId() (int64, error) { return 0, nil }
+func (r fakeResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if c.db.Query == nil {
+		return nil, ErrUnexpectedQuery
+	}
+	columns, rows, err := c.db.Query(query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: columns, rows: rows}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	for i, v := range r.rows[0] {
+		dest[i] = v
+	}
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// IsStatement mencocokkan awal statement, misalnya IsStatement(q, "UPDATE `users`").
+func IsStatement(query, prefix string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), prefix)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// CookieConfig dibaca dari env:
+//
+//	COOKIE_SECURE   true di production (HTTPS), default false untuk development
+//	COOKIE_SAMESITE lax (default), strict, atau none
+//	COOKIE_DOMAIN   kosong berarti host-only cookie
+type CookieConfig struct {
+	Secure   bool
+	SameSite http.SameSite
+	Domain   string
+}
+
+func GetCookieConfig() CookieConfig {
+	cfg := CookieConfig{
+		Secure: GetEnvBool("COOKIE_SECURE", false),
+		Domain: os.Getenv("COOKIE_DOMAIN"),
+	}
+
+	switch strings.ToLower(os.Getenv("COOKIE_SAMESITE")) {
+	case "strict":
+		cfg.SameSite = http.SameSiteStrictMode
+	case "none":
+		// Browser menolak SameSite=None tanpa Secure
+		cfg.SameSite = http.SameSiteNoneMode
+		cfg.Secure = true
+	default:
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return cfg
+}
+
+// SetCookie menulis cookie dengan atribut dari GetCookieConfig. maxAge < 0 menghapus cookie.
+func SetCookie(w http.ResponseWriter, name, value string, maxAge int, path string, httpOnly bool) {
+	cfg := GetCookieConfig()
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     path,
+		Domain:   cfg.Domain,
+		Secure:   cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: cfg.SameSite,
+	})
+}
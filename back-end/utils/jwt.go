@@ -3,61 +3,194 @@ package utils
 import (
 	"errors"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// Purpose untuk token yang bukan access token biasa. Token dengan purpose
+// tidak pernah diterima oleh ValidateToken.
+const (
+	TokenPurposeMFA = "mfa_challenge"
+)
+
+// Setiap jenis token punya header typ dan audience sendiri. Key yang sama
+// dipublikasikan di JWKS, jadi service lain yang hanya menerima audience API
+// tidak akan menerima token impersonation atau challenge MFA sebagai access token.
+type tokenKind struct {
+	typ            string
+	audienceSuffix string
+}
+
+var (
+	accessTokenKind        = tokenKind{typ: "at+jwt"}
+	impersonationTokenKind = tokenKind{typ: "impersonation+jwt", audienceSuffix: "/impersonation"}
+	mfaChallengeTokenKind  = tokenKind{typ: "mfa-challenge+jwt", audienceSuffix: "/mfa-challenge"}
+)
+
+func (k tokenKind) audience() string {
+	return JWTAudience() + k.audienceSuffix
+}
+
+// JWTIssuer adalah nilai iss semua token yang diterbitkan backend ini.
+func JWTIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "ecommerce-backend"
+}
+
+// JWTAudience adalah aud access token, yaitu yang harus dicek service lain.
+func JWTAudience() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
+	}
+	return "ecommerce-api"
+}
+
 type JWTClaims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
+	Purpose   string `json:"purpose,omitempty"`
+	// ImpersonatorID diisi jika token diterbitkan untuk admin yang sedang
+	// bertindak sebagai user lain; SessionID lalu berisi ID impersonation.
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID, role string) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", errors.New("JWT_SECRET is not set")
-	}
+// AccessTokenTTL adalah umur access token. Sengaja dibuat pendek karena
+// sesi diperpanjang lewat refresh token.
+func AccessTokenTTL() time.Duration {
+	return GetEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
 
-	claims := JWTClaims{
-		UserID: userID,
-		Role:   role,
+func RefreshTokenTTL() time.Duration {
+	return GetEnvDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour)
+}
+
+func MFAChallengeTTL() time.Duration {
+	return GetEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute)
+}
+
+// ImpersonationTTL adalah umur maksimum token impersonation. Token ini tidak
+// punya refresh token, jadi admin harus memulai ulang setelah kedaluwarsa.
+func ImpersonationTTL() time.Duration {
+	return GetEnvDuration("IMPERSONATION_TTL", 15*time.Minute)
+}
+
+func GenerateToken(userID, role, sessionID string) (string, error) {
+	return signClaims(accessTokenKind, JWTClaims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL())),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
-	}
+	})
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+func GenerateImpersonationToken(userID, role, impersonationID, adminID string, expiresAt time.Time) (string, error) {
+	return signClaims(impersonationTokenKind, JWTClaims{
+		UserID:         userID,
+		Role:           role,
+		SessionID:      impersonationID,
+		ImpersonatorID: adminID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
 }
 
+// GenerateMFAChallengeToken diterbitkan setelah password benar tetapi
+// sebelum kode 2FA diverifikasi.
+func GenerateMFAChallengeToken(userID, role string) (string, error) {
+	// ID dipakai untuk membatasi jumlah percobaan kode per challenge
+	challengeID, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	return signClaims(mfaChallengeTokenKind, JWTClaims{
+		UserID:  userID,
+		Role:    role,
+		Purpose: TokenPurposeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        challengeID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(MFAChallengeTTL())),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+}
+
+// ValidateToken menerima access token dan token impersonation.
 func ValidateToken(tokenString string) (*JWTClaims, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return nil, errors.New("JWT_SECRET is not set")
+	claims, kind, err := parseClaims(tokenString, accessTokenKind, impersonationTokenKind)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" || (kind == impersonationTokenKind) != (claims.ImpersonatorID != "") {
+		return nil, errors.New("invalid token")
 	}
+	return claims, nil
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+func ValidatePurposeToken(tokenString, purpose string) (*JWTClaims, error) {
+	if purpose != TokenPurposeMFA {
+		return nil, errors.New("invalid token")
+	}
+	claims, _, err := parseClaims(tokenString, mfaChallengeTokenKind)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func signClaims(kind tokenKind, claims JWTClaims) (string, error) {
+	ring, err := getKeyring()
+	if err != nil {
+		return "", err
+	}
+	claims.Issuer = JWTIssuer()
+	claims.Audience = jwt.ClaimStrings{kind.audience()}
+	return ring.sign(claims, kind.typ)
+}
+
+// parseClaims memverifikasi signature, masa berlaku, iss, dan typ serta aud
+// yang harus cocok dengan salah satu kinds.
+func parseClaims(tokenString string, kinds ...tokenKind) (*JWTClaims, tokenKind, error) {
+	ring, err := getKeyring()
+	if err != nil {
+		return nil, tokenKind{}, err
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, ring.keyFunc)
 
 	if err != nil {
 		var validationErr *jwt.ValidationError
 		if errors.As(err, &validationErr) {
 			if errors.Is(err, jwt.ErrTokenExpired) {
-				return nil, errors.New("token has expired")
+				return nil, tokenKind{}, errors.New("token has expired")
 			}
-			return nil, errors.New("invalid token")
+			return nil, tokenKind{}, errors.New("invalid token")
 		}
-		return nil, err
+		return nil, tokenKind{}, err
 	}
 
 	claims, ok := token.Claims.(*JWTClaims)
-	if !ok || !token.Valid {
-		return nil, errors.New("invalid token")
+	if !ok || !token.Valid || !claims.VerifyIssuer(JWTIssuer(), true) {
+		return nil, tokenKind{}, errors.New("invalid token")
 	}
 
-	return claims, nil
+	typ, _ := token.Header["typ"].(string)
+	i := slices.IndexFunc(kinds, func(k tokenKind) bool { return k.typ == typ })
+	if i < 0 || !claims.VerifyAudience(kinds[i].audience(), true) {
+		return nil, tokenKind{}, errors.New("invalid token")
+	}
+	return claims, kinds[i], nil
 }
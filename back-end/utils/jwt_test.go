@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestTokenKinds(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "main", newRSAKey(t))
+	loadTestKeyring(t, map[string]string{"JWT_KEYS_DIR": dir})
+
+	access, err := GenerateToken("user-1", "buyer", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	impersonation, err := GenerateImpersonationToken("user-1", "buyer", "imp-1", "admin-1", time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := GenerateMFAChallengeToken("user-1", "seller")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		wantTyp      string
+		wantAudience string
+		access       bool
+		mfa          bool
+	}{
+		{"access", access, "at+jwt", "ecommerce-api", true, false},
+		{"impersonation", impersonation, "impersonation+jwt", "ecommerce-api/impersonation", true, false},
+		{"mfa challenge", challenge, "mfa-challenge+jwt", "ecommerce-api/mfa-challenge", false, true},
+	}
+	for _, tt := range tests {
+		header := tokenHeader(t, tt.token)
+		if header["typ"] != tt.wantTyp {
+			t.Errorf("%s: typ = %v, want %s", tt.name, header["typ"], tt.wantTyp)
+		}
+		parsed, _, err := new(jwt.Parser).ParseUnverified(tt.token, &JWTClaims{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims := parsed.Claims.(*JWTClaims)
+		if claims.Issuer != "ecommerce-backend" || len(claims.Audience) != 1 || claims.Audience[0] != tt.wantAudience {
+			t.Errorf("%s: iss = %q, aud = %v", tt.name, claims.Issuer, claims.Audience)
+		}
+
+		if _, err := ValidateToken(tt.token); (err == nil) != tt.access {
+			t.Errorf("%s: ValidateToken err = %v, want accepted=%v", tt.name, err, tt.access)
+		}
+		if _, err := ValidatePurposeToken(tt.token, TokenPurposeMFA); (err == nil) != tt.mfa {
+			t.Errorf("%s: ValidatePurposeToken err = %v, want accepted=%v", tt.name, err, tt.mfa)
+		}
+	}
+
+	claims, err := ValidateToken(impersonation)
+	if err != nil || claims.ImpersonatorID != "admin-1" || claims.SessionID != "imp-1" {
+		t.Errorf("impersonation claims = %+v, %v", claims, err)
+	}
+}
+
+func TestParseClaimsChecksIssuerAudienceAndType(t *testing.T) {
+	dir := t.TempDir()
+	key := newRSAKey(t)
+	writePrivateKey(t, dir, "main", key)
+	loadTestKeyring(t, map[string]string{"JWT_KEYS_DIR": dir})
+
+	sign := func(typ string, claims JWTClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		if typ != "" {
+			token.Header["typ"] = typ
+		}
+		token.Header["kid"] = "main"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	valid := func() JWTClaims {
+		return JWTClaims{
+			UserID: "user-1",
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    JWTIssuer(),
+				Audience:  jwt.ClaimStrings{JWTAudience()},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		}
+	}
+
+	if _, err := ValidateToken(sign("at+jwt", valid())); err != nil {
+		t.Fatalf("control token rejected: %v", err)
+	}
+
+	wrongIssuer, noIssuer, wrongAudience, noAudience, expired, impersonatorInAccess, mfaAudience, noImpersonator :=
+		valid(), valid(), valid(), valid(), valid(), valid(), valid(), valid()
+	wrongIssuer.Issuer = "someone-else"
+	noIssuer.Issuer = ""
+	wrongAudience.Audience = jwt.ClaimStrings{"other-service"}
+	noAudience.Audience = nil
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	impersonatorInAccess.ImpersonatorID = "admin-1"
+	mfaAudience.Audience = jwt.ClaimStrings{JWTAudience() + "/mfa-challenge"}
+	noImpersonator.Audience = jwt.ClaimStrings{JWTAudience() + "/impersonation"}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong issuer", sign("at+jwt", wrongIssuer)},
+		{"missing issuer", sign("at+jwt", noIssuer)},
+		{"wrong audience", sign("at+jwt", wrongAudience)},
+		{"missing audience", sign("at+jwt", noAudience)},
+		{"generic JWT typ", sign("JWT", valid())},
+		{"missing typ", sign("", valid())},
+		{"expired", sign("at+jwt", expired)},
+		{"impersonator claim on access token", sign("at+jwt", impersonatorInAccess)},
+		{"access typ with mfa audience", sign("at+jwt", mfaAudience)},
+		{"impersonation typ without impersonator", sign("impersonation+jwt", noImpersonator)},
+	}
+	for _, tt := range tests {
+		if _, err := ValidateToken(tt.token); err == nil {
+			t.Errorf("%s: token was accepted", tt.name)
+		}
+	}
+}
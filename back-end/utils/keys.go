@@ -0,0 +1,263 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Keyring menyimpan key untuk menandatangani dan memverifikasi JWT.
+//
+// Key dibaca sekali dari JWT_KEYS_DIR:
+//   - <kid>.pem     private key RSA atau Ed25519 (PKCS#8 / PKCS#1), bisa dipakai untuk sign
+//   - <kid>.pub.pem public key saja, untuk key lama yang masih harus diverifikasi
+//
+// JWT_ACTIVE_KID memilih key yang dipakai untuk sign (default: kid terakhir
+// secara alfabetis). Rotasi: tambahkan key baru, pindahkan JWT_ACTIVE_KID,
+// lalu simpan key lama sebagai .pub.pem sampai semua token lama kedaluwarsa.
+//
+// Jika JWT_KEYS_DIR kosong, keyring memakai HS256 dengan JWT_SECRET seperti sebelumnya.
+type Keyring struct {
+	activeKID string
+	signer    crypto.Signer
+	method    jwt.SigningMethod
+	verifiers map[string]verificationKey
+
+	hmacSecret   []byte
+	acceptLegacy bool
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+var (
+	keyring     *Keyring
+	keyringErr  error
+	keyringOnce sync.Once
+)
+
+// InitJWTKeys memuat keyring di awal supaya konfigurasi yang salah langsung ketahuan saat startup.
+func InitJWTKeys() error {
+	_, err := getKeyring()
+	return err
+}
+
+func getKeyring() (*Keyring, error) {
+	keyringOnce.Do(func() {
+		keyring, keyringErr = loadKeyring()
+	})
+	return keyring, keyringErr
+}
+
+func loadKeyring() (*Keyring, error) {
+	ring := &Keyring{
+		verifiers:    make(map[string]verificationKey),
+		acceptLegacy: GetEnvBool("JWT_ACCEPT_LEGACY_HS256", false),
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		ring.hmacSecret = []byte(secret)
+	}
+
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		if ring.hmacSecret == nil {
+			return nil, errors.New("JWT_SECRET is not set")
+		}
+		return ring, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	signers := make(map[string]crypto.Signer)
+	var lastKID string
+	for _, file := range files {
+		base := filepath.Base(file)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasSuffix(base, ".pub.pem") {
+			kid := strings.TrimSuffix(base, ".pub.pem")
+			pub, err := parsePublicKey(data)
+			if err != nil {
+				return nil, fmt.Errorf("jwt key %s: %w", base, err)
+			}
+			method, err := methodForKey(pub)
+			if err != nil {
+				return nil, fmt.Errorf("jwt key %s: %w", base, err)
+			}
+			ring.verifiers[kid] = verificationKey{method: method, key: pub}
+			continue
+		}
+
+		kid := strings.TrimSuffix(base, ".pem")
+		signer, err := parsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", base, err)
+		}
+		method, err := methodForKey(signer.Public())
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", base, err)
+		}
+		signers[kid] = signer
+		ring.verifiers[kid] = verificationKey{method: method, key: signer.Public()}
+		lastKID = kid
+	}
+
+	ring.activeKID = os.Getenv("JWT_ACTIVE_KID")
+	if ring.activeKID == "" {
+		ring.activeKID = lastKID
+	}
+	signer, ok := signers[ring.activeKID]
+	if !ok {
+		return nil, fmt.Errorf("no private key found for active kid %q in %s", ring.activeKID, dir)
+	}
+	ring.signer = signer
+	ring.method = ring.verifiers[ring.activeKID].method
+
+	return ring, nil
+}
+
+func (k *Keyring) sign(claims jwt.Claims, typ string) (string, error) {
+	if k.signer == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["typ"] = typ
+		return token.SignedString(k.hmacSecret)
+	}
+
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["typ"] = typ
+	token.Header["kid"] = k.activeKID
+	return token.SignedString(k.signer)
+}
+
+func (k *Keyring) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if kid == "" {
+		// Token tanpa kid hanya sah di mode HS256 atau selama masa transisi
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok && k.hmacSecret != nil && (k.signer == nil || k.acceptLegacy) {
+			return k.hmacSecret, nil
+		}
+		return nil, errors.New("unexpected signing method")
+	}
+
+	vk, ok := k.verifiers[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if token.Method.Alg() != vk.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return vk.key, nil
+}
+
+// JWKS mengembalikan public key yang aktif untuk verifikasi dalam format JWK Set.
+func JWKS() ([]JWK, error) {
+	ring, err := getKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	kids := make([]string, 0, len(ring.verifiers))
+	for kid := range ring.verifiers {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := make([]JWK, 0, len(kids))
+	for _, kid := range kids {
+		vk := ring.verifiers[kid]
+		jwk := JWK{Kid: kid, Use: "sig", Alg: vk.method.Alg()}
+		switch pub := vk.key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+	return keys, nil
+}
+
+func methodForKey(pub crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, errors.New("unsupported key type, use RSA or Ed25519")
+	}
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key")
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported private key format")
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported public key format")
+}
@@ -0,0 +1,339 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writePrivateKey(t *testing.T, dir, kid string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid+".pem", "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir, kid string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid+".pub.pem", "PUBLIC KEY", der)
+}
+
+// loadTestKeyring memuat keyring dari env test dan memasangnya sebagai keyring
+// global selama test berjalan.
+func loadTestKeyring(t *testing.T, env map[string]string) *Keyring {
+	t.Helper()
+	for _, key := range []string{"JWT_SECRET", "JWT_KEYS_DIR", "JWT_ACTIVE_KID", "JWT_ACCEPT_LEGACY_HS256"} {
+		t.Setenv(key, env[key])
+	}
+	ring, err := loadKeyring()
+	if err != nil {
+		t.Fatal(err)
+	}
+	useKeyring(t, ring)
+	return ring
+}
+
+func useKeyring(t *testing.T, ring *Keyring) {
+	t.Helper()
+	keyringOnce.Do(func() {})
+	previous, previousErr := keyring, keyringErr
+	keyring, keyringErr = ring, nil
+	t.Cleanup(func() { keyring, keyringErr = previous, previousErr })
+}
+
+func tokenHeader(t *testing.T, token string) map[string]interface{} {
+	t.Helper()
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, &JWTClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Header
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := newRSAKey(t)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "2024-01", rsaKey)
+	writePrivateKey(t, dir, "2025-01", edKey)
+	writePublicKey(t, dir, "2023-01", &newRSAKey(t).PublicKey)
+
+	tests := []struct {
+		name       string
+		activeKID  string
+		wantKID    string
+		wantMethod string
+	}{
+		{"default is last private kid", "", "2025-01", "EdDSA"},
+		{"explicit active kid", "2024-01", "2024-01", "RS256"},
+	}
+	for _, tt := range tests {
+		ring := loadTestKeyring(t, map[string]string{"JWT_KEYS_DIR": dir, "JWT_ACTIVE_KID": tt.activeKID})
+		if ring.activeKID != tt.wantKID || ring.method.Alg() != tt.wantMethod {
+			t.Errorf("%s: active = %s/%s, want %s/%s", tt.name, ring.activeKID, ring.method.Alg(), tt.wantKID, tt.wantMethod)
+		}
+		if len(ring.verifiers) != 3 {
+			t.Errorf("%s: %d verifiers, want 3", tt.name, len(ring.verifiers))
+		}
+
+		token, err := GenerateToken("user-1", "buyer", "session-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kid := tokenHeader(t, token)["kid"]; kid != tt.wantKID {
+			t.Errorf("%s: token kid = %v, want %s", tt.name, kid, tt.wantKID)
+		}
+		if _, err := ValidateToken(token); err != nil {
+			t.Errorf("%s: ValidateToken: %v", tt.name, err)
+		}
+	}
+}
+
+func TestLoadKeyringErrors(t *testing.T) {
+	dir := t.TempDir()
+	writePublicKey(t, dir, "old", &newRSAKey(t).PublicKey)
+	badDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(badDir, "broken.pem"), []byte("not pem"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"no secret and no keys dir", map[string]string{}},
+		{"only public keys", map[string]string{"JWT_KEYS_DIR": dir}},
+		{"unknown active kid", map[string]string{"JWT_KEYS_DIR": dir, "JWT_ACTIVE_KID": "missing"}},
+		{"invalid pem", map[string]string{"JWT_KEYS_DIR": badDir}},
+	}
+	for _, tt := range tests {
+		for _, key := range []string{"JWT_SECRET", "JWT_KEYS_DIR", "JWT_ACTIVE_KID"} {
+			t.Setenv(key, tt.env[key])
+		}
+		if _, err := loadKeyring(); err == nil {
+			t.Errorf("%s: loadKeyring succeeded, want error", tt.name)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, newKey := newRSAKey(t), newRSAKey(t)
+
+	oldDir := t.TempDir()
+	writePrivateKey(t, oldDir, "2024-01", oldKey)
+	loadTestKeyring(t, map[string]string{"JWT_KEYS_DIR": oldDir})
+	oldToken, err := GenerateToken("user-1", "buyer", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Key baru aktif, key lama hanya disimpan sebagai public key
+	rotatedDir := t.TempDir()
+	writePrivateKey(t, rotatedDir, "2025-01", newKey)
+	writePublicKey(t, rotatedDir, "2024-01", &oldKey.PublicKey)
+	loadTestKeyring(t, map[string]string{"JWT_KEYS_DIR": rotatedDir})
+
+	if _, err := ValidateToken(oldToken); err != nil {
+		t.Errorf("token signed with rotated-out key: %v", err)
+	}
+	newToken, err := GenerateToken("user-1", "buyer", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenHeader(t, newToken)["kid"]; kid != "2025-01" {
+		t.Errorf("new token kid = %v, want 2025-01", kid)
+	}
+
+	// Setelah key lama dihapus, token lama tidak berlaku lagi
+	finalDir := t.TempDir()
+	writePrivateKey(t, finalDir, "2025-01", newKey)
+	loadTestKeyring(t, map[string]string{"JWT_KEYS_DIR": finalDir})
+	if _, err := ValidateToken(oldToken); err == nil {
+		t.Error("token with removed kid was accepted")
+	}
+	if _, err := ValidateToken(newToken); err != nil {
+		t.Errorf("token with active kid: %v", err)
+	}
+}
+
+// forgeToken menandatangani claims access token yang valid dengan method dan key
+// sembarang, untuk menguji bahwa keyring menolaknya.
+func forgeToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+	t.Helper()
+	claims := JWTClaims{
+		UserID: "user-1",
+		Role:   "admin",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    JWTIssuer(),
+			Audience:  jwt.ClaimStrings{JWTAudience()},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["typ"] = accessTokenKind.typ
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestKeyringRejectsForgedTokens(t *testing.T) {
+	dir := t.TempDir()
+	key := newRSAKey(t)
+	writePrivateKey(t, dir, "main", key)
+	keyPEM, err := os.ReadFile(filepath.Join(dir, "main.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePublicKey(t, dir, "pub", &key.PublicKey)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadTestKeyring(t, map[string]string{"JWT_KEYS_DIR": dir, "JWT_ACTIVE_KID": "main", "JWT_SECRET": "legacy-secret"})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"unknown kid", forgeToken(t, jwt.SigningMethodRS256, "other", newRSAKey(t))},
+		{"right kid, wrong key", forgeToken(t, jwt.SigningMethodRS256, "main", newRSAKey(t))},
+		// Serangan klasik: public key RSA dipakai sebagai secret HMAC
+		{"HS256 with public key DER as secret", forgeToken(t, jwt.SigningMethodHS256, "pub", publicDER)},
+		{"HS256 with key file as secret", forgeToken(t, jwt.SigningMethodHS256, "main", keyPEM)},
+		{"alg none", forgeToken(t, jwt.SigningMethodNone, "main", jwt.UnsafeAllowNoneSignatureType)},
+		{"alg none without kid", forgeToken(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType)},
+		{"legacy HS256 without kid when legacy is off", forgeToken(t, jwt.SigningMethodHS256, "", []byte("legacy-secret"))},
+	}
+	for _, tt := range tests {
+		if _, err := ValidateToken(tt.token); err == nil {
+			t.Errorf("%s: token was accepted", tt.name)
+		}
+	}
+
+	if _, err := ValidateToken(forgeToken(t, jwt.SigningMethodRS256, "main", key)); err != nil {
+		t.Errorf("control token signed with the real key was rejected: %v", err)
+	}
+}
+
+func TestKeyringLegacyHS256(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "main", newRSAKey(t))
+	loadTestKeyring(t, map[string]string{"JWT_KEYS_DIR": dir, "JWT_SECRET": "legacy-secret", "JWT_ACCEPT_LEGACY_HS256": "true"})
+
+	if _, err := ValidateToken(forgeToken(t, jwt.SigningMethodHS256, "", []byte("legacy-secret"))); err != nil {
+		t.Errorf("legacy token during transition: %v", err)
+	}
+	if _, err := ValidateToken(forgeToken(t, jwt.SigningMethodHS256, "", []byte("other-secret"))); err == nil {
+		t.Error("legacy token with wrong secret was accepted")
+	}
+	// HS256 dengan kid tetap ditolak walaupun mode transisi aktif
+	if _, err := ValidateToken(forgeToken(t, jwt.SigningMethodHS256, "main", []byte("legacy-secret"))); err == nil {
+		t.Error("HS256 token with RSA kid was accepted")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := newRSAKey(t)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "b-rsa", rsaKey)
+	writePublicKey(t, dir, "a-ed", edPub)
+	writePrivateKey(t, dir, "c-ed", edKey)
+	loadTestKeyring(t, map[string]string{"JWT_KEYS_DIR": dir, "JWT_ACTIVE_KID": "b-rsa"})
+
+	keys, err := JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("JWKS has %d keys, want 3", len(keys))
+	}
+	var kids []string
+	for _, k := range keys {
+		kids = append(kids, k.Kid)
+		if k.Use != "sig" {
+			t.Errorf("%s: use = %q, want sig", k.Kid, k.Use)
+		}
+	}
+	if strings.Join(kids, ",") != "a-ed,b-rsa,c-ed" {
+		t.Errorf("kids = %v, want sorted a-ed,b-rsa,c-ed", kids)
+	}
+
+	rsaJWK := keys[1]
+	n, _ := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	e, _ := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	if rsaJWK.Kty != "RSA" || rsaJWK.Alg != "RS256" ||
+		new(big.Int).SetBytes(n).Cmp(rsaKey.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(rsaKey.E) {
+		t.Errorf("RSA JWK does not match key: %+v", rsaJWK)
+	}
+	for _, k := range []JWK{keys[0], keys[2]} {
+		x, _ := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Kty != "OKP" || k.Crv != "Ed25519" || k.Alg != "EdDSA" || len(x) != ed25519.PublicKeySize {
+			t.Errorf("Ed25519 JWK is malformed: %+v", k)
+		}
+	}
+	if x, _ := base64.RawURLEncoding.DecodeString(keys[0].X); string(x) != string(edPub) {
+		t.Error("public-only Ed25519 key does not match")
+	}
+	for _, k := range keys {
+		if k.N == "" && k.X == "" {
+			t.Errorf("%s: JWK has no key material", k.Kid)
+		}
+	}
+}
+
+func TestJWKSWithHMACOnly(t *testing.T) {
+	loadTestKeyring(t, map[string]string{"JWT_SECRET": "secret"})
+	keys, err := JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("JWKS in HS256 mode = %v, want empty", keys)
+	}
+}
@@ -0,0 +1,25 @@
+package utils
+
+import "golang.org/x/crypto/bcrypt"
+
+// BcryptCost dibaca dari BCRYPT_COST agar cost bisa dinaikkan seiring waktu
+// tanpa perubahan kode. Nilai di luar rentang bcrypt memakai DefaultCost.
+func BcryptCost() int {
+	cost := GetEnvInt("BCRYPT_COST", bcrypt.DefaultCost)
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return bcrypt.DefaultCost
+	}
+	return cost
+}
+
+func HashPassword(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), BcryptCost())
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func CheckPassword(hashed, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password)) == nil
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret ASCII "12345678901234567890" dari RFC 6238 Appendix B (mode SHA1).
+const rfc6238Secret = "12345678901234567890"
+
+func TestHOTPRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		if got := hotp([]byte(rfc6238Secret), tt.unix/totpPeriod, 8); got != tt.want {
+			t.Errorf("T=%d: hotp = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret := base32NoPadding.EncodeToString([]byte(rfc6238Secret))
+	at := time.Unix(1111111109, 0)
+	step := at.Unix() / totpPeriod
+	// 6 digit terakhir dari vektor 8 digit RFC
+	code := "081804"
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		at       time.Time
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", secret, code, at, step, true},
+		{"lowercase secret", strings.ToLower(secret), code, at, step, true},
+		{"surrounding whitespace", secret, " " + code + "\n", at, step, true},
+		{"one step late", secret, code, at.Add(totpPeriod * time.Second), step, true},
+		{"one step early", secret, code, at.Add(-totpPeriod * time.Second), step, true},
+		{"outside skew", secret, code, at.Add(2 * totpPeriod * time.Second), 0, false},
+		{"wrong code", secret, "000000", at, 0, false},
+		{"8 digit code", secret, "07081804", at, 0, false},
+		{"invalid secret", "not base32!", code, at, 0, false},
+	}
+	for _, tt := range tests {
+		gotStep, ok := ValidateTOTP(tt.secret, tt.code, tt.at)
+		if ok != tt.wantOK || gotStep != tt.wantStep {
+			t.Errorf("%s: ValidateTOTP = %d, %v; want %d, %v", tt.name, gotStep, ok, tt.wantStep, tt.wantOK)
+		}
+	}
+}
+
+func TestGenerateTOTPSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := base32NoPadding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes, err %v", secret, len(key), err)
+	}
+	now := time.Now()
+	if _, ok := ValidateTOTP(secret, hotp(key, now.Unix()/totpPeriod, totpDigits), now); !ok {
+		t.Error("code generated for the current step was rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	u, err := url.Parse(TOTPProvisioningURI("Toko", "a@b.c", "SECRET"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Toko:a@b.c" {
+		t.Errorf("unexpected URI %s", u)
+	}
+	q := u.Query()
+	for key, want := range map[string]string{"secret": "SECRET", "issuer": "Toko", "algorithm": "SHA1", "digits": "6", "period": "30"} {
+		if q.Get(key) != want {
+			t.Errorf("%s = %q, want %q", key, q.Get(key), want)
+		}
+	}
+}
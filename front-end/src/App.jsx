@@ -6,6 +6,8 @@ import Products from "./pages/public/Products"; // Add this import
 import Login from "./pages/auth/Login"; // Add this import
 import Register from "./pages/auth/Register"; // Add this import
 import ForgotPassword from "./pages/auth/ForgotPassword"; // Add this import
+import VerifyEmail from "./pages/auth/VerifyEmail";
+import OAuthCallback from "./pages/auth/OAuthCallback";
 import SearchResults from "./pages/public/Search"; // Add this import
 import OrderHistory from "./pages/public/OrderHistory";
 import OrderDetail from "./pages/public/OrderDetail";
@@ -114,6 +116,8 @@ const App = () => {
         <Route path="/register" element={<Register />} /> {/* Add this route */}
         <Route path="/forgot-password" element={<ForgotPassword />} />{" "}
         {/* Add this route */}
+        <Route path="/verify-email" element={<VerifyEmail />} />
+        <Route path="/oauth/callback" element={<OAuthCallback />} />
         <Route path="/search" element={<SearchResults />} />{" "}
         {/* Add this route */}
         <Route path="/orders" element={<OrderHistory />} />
@@ -89,7 +89,15 @@ const ProductCard = ({ product }) => {
         ) : (
           <CardMedia
             component="img"
-            image={product.image_url}
+            image={product.image_renditions?.medium?.url || product.image_url}
+            srcSet={
+              product.image_renditions
+                ? Object.values(product.image_renditions)
+                    .map((rendition) => `${rendition.url} ${rendition.width}w`)
+                    .join(", ")
+                : undefined
+            }
+            sizes="(max-width: 600px) 50vw, 300px"
             alt={name}
             // onLoad={handleImageLoad}
             // onError={handleImageError}
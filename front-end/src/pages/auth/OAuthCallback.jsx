@@ -0,0 +1,94 @@
+import React, { useEffect, useRef, useState } from 'react';
+import {
+  Box,
+  Container,
+  Typography,
+  Link,
+} from '@mui/material';
+import { Link as RouterLink, useNavigate, useSearchParams } from 'react-router-dom';
+import Card from '../../components/common/Card';
+import Loading from '../../components/common/Loading';
+import PublicLayout from '../../layouts/PublicLayout';
+import api from '../../services/api';
+
+// Backend me-redirect ke sini dengan ?code= sekali pakai (atau ?error=);
+// code ditukar dengan sesi lewat POST supaya token tidak pernah ada di URL.
+const OAuthCallback = () => {
+  const [searchParams] = useSearchParams();
+  const navigate = useNavigate();
+  const code = searchParams.get('code');
+  const [error, setError] = useState(searchParams.get('error') || (code ? '' : 'Login link is invalid.'));
+  const [message, setMessage] = useState('');
+  const exchanged = useRef(false);
+
+  useEffect(() => {
+    if (!code || exchanged.current) return;
+    exchanged.current = true;
+
+    const exchange = async () => {
+      try {
+        const response = await api.post('/auth/oauth/exchange', { code });
+
+        if (response.data?.mfa_required) {
+          setMessage('Two-factor authentication is required for this account.');
+          return;
+        }
+
+        const { user, token } = response.data;
+        localStorage.setItem('user', JSON.stringify(user));
+        if (token) {
+          localStorage.setItem('token', token);
+        }
+
+        if (user.role === 'admin') {
+          navigate('/admin/dashboard');
+        } else if (user.role === 'seller') {
+          navigate('/dashboard/seller');
+        } else {
+          navigate('/');
+        }
+      } catch (err) {
+        setError(err.response?.data?.error || 'Failed to sign in. Please try again.');
+      }
+    };
+
+    exchange();
+  }, [code, navigate]);
+
+  const pending = !error && !message;
+
+  return (
+    <PublicLayout>
+      <Container maxWidth="sm" sx={{
+        py: 8,
+        minHeight: 'calc(100vh - 128px)',
+        display: 'flex',
+        alignItems: 'center',
+        position: 'relative'
+      }}>
+        {pending && <Loading overlay text="Signing in..." />}
+        <Card variant="elevated" sx={{ width: '100%' }}>
+          <Card.Header>
+            <Card.Title>Sign In</Card.Title>
+          </Card.Header>
+          <Card.Content>
+            <Box sx={{ textAlign: 'center', py: 2 }}>
+              <Typography variant="body2" color={error ? 'error' : 'text.secondary'}>
+                {error || message || 'Completing sign in...'}
+              </Typography>
+            </Box>
+          </Card.Content>
+          <Card.Footer>
+            <Box sx={{ textAlign: 'center' }}>
+              <Link component={RouterLink} to="/login" variant="body2">
+                Back to Login
+              </Link>
+            </Box>
+          </Card.Footer>
+        </Card>
+      </Container>
+    </PublicLayout>
+  );
+};
+
+export default OAuthCallback;
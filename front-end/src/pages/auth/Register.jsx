@@ -10,10 +10,6 @@ import {
   Link,
   Divider,
   Alert,
-  FormControl,
-  InputLabel,
-  Select,
-  MenuItem,
 } from "@mui/material";
 import { Visibility, VisibilityOff } from "@mui/icons-material";
 import { Link as RouterLink, useNavigate } from "react-router-dom";
@@ -30,7 +26,6 @@ const Register = () => {
     email: "",
     password: "",
     confirmPassword: "",
-    role: "buyer", // Add this
     showPassword: false,
     showConfirmPassword: false,
   });
@@ -77,7 +72,6 @@ const Register = () => {
           name: values.fullName, // Ubah format nama field
           email: values.email,
           password: values.password,
-          role: values.role, // Add this
         };
 
         console.log("Sending registration data:", userData); // Debug log
@@ -124,11 +118,6 @@ const Register = () => {
     }
   };
 
-  const roles = [
-    { value: "buyer", label: "Buyer" },
-    { value: "seller", label: "Seller" },
-  ];
-
   return (
     <PublicLayout>
       <Container
@@ -173,20 +162,6 @@ const Register = () => {
                 error={Boolean(errors.email)}
                 helperText={errors.email}
               />
-              <FormControl fullWidth margin="normal">
-                <InputLabel>Role</InputLabel>
-                <Select
-                  value={values.role}
-                  onChange={handleChange("role")}
-                  label="Role"
-                >
-                  {roles.map((role) => (
-                    <MenuItem key={role.value} value={role.value}>
-                      {role.label}
-                    </MenuItem>
-                  ))}
-                </Select>
-              </FormControl>
               <TextField
                 fullWidth
                 label="Password"
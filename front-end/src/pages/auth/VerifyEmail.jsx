@@ -0,0 +1,88 @@
+import React, { useState } from 'react';
+import {
+  Box,
+  Container,
+  Button,
+  Typography,
+  Link,
+} from '@mui/material';
+import { Link as RouterLink, useSearchParams } from 'react-router-dom';
+import Card from '../../components/common/Card';
+import Loading from '../../components/common/Loading';
+import PublicLayout from '../../layouts/PublicLayout';
+import api from '../../services/api';
+
+// Token hanya dikirim saat tombol ditekan, supaya link preview atau scanner
+// email yang membuka link tidak ikut memverifikasi.
+const VerifyEmail = () => {
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get('token');
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState(token ? '' : 'Verification link is invalid.');
+  const [success, setSuccess] = useState(false);
+
+  const handleVerify = async () => {
+    setLoading(true);
+    setError('');
+    try {
+      await api.post('/auth/verify-email', { token });
+      setSuccess(true);
+    } catch (err) {
+      setError(err.response?.data?.error || 'Failed to verify email. Please try again.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <PublicLayout>
+      <Container maxWidth="sm" sx={{
+        py: 8,
+        minHeight: 'calc(100vh - 128px)',
+        display: 'flex',
+        alignItems: 'center',
+        position: 'relative'
+      }}>
+        {loading && <Loading overlay text="Verifying email..." />}
+        <Card variant="elevated" sx={{ width: '100%' }}>
+          <Card.Header>
+            <Card.Title>Verify Email</Card.Title>
+          </Card.Header>
+          <Card.Content>
+            <Box sx={{ textAlign: 'center', py: 2 }}>
+              {success ? (
+                <Typography variant="body1" color="success.main">
+                  Your email has been verified.
+                </Typography>
+              ) : (
+                <Typography variant="body2" color={error ? 'error' : 'text.secondary'}>
+                  {error || 'Click the button below to confirm your email address.'}
+                </Typography>
+              )}
+            </Box>
+          </Card.Content>
+          <Card.Footer>
+            {!success && token && (
+              <Button
+                fullWidth
+                variant="contained"
+                disabled={loading}
+                onClick={handleVerify}
+                sx={{ mb: 2 }}
+              >
+                Verify Email
+              </Button>
+            )}
+            <Box sx={{ textAlign: 'center' }}>
+              <Link component={RouterLink} to="/login" variant="body2">
+                Back to Login
+              </Link>
+            </Box>
+          </Card.Footer>
+        </Card>
+      </Container>
+    </PublicLayout>
+  );
+};
+
+export default VerifyEmail;
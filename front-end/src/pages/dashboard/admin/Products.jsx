@@ -80,8 +80,8 @@ const SellerProducts = () => {
     const user = getUserInfo();
 
     try {
-      const res = await api.get("/products");
-      setProducts(res.data);
+      const res = await api.get("/products", { params: { limit: 100 } });
+      setProducts(res.data.data);
     } catch (err) {
       setError("Failed to fetch products");
     } finally {
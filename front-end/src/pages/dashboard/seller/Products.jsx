@@ -35,7 +35,6 @@ import {
 import { getAllCategories } from "../../../services/categories";
 import { formatPrice } from "../../../utils/formatters";
 import api from "../../../services/api";
-import { getUserInfo } from "../../../utils/auth";
 import CloudUploadIcon from "@mui/icons-material/CloudUpload";
 import { styled } from "@mui/system";
 
@@ -75,11 +74,9 @@ const SellerProducts = () => {
   }, []);
 
   const fetchProducts = async () => {
-    const user = getUserInfo();
-
     try {
-      const res = await api.get("/products");
-      setProducts(res.data.filter((pr) => pr.seller_id === user.user_id));
+      const res = await api.get("/seller/products", { params: { limit: 100 } });
+      setProducts(res.data.data);
     } catch (err) {
       setError("Failed to fetch products");
     } finally {
@@ -126,12 +123,12 @@ const SellerProducts = () => {
   };
 
   const handleDelete = async (id) => {
-    if (window.confirm("Are you sure you want to delete this product?")) {
+    if (window.confirm("Are you sure you want to archive this product?")) {
       try {
         await deleteProduct(id);
         fetchProducts();
       } catch (err) {
-        setError("Failed to delete product");
+        setError("Failed to archive product");
       }
     }
   };
@@ -209,7 +206,7 @@ const SellerProducts = () => {
               <EditIcon />
             </IconButton>
           </Tooltip>
-          <Tooltip title="Delete">
+          <Tooltip title="Archive">
             <IconButton
               size="small"
               onClick={() => handleDelete(params.row.id)}
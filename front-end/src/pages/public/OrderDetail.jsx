@@ -219,11 +219,11 @@ const OrderDetail = () => {
                       overflow: 'hidden'
                     }}
                   >
-                    {item.product?.imageUrl ? (
+                    {item.image_url ? (
                       <Box 
                         component="img"
-                        src={item.product.imageUrl}
-                        alt={item.product.name}
+                        src={item.image_url}
+                        alt={item.product_name}
                         sx={{ width: '100%', height: '100%', objectFit: 'cover' }}
                       />
                     ) : (
@@ -254,14 +254,14 @@ const OrderDetail = () => {
                         fontSize: '1rem'
                       }}
                     >
-                      {item.product?.name || 'Product Unavailable'}
+                      {item.product_name || 'Product Unavailable'}
                     </Button>
                     <Typography variant="body2" color="textSecondary">Qty: {item.quantity}</Typography>
                     <Typography variant="body2" color="textSecondary">
-                      Price: Rp {item.price.toLocaleString('id-ID')}
+                      Price: Rp {item.unit_price.toLocaleString('id-ID')}
                     </Typography>
                     <Typography variant="subtitle2" sx={{ mt: 1 }}>
-                      Subtotal: Rp {(item.quantity * item.price).toLocaleString('id-ID')}
+                      Subtotal: Rp {item.price.toLocaleString('id-ID')}
                     </Typography>
                   </Box>
                 </Box>
@@ -284,11 +284,11 @@ const OrderHistory = () => {
                           overflow: "hidden",
                         }}
                       >
-                        {item.product?.image_url ? (
+                        {item.image_url ? (
                           <Box
                             component="img"
-                            src={item.product.image_url}
-                            alt={item.product.name}
+                            src={item.image_url}
+                            alt={item.product_name}
                             sx={{
                               width: "100%",
                               height: "100%",
@@ -312,11 +312,11 @@ const OrderHistory = () => {
                       </Box>
                       <Box sx={{ flexGrow: 1 }}>
                         <Typography variant="subtitle2">
-                          {item.product?.name || "Product Unavailable"}
+                          {item.product_name || "Product Unavailable"}
                         </Typography>
                         <Typography variant="body2" color="textSecondary">
                           Qty: {item.quantity} ×{" "}
-                          {formatPrice(item.product.price)}
+                          {formatPrice(item.unit_price)}
                         </Typography>
                       </Box>
                       <Box
@@ -12,6 +12,7 @@ import {
   TextField,
   Divider,
   IconButton,
+  Chip,
 } from "@mui/material";
 import {
   Add as AddIcon,
@@ -53,6 +54,7 @@ const ProductDetail = () => {
   const [thumbsSwiper, setThumbsSwiper] = useState(null);
   const [tabValue, setTabValue] = useState(0);
   const [quantity, setQuantity] = useState(1);
+  const [selectedOptions, setSelectedOptions] = useState({});
   const [isWishlisted, setIsWishlisted] = useState(false);
   const [product, setProduct] = useState({
     name: "Loading",
@@ -65,14 +67,29 @@ const ProductDetail = () => {
   // const [reviewsLoading, setReviewsLoading] = useState(true);
   // const [reviewsError, setReviewsError] = useState(null);
 
+  const variants = product.variants || [];
+  const options = product.options || [];
+  // Varian terpilih jika semua opsi (ukuran, warna, ...) sudah dipilih
+  const selectedVariant = variants.find((variant) =>
+    options.every(
+      (option) => variant.options?.[option.name] === selectedOptions[option.name]
+    )
+  );
+
   const handleAddToCart = async (productId) => {
-    // Add product to cart logic here
+    if (variants.length > 0 && !selectedVariant) {
+      alert("Please choose all product options first.");
+      return;
+    }
     const info = getUserInfo();
     const data = {
       product_id: productId,
       user_id: info.user_id,
       quantity,
     };
+    if (selectedVariant) {
+      data.variant_id = selectedVariant.id;
+    }
 
     await addItemToCart(data);
     alert("Product added to cart!");
@@ -85,8 +102,10 @@ const ProductDetail = () => {
       const productData = await getProductById(id);
       setProduct(productData);
 
-      const mockGallery = [productData.image_url];
-      setProductImages(mockGallery);
+      const gallery = productData.images?.length
+        ? productData.images.map((image) => image.url)
+        : [productData.image_url];
+      setProductImages(gallery);
       setLoading(false);
     } catch (err) {
       console.error("Error fetching product:", err);
@@ -189,9 +208,34 @@ const ProductDetail = () => {
               </Box>
 
               <Typography variant="h5" color="primary" sx={{ mb: 3 }}>
-                {formatPrice(product.price)}
+                {formatPrice(selectedVariant ? selectedVariant.price : product.price)}
               </Typography>
 
+              {options.map((option) => (
+                <Box key={option.id} sx={{ mb: 2 }}>
+                  <Typography variant="subtitle2" sx={{ mb: 1 }}>
+                    {option.name}
+                  </Typography>
+                  <Box sx={{ display: "flex", flexWrap: "wrap", gap: 1 }}>
+                    {option.values.map((value) => (
+                      <Chip
+                        key={value}
+                        label={value}
+                        color={selectedOptions[option.name] === value ? "primary" : "default"}
+                        onClick={() =>
+                          setSelectedOptions({ ...selectedOptions, [option.name]: value })
+                        }
+                      />
+                    ))}
+                  </Box>
+                </Box>
+              ))}
+              {selectedVariant && (
+                <Typography variant="body2" color="text.secondary" sx={{ mb: 2 }}>
+                  SKU {selectedVariant.sku} · Stock {selectedVariant.stock}
+                </Typography>
+              )}
+
               <Box sx={{ display: "flex", alignItems: "center", mb: 3 }}>
                 <Box sx={{ display: "flex", alignItems: "center", mr: 2 }}>
                   <IconButton
@@ -129,7 +129,7 @@ const Products = () => {
         `${import.meta.env.VITE_API_URL}/products/search?q=${query}`
       );
 
-      setProducts(res.data);
+      setProducts(res.data.data);
     } catch (err) {
       setError("Failed to search products");
     }
@@ -13,8 +13,13 @@ const api = axios.create({
   withCredentials: true
 });
 
+const readCookie = (name) => {
+  const match = document.cookie.match(new RegExp(`(?:^|; )${name}=([^;]*)`));
+  return match ? decodeURIComponent(match[1]) : null;
+};
+
 /**
- * Add auth token to requests
+ * Add auth token and CSRF token to requests
  */
 api.interceptors.request.use(
   (config) => {
@@ -22,6 +27,12 @@ api.interceptors.request.use(
     if (token) {
       config.headers.Authorization = `Bearer ${token}`;
     }
+    // Backend mewajibkan X-CSRF-Token untuk request yang mengubah data dengan auth dari cookie
+    const method = (config.method || 'get').toLowerCase();
+    const csrfToken = readCookie('csrf_token');
+    if (csrfToken && !['get', 'head', 'options'].includes(method)) {
+      config.headers['X-CSRF-Token'] = csrfToken;
+    }
     return config;
   },
   (error) => {
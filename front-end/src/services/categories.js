@@ -30,3 +30,18 @@ export const getCategoryById = async (id) => {
     throw error;
   }
 };
+
+/**
+ * Mengambil skema atribut kategori (brand, bahan, ukuran, ...)
+ * @param {string} id - ID kategori
+ * @returns {Promise<Array>} - Array atribut { key, name, type, options, unit, required }
+ */
+export const getCategoryAttributes = async (id) => {
+  try {
+    const response = await axios.get(`${API_URL}/categories/${id}/attributes`);
+    return response.data;
+  } catch (error) {
+    console.error(`Error mengambil atribut kategori ${id}:`, error);
+    throw error;
+  }
+};
@@ -8,8 +8,11 @@ export const API_URL = import.meta.env.VITE_API_URL || "http://localhost:8080";
  */
 export const getAllProducts = async () => {
   try {
-    const response = await axios.get(`${API_URL}/products`);
-    return response.data;
+    // Endpoint sekarang terpaginasi; ambil halaman terbesar untuk halaman yang masih memfilter di client
+    const response = await axios.get(`${API_URL}/products`, {
+      params: { limit: 100 },
+    });
+    return response.data.data;
   } catch (error) {
     console.error("Error mengambil produk:", error);
     throw error;
@@ -23,6 +26,7 @@ export const getAllProducts = async () => {
  * @param {string} params.category - Kategori produk
  * @param {number} params.minPrice - Harga minimum
  * @param {number} params.maxPrice - Harga maksimum
+ * @param {Object} params.attributes - Filter atribut, mis. { brand: ["Nike"], weight: { min: 1, max: 5 } }
  * @returns {Promise<Array>} - Array produk hasil pencarian
  */
 export const searchProducts = async ({
@@ -30,6 +34,7 @@ export const searchProducts = async ({
   category,
   minPrice,
   maxPrice,
+  attributes = {},
 }) => {
   try {
     // Membangun parameter query
@@ -38,17 +43,45 @@ export const searchProducts = async ({
     if (category) params.append("category", category);
     if (minPrice) params.append("min_price", minPrice);
     if (maxPrice) params.append("max_price", maxPrice);
+    Object.entries(attributes).forEach(([key, value]) => {
+      if (Array.isArray(value)) {
+        if (value.length) params.append(`attr.${key}`, value.join(","));
+      } else if (value && typeof value === "object") {
+        if (value.min != null) params.append(`attr.${key}.min`, value.min);
+        if (value.max != null) params.append(`attr.${key}.max`, value.max);
+      } else if (value) {
+        params.append(`attr.${key}`, value);
+      }
+    });
 
     const response = await axios.get(
       `${API_URL}/products/search?${params.toString()}`
     );
-    return response.data;
+    // Hasil terurut berdasarkan relevansi dan terpaginasi: { data, pagination, facets }
+    return response.data.data;
   } catch (error) {
     console.error("Error mencari produk:", error);
     throw error;
   }
 };
 
+/**
+ * Autocomplete untuk search bar
+ * @param {string} query - Teks yang sedang diketik
+ * @returns {Promise<Object>} - { products, categories, queries, corrected? }
+ */
+export const suggestProducts = async (query, limit = 5) => {
+  try {
+    const response = await axios.get(`${API_URL}/products/suggest`, {
+      params: { q: query, limit },
+    });
+    return response.data;
+  } catch (error) {
+    console.error("Error mengambil saran pencarian:", error);
+    throw error;
+  }
+};
+
 /**
  * Mendapatkan detail produk berdasarkan ID
  * @param {string|number} id - ID produk
@@ -71,10 +104,10 @@ export const getProductById = async (id) => {
  */
 export const getProductsByCategory = async (category) => {
   try {
-    const response = await axios.get(
-      `${API_URL}/products?category=${category}`
-    );
-    return response.data;
+    const response = await axios.get(`${API_URL}/products`, {
+      params: { category, limit: 100 },
+    });
+    return response.data.data;
   } catch (error) {
     console.error(`Error mengambil produk untuk kategori ${category}:`, error);
     throw error;
@@ -87,8 +120,13 @@ export const getProductsByCategory = async (category) => {
  */
 export const getSellerProducts = async () => {
   try {
-    const response = await axios.get(`/products/seller`);
-    return response.data;
+    const token = localStorage.getItem("token");
+    const response = await axios.get(`${API_URL}/seller/products`, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data.data;
   } catch (error) {
     console.error("Error mengambil produk seller:", error);
     throw error;
@@ -154,3 +192,69 @@ export const deleteProduct = async (id) => {
     throw error;
   }
 };
+
+/**
+ * Memulai import katalog dari file CSV/XLSX (berjalan di background)
+ * @param {File} file - File .csv atau .xlsx
+ * @param {boolean} dryRun - Hanya validasi tanpa menyimpan
+ * @returns {Promise<Object>} - Job import
+ */
+export const importProducts = async (file, dryRun = false) => {
+  try {
+    const token = localStorage.getItem("token");
+    const formData = new FormData();
+    formData.append("file", file);
+    formData.append("dry_run", dryRun ? "true" : "false");
+    const response = await axios.post(`${API_URL}/seller/products/import`, formData, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data.job;
+  } catch (error) {
+    console.error("Error memulai import produk:", error);
+    throw error;
+  }
+};
+
+/**
+ * Mengambil status dan error per baris dari job import
+ * @param {string} id - ID job import
+ * @returns {Promise<Object>} - Job import
+ */
+export const getProductImportJob = async (id) => {
+  try {
+    const token = localStorage.getItem("token");
+    const response = await axios.get(`${API_URL}/seller/products/imports/${id}`, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data.data;
+  } catch (error) {
+    console.error(`Error mengambil job import ${id}:`, error);
+    throw error;
+  }
+};
+
+/**
+ * Mengunduh katalog seller
+ * @param {"csv"|"xlsx"} format - Format file
+ * @returns {Promise<Blob>} - Isi file
+ */
+export const exportProducts = async (format = "csv") => {
+  try {
+    const token = localStorage.getItem("token");
+    const response = await axios.get(`${API_URL}/seller/products/export`, {
+      params: { format },
+      responseType: "blob",
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  } catch (error) {
+    console.error("Error mengekspor produk:", error);
+    throw error;
+  }
+};